@@ -0,0 +1,37 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+// Condition types reported in the status of ListSources, ListJobs and
+// ListCronJobs.
+const (
+	// ConditionReady is true when a ListSource holds a list, the Jobs of a
+	// ListJob were created, or a ListCronJob is scheduled.
+	ConditionReady = "Ready"
+	// ConditionFetching is true while a ListSource fetches its items.
+	ConditionFetching = "Fetching"
+	// ConditionDegraded is true when a ListSource serves its previous list
+	// after a failed fetch, a ListJob has failed items, or the last run of a
+	// ListCronJob failed.
+	ConditionDegraded = "Degraded"
+	// ConditionComplete is true when all items of a ListJob succeeded.
+	ConditionComplete = "Complete"
+	// ConditionFailed is true when a ListJob finished with failures.
+	ConditionFailed = "Failed"
+	// ConditionSuspended is true when a ListCronJob is suspended.
+	ConditionSuspended = "Suspended"
+)
@@ -26,10 +26,16 @@ import (
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
 // ListCronJobSpec defines the desired state of ListCronJob.
+// +kubebuilder:validation:XValidation:rule="!has(self.maxFailedIndexes) || has(self.backoffLimitPerIndex)",message="maxFailedIndexes requires backoffLimitPerIndex"
+// +kubebuilder:validation:XValidation:rule="!has(self.podFailurePolicy) || has(self.backoffLimitPerIndex) || self.podFailurePolicy.rules.all(r, r.action != 'FailIndex')",message="podFailurePolicy action FailIndex requires backoffLimitPerIndex"
 type ListCronJobSpec struct {
-	ListSourceRef              string                    `json:"listSourceRef,omitempty"`
-	StaticList                 []string                  `json:"staticList,omitempty"`
-	Parallelism                int32                     `json:"parallelism"`
+	ListSourceRef string   `json:"listSourceRef,omitempty"`
+	StaticList    []string `json:"staticList,omitempty"`
+	// Parallelism is the number of pods running at the same time.
+	// Defaults to 1.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=1
+	Parallelism                int32                     `json:"parallelism,omitempty"`
 	Template                   JobTemplateSpec           `json:"template"`
 	TTLSecondsAfterFinished    *int32                    `json:"ttlSecondsAfterFinished,omitempty"`
 	Schedule                   string                    `json:"schedule"`
@@ -38,16 +44,55 @@ type ListCronJobSpec struct {
 	SuccessfulJobsHistoryLimit *int32                    `json:"successfulJobsHistoryLimit,omitempty"`
 	FailedJobsHistoryLimit     *int32                    `json:"failedJobsHistoryLimit,omitempty"`
 	Suspend                    *bool                     `json:"suspend,omitempty"`
+	// BatchSize is the number of contiguous items handed to every pod.
+	// Defaults to 1.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	BatchSize        int32 `json:"batchSize,omitempty"`
+	JobFailurePolicy `json:",inline"`
+	// RefreshListSource makes every run wait for the referenced ListSource to
+	// fetch its items anew, instead of running with the list of its last
+	// interval.
+	// +kubebuilder:validation:Optional
+	RefreshListSource bool `json:"refreshListSource,omitempty"`
 }
 
+// MaxListCronJobNameLength leaves room in the 63 characters of a Job name for
+// the "-<minutes since epoch>" suffix of the ListJobs a ListCronJob schedules,
+// as CronJobs do.
+const MaxListCronJobNameLength = 52
+
+// History limits used when a ListCronJob does not set them.
+const (
+	DefaultSuccessfulJobsHistoryLimit = 3
+	DefaultFailedJobsHistoryLimit     = 1
+)
+
 // ListCronJobStatus defines the observed state of ListCronJob.
 type ListCronJobStatus struct {
 	Active           []corev1.ObjectReference `json:"active,omitempty"`
 	LastScheduleTime *metav1.Time             `json:"lastScheduleTime,omitempty"`
+	// LastSuccessfulTime is when the last successful ListJob completed.
+	LastSuccessfulTime *metav1.Time `json:"lastSuccessfulTime,omitempty"`
+	// NextScheduleTime is the next time a ListJob is due.
+	NextScheduleTime *metav1.Time `json:"nextScheduleTime,omitempty"`
+	// ObservedGeneration is the generation the status was computed for.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions describe the state of the ListCronJob, see the Condition
+	// constants for their types.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Schedule",type="string",JSONPath=".spec.schedule"
+// +kubebuilder:printcolumn:name="Suspend",type="boolean",JSONPath=".spec.suspend"
+// +kubebuilder:printcolumn:name="Last Schedule",type="date",JSONPath=".status.lastScheduleTime"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:validation:XValidation:rule="size(self.metadata.name) <= 52",message="name must be no more than 52 characters"
 
 // ListCronJob is the Schema for the listcronjobs API.
 type ListCronJob struct {
@@ -1,32 +1,216 @@
 package v1alpha1
 
 import (
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// DefaultEnvName is the variable holding the item when EnvName is not set.
+const DefaultEnvName = "ITEM"
+
+// +kubebuilder:validation:XValidation:rule="has(self.image) || has(self.podTemplate)",message="either image or podTemplate must be set"
 type JobTemplateSpec struct {
-	Image     string                      `json:"image"`
-	Command   []string                    `json:"command"`
-	EnvName   string                      `json:"envName"`
+	// Image overrides the image of the item container.
+	// +kubebuilder:validation:Optional
+	Image string `json:"image,omitempty"`
+	// Command overrides the command of the item container.
+	// +kubebuilder:validation:Optional
+	Command []string `json:"command,omitempty"`
+	// EnvName is the variable holding the item. Defaults to ITEM.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^[A-Za-z_][A-Za-z0-9_]*$`
+	EnvName   string                      `json:"envName,omitempty"`
 	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+	// Fields exposes keys of structured (JSON object) items as additional
+	// environment variables next to the full item.
+	// +kubebuilder:validation:Optional
+	Fields []ItemField `json:"fields,omitempty"`
+	// Delivery selects how the item reaches the container. Defaults to shell.
+	// +kubebuilder:validation:Optional
+	Delivery ItemDelivery `json:"delivery,omitempty"`
+	// PodTemplate is the full pod template used for every item. The operator
+	// injects the item plumbing into the container named by Container.
+	// +kubebuilder:validation:Optional
+	PodTemplate *corev1.PodTemplateSpec `json:"podTemplate,omitempty"`
+	// Container names the container of PodTemplate that processes the item.
+	// Defaults to the container named "main", or the first container.
+	// +kubebuilder:validation:Optional
+	Container string `json:"container,omitempty"`
+}
+
+// ItemDelivery selects how the item is handed to the workload container.
+// +kubebuilder:validation:Enum=shell;env;file
+type ItemDelivery string
+
+const (
+	// ShellDelivery sources a generated env file and runs Command joined with
+	// spaces through sh -c. Items are interpolated by the shell, so this mode
+	// must only be used with trusted lists.
+	ShellDelivery ItemDelivery = "shell"
+	// EnvDelivery exports the item and its fields verbatim as environment
+	// variables and execs Command as an argument array. Requires sh in the image.
+	EnvDelivery ItemDelivery = "env"
+	// FileDelivery mounts the item and its fields as files under /parallax and
+	// execs Command directly, without any shell involved.
+	FileDelivery ItemDelivery = "file"
+)
+
+// ItemField maps a top-level key of a JSON object item to an environment variable.
+type ItemField struct {
+	// Name is the key to read from the item object, e.g. "id".
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// EnvName overrides the variable name. Defaults to <envName>_<NAME>, e.g. ITEM_ID.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^[A-Za-z_][A-Za-z0-9_]*$`
+	EnvName string `json:"envName,omitempty"`
+}
+
+// JobFailurePolicy controls how failures of single items affect the whole run.
+type JobFailurePolicy struct {
+	// BackoffLimit is the number of pod failures across all items before the
+	// whole run is marked failed. Defaults to 6, or unlimited when
+	// backoffLimitPerIndex is set.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	BackoffLimit *int32 `json:"backoffLimit,omitempty"`
+	// BackoffLimitPerIndex is the number of retries of a single item before that
+	// item is marked failed. Other items keep running.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	BackoffLimitPerIndex *int32 `json:"backoffLimitPerIndex,omitempty"`
+	// MaxFailedIndexes marks the whole run failed once more items than this
+	// failed. Requires backoffLimitPerIndex.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	MaxFailedIndexes *int32 `json:"maxFailedIndexes,omitempty"`
+	// ActiveDeadlineSeconds bounds the duration of the whole run.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	ActiveDeadlineSeconds *int64 `json:"activeDeadlineSeconds,omitempty"`
+	// PodFailurePolicy decides per pod failure whether to retry, ignore, fail
+	// the item (FailIndex) or fail the whole run (FailJob).
+	// +kubebuilder:validation:Optional
+	PodFailurePolicy *batchv1.PodFailurePolicy `json:"podFailurePolicy,omitempty"`
 }
 
+// +kubebuilder:validation:XValidation:rule="!has(self.maxFailedIndexes) || has(self.backoffLimitPerIndex)",message="maxFailedIndexes requires backoffLimitPerIndex"
+// +kubebuilder:validation:XValidation:rule="!has(self.podFailurePolicy) || has(self.backoffLimitPerIndex) || self.podFailurePolicy.rules.all(r, r.action != 'FailIndex')",message="podFailurePolicy action FailIndex requires backoffLimitPerIndex"
 type ListJobSpec struct {
-	ListSourceRef           string           `json:"listSourceRef,omitempty"`
-	StaticList              []string         `json:"staticList,omitempty"`
-	Parallelism             int32            `json:"parallelism"`
+	ListSourceRef string   `json:"listSourceRef,omitempty"`
+	StaticList    []string `json:"staticList,omitempty"`
+	// Parallelism is the number of pods running at the same time.
+	// Defaults to 1.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=1
+	Parallelism             int32            `json:"parallelism,omitempty"`
 	Template                JobTemplateSpec  `json:"template"`
 	TTLSecondsAfterFinished *int32           `json:"ttlSecondsAfterFinished,omitempty"`
 	DeleteAfter             *metav1.Duration `json:"deleteAfter,omitempty"`
+	// BatchSize is the number of contiguous items handed to every pod.
+	// Defaults to 1.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	BatchSize        int32 `json:"batchSize,omitempty"`
+	JobFailurePolicy `json:",inline"`
+}
+
+// MaxListJobNameLength is the longest ListJob name: the name is the name of
+// its first Job and the value of a label of its Jobs, Pods and ConfigMaps.
+const MaxListJobNameLength = 63
+
+const (
+	// RetryFailedAnnotation requests a follow-up ListJob that runs only the
+	// failed items of a finished ListJob. It is removed once handled.
+	RetryFailedAnnotation = "batchops.io/retry-failed"
+	// RetryOfLabel is set on a retry ListJob to the name of the ListJob it retries.
+	RetryOfLabel = "batchops.io/retry-of"
+)
+
+// ListJobPhase is the overall state of a ListJob.
+// +kubebuilder:validation:Enum=Pending;Running;Succeeded;Failed
+type ListJobPhase string
+
+const (
+	ListJobPending   ListJobPhase = "Pending"
+	ListJobRunning   ListJobPhase = "Running"
+	ListJobSucceeded ListJobPhase = "Succeeded"
+	ListJobFailed    ListJobPhase = "Failed"
+)
+
+// ItemPhase is the state of a single item of a ListJob.
+// +kubebuilder:validation:Enum=Pending;Running;Succeeded;Failed
+type ItemPhase string
+
+const (
+	ItemPending   ItemPhase = "Pending"
+	ItemRunning   ItemPhase = "Running"
+	ItemSucceeded ItemPhase = "Succeeded"
+	ItemFailed    ItemPhase = "Failed"
+)
+
+// ItemStatus records the outcome of the item at a completion index.
+type ItemStatus struct {
+	// Index is the position of the item in the list. It equals the completion
+	// index unless batchSize is set.
+	Index int32 `json:"index"`
+	// Item is the item value, truncated for very long items.
+	Item  string    `json:"item"`
+	Phase ItemPhase `json:"phase"`
+	// PodName is the most recent pod that processed the item.
+	// +kubebuilder:validation:Optional
+	PodName string `json:"podName,omitempty"`
+	// ExitCode of the item container in PodName, once it terminated.
+	// +kubebuilder:validation:Optional
+	ExitCode *int32 `json:"exitCode,omitempty"`
+	// Message is the termination reason or message of a failed item.
+	// +kubebuilder:validation:Optional
+	Message string `json:"message,omitempty"`
 }
 
 type ListJobStatus struct {
 	JobName string `json:"jobName,omitempty"`
+	// JobNames lists the Jobs of all shards when the list is split across
+	// several Jobs, in list order.
+	JobNames []string     `json:"jobNames,omitempty"`
+	Phase    ListJobPhase `json:"phase,omitempty"`
+	// Progress is a human readable succeeded/total summary.
+	Progress       string       `json:"progress,omitempty"`
+	Total          int32        `json:"total,omitempty"`
+	Pending        int32        `json:"pending,omitempty"`
+	Running        int32        `json:"running,omitempty"`
+	Succeeded      int32        `json:"succeeded,omitempty"`
+	Failed         int32        `json:"failed,omitempty"`
+	StartTime      *metav1.Time `json:"startTime,omitempty"`
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// Items holds a record per item. For very large lists, records of failed,
+	// then running and pending items are kept in preference and ItemsTruncated
+	// is set.
+	// +listType=map
+	// +listMapKey=index
+	Items          []ItemStatus `json:"items,omitempty"`
+	ItemsTruncated bool         `json:"itemsTruncated,omitempty"`
+	// Retries lists the ListJobs created to retry the failed items of this one.
+	Retries []string `json:"retries,omitempty"`
+	// ObservedGeneration is the generation the status was computed for.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions describe the state of the ListJob, see the Condition
+	// constants for their types.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="Progress",type="string",JSONPath=".status.progress"
+// +kubebuilder:printcolumn:name="Failed",type="integer",JSONPath=".status.failed"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:validation:XValidation:rule="size(self.metadata.name) <= 63",message="name must be no more than 63 characters"
 
 type ListJob struct {
 	metav1.TypeMeta   `json:",inline"`
@@ -17,7 +17,10 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"time"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 type ListSourceType string
@@ -26,14 +29,25 @@ const (
 	StaticList   ListSourceType = "static"
 	APIList      ListSourceType = "api"
 	PostgresList ListSourceType = "postgresql"
+	MySQLList    ListSourceType = "mysql"
+	// SQLList queries a database with a driver selected by name.
+	SQLList ListSourceType = "sql"
+	// KubernetesList lists objects of the cluster.
+	KubernetesList ListSourceType = "kubernetes"
+	// S3List lists the objects of an S3 bucket.
+	S3List ListSourceType = "s3"
+	// GitList reads the items of a git repository.
+	GitList ListSourceType = "git"
 )
 
-// +kubebuilder:validation:Enum=basic;bearer
+// +kubebuilder:validation:Enum=basic;bearer;oauth2
 type APIAuthType string
 
 const (
 	BasicAuth  APIAuthType = "basic"
 	BearerAuth APIAuthType = "bearer"
+	// OAuth2Auth requests access tokens with the OAuth2 client credentials flow.
+	OAuth2Auth APIAuthType = "oauth2"
 )
 
 type APIConfig struct {
@@ -43,6 +57,156 @@ type APIConfig struct {
 	Auth    *APIAuth          `json:"auth,omitempty"`
 	// +kubebuilder:validation:Required
 	JSONPath string `json:"jsonPath,omitempty"`
+	// Method is the HTTP method of the requests. Defaults to POST when a body
+	// or GraphQL query is sent, GET otherwise.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=GET;POST;PUT
+	Method string `json:"method,omitempty"`
+	// Body is sent as the request body, e.g. the JSON query of a search endpoint.
+	// +kubebuilder:validation:Optional
+	Body string `json:"body,omitempty"`
+	// BodyFrom reads the request body from a Secret key, for bodies that
+	// carry credentials.
+	// +kubebuilder:validation:Optional
+	BodyFrom *SecretRef `json:"bodyFrom,omitempty"`
+	// GraphQL sends a GraphQL query instead of a body. Errors returned by the
+	// server fail the fetch. JSONPath applies to the whole response, e.g.
+	// "$.data.assets.nodes[*].id".
+	// +kubebuilder:validation:Optional
+	GraphQL *GraphQLRequest `json:"graphql,omitempty"`
+	// TLS configures the trust and client certificates of HTTPS requests.
+	// +kubebuilder:validation:Optional
+	TLS *APITLSConfig `json:"tls,omitempty"`
+	// Pagination fetches the list over several requests. Without it a single
+	// request returns the whole list.
+	// +kubebuilder:validation:Optional
+	Pagination *APIPagination `json:"pagination,omitempty"`
+}
+
+type APITLSConfig struct {
+	// CA is the bundle of certificate authorities trusted to sign the server
+	// certificate, in place of the system trust store.
+	// +kubebuilder:validation:Optional
+	CA *CABundleRef `json:"ca,omitempty"`
+	// ClientCertificate is presented to servers that require mutual TLS.
+	// +kubebuilder:validation:Optional
+	ClientCertificate *ClientCertificateRef `json:"clientCertificate,omitempty"`
+	// ServerName is the name the server certificate is verified against and
+	// sent for SNI. Defaults to the host of the URL.
+	// +kubebuilder:validation:Optional
+	ServerName string `json:"serverName,omitempty"`
+	// InsecureSkipVerify disables the verification of the server certificate.
+	// Every fetch records a Warning event while it is set.
+	// +kubebuilder:validation:Optional
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+}
+
+// CABundleRef references a PEM encoded CA bundle in a Secret or a ConfigMap.
+// Exactly one of them must be set.
+type CABundleRef struct {
+	// +kubebuilder:validation:Optional
+	SecretRef *SecretRef `json:"secretRef,omitempty"`
+	// +kubebuilder:validation:Optional
+	ConfigMapRef *ConfigMapRef `json:"configMapRef,omitempty"`
+}
+
+type ConfigMapRef struct {
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+	// +kubebuilder:validation:Optional
+	Namespace string `json:"namespace,omitempty"`
+	// +kubebuilder:validation:Required
+	Key string `json:"key"`
+}
+
+// ClientCertificateRef references a PEM encoded certificate and private key
+// in a Secret, such as a kubernetes.io/tls Secret.
+type ClientCertificateRef struct {
+	// +kubebuilder:validation:Required
+	SecretName string `json:"secretName"`
+	// +kubebuilder:validation:Optional
+	Namespace string `json:"namespace,omitempty"`
+	// CertificateKey is the key of the certificate. Defaults to tls.crt.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=tls.crt
+	CertificateKey string `json:"certificateKey,omitempty"`
+	// PrivateKeyKey is the key of the private key. Defaults to tls.key.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=tls.key
+	PrivateKeyKey string `json:"privateKeyKey,omitempty"`
+}
+
+type GraphQLRequest struct {
+	// Query is the GraphQL document.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Query string `json:"query"`
+	// Variables are sent with the query. The parameters of offset, page and
+	// cursor pagination are set as variables too.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Variables *runtime.RawExtension `json:"variables,omitempty"`
+	// OperationName selects the operation of a document holding several.
+	// +kubebuilder:validation:Optional
+	OperationName string `json:"operationName,omitempty"`
+}
+
+// APIPaginationType selects how the next page of an API list is requested.
+// +kubebuilder:validation:Enum=cursor;link;offset;page
+type APIPaginationType string
+
+const (
+	// CursorPagination reads a next-page token or URL from every response.
+	CursorPagination APIPaginationType = "cursor"
+	// LinkPagination follows the rel="next" URL of the Link response header (RFC 5988).
+	LinkPagination APIPaginationType = "link"
+	// OffsetPagination sends the number of items already read and a page size.
+	OffsetPagination APIPaginationType = "offset"
+	// PagePagination sends an increasing page number.
+	PagePagination APIPaginationType = "page"
+)
+
+// DefaultAPIMaxPages is the number of pages an API list may span unless
+// MaxPages says otherwise.
+const DefaultAPIMaxPages = 100
+
+type APIPagination struct {
+	// +kubebuilder:validation:Required
+	Type APIPaginationType `json:"type"`
+	// CursorPath is the JSONPath of the next-page token in the response, e.g.
+	// "$.meta.next_cursor". A missing or empty token ends the list. Required
+	// for cursor pagination.
+	// +kubebuilder:validation:Optional
+	CursorPath string `json:"cursorPath,omitempty"`
+	// CursorParam is the query parameter, or GraphQL variable, the token is
+	// sent in. When unset the token is the URL of the next page, absolute or
+	// relative to the last one.
+	// +kubebuilder:validation:Optional
+	CursorParam string `json:"cursorParam,omitempty"`
+	// OffsetParam is the query parameter of the offset. Defaults to "offset".
+	// +kubebuilder:validation:Optional
+	OffsetParam string `json:"offsetParam,omitempty"`
+	// PageParam is the query parameter of the page number. Defaults to "page".
+	// +kubebuilder:validation:Optional
+	PageParam string `json:"pageParam,omitempty"`
+	// StartPage is the number of the first page. Defaults to 1.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	StartPage *int32 `json:"startPage,omitempty"`
+	// LimitParam is the query parameter of the page size. Defaults to "limit".
+	// +kubebuilder:validation:Optional
+	LimitParam string `json:"limitParam,omitempty"`
+	// Limit is the page size to request. A page with fewer items is the last
+	// one. Required for offset pagination; with page pagination the list ends
+	// at the first empty page when unset.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	Limit int32 `json:"limit,omitempty"`
+	// MaxPages caps the number of requests of one fetch. A list that has more
+	// pages fails to fetch rather than being cut short. Defaults to 100.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	MaxPages int32 `json:"maxPages,omitempty"`
 }
 
 type APIAuth struct {
@@ -54,23 +218,476 @@ type APIAuth struct {
 	UsernameKey string `json:"usernameKey,omitempty"`
 	// +kubebuilder:validation:Required
 	PasswordKey string `json:"passwordKey,omitempty"`
+	// OAuth2 configures the client credentials flow of the oauth2 type. The
+	// client secret is read from the SecretRef key.
+	// +kubebuilder:validation:Optional
+	OAuth2 *OAuth2ClientCredentials `json:"oauth2,omitempty"`
 }
 
-type PostgresConfig struct {
+type OAuth2ClientCredentials struct {
+	// TokenURL is the token endpoint of the authorization server.
 	// +kubebuilder:validation:Required
-	ConnectionString string `json:"connectionString"`
+	TokenURL string `json:"tokenURL"`
+	// ClientIDKey is the key of the client ID in the Secret. Defaults to client_id.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=client_id
+	ClientIDKey string `json:"clientIDKey,omitempty"`
+	// Scopes are requested for the token.
+	// +kubebuilder:validation:Optional
+	Scopes []string `json:"scopes,omitempty"`
+	// Audience is sent as the audience parameter of the token request.
+	// +kubebuilder:validation:Optional
+	Audience string `json:"audience,omitempty"`
+	// EndpointParams are additional parameters of the token request.
+	// +kubebuilder:validation:Optional
+	EndpointParams map[string]string `json:"endpointParams,omitempty"`
+}
+
+// PostgresSSLMode is the sslmode of a PostgreSQL connection.
+// +kubebuilder:validation:Enum=disable;require;verify-ca;verify-full
+type PostgresSSLMode string
+
+const (
+	// SSLModeDisable connects without TLS.
+	SSLModeDisable PostgresSSLMode = "disable"
+	// SSLModeRequire connects with TLS without verifying the server, unless a
+	// CA is configured, which makes it verify-ca.
+	SSLModeRequire PostgresSSLMode = "require"
+	// SSLModeVerifyCA verifies that the server certificate is signed by the CA.
+	SSLModeVerifyCA PostgresSSLMode = "verify-ca"
+	// SSLModeVerifyFull verifies the server certificate and its host name.
+	SSLModeVerifyFull PostgresSSLMode = "verify-full"
+)
+
+// +kubebuilder:validation:XValidation:rule="has(self.connectionString) != has(self.host)",message="exactly one of connectionString or host is required"
+type PostgresConfig struct {
+	// ConnectionString is a libpq connection string or URL. The credentials
+	// of Auth are added to it.
+	// +kubebuilder:validation:Optional
+	ConnectionString string `json:"connectionString,omitempty"`
+	// Host is the server to connect to, instead of a ConnectionString.
+	// +kubebuilder:validation:Optional
+	Host string `json:"host,omitempty"`
+	// Port of the server. Defaults to 5432.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port,omitempty"`
+	// Database to connect to.
+	// +kubebuilder:validation:Optional
+	Database string `json:"database,omitempty"`
+	// User to connect as, unless Auth takes it from the Secret.
+	// +kubebuilder:validation:Optional
+	User string `json:"user,omitempty"`
+	// SSLMode overrides the sslmode of the connection. Unset uses the
+	// sslmode of the ConnectionString, verify-full when TLS is set, or disable.
+	// +kubebuilder:validation:Optional
+	SSLMode PostgresSSLMode `json:"sslMode,omitempty"`
+	// TLS holds the CA and client certificate of TLS connections.
+	// +kubebuilder:validation:Optional
+	TLS *DatabaseTLSConfig `json:"tls,omitempty"`
 	// +kubebuilder:validation:Required
-	Query string        `json:"query"`
-	Auth  *PostgresAuth `json:"auth,omitempty"`
+	Query string `json:"query"`
+	// Parameters are bound to the $1, $2, ... placeholders of the Query, in
+	// order. Their values are never spliced into the query text.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxItems=100
+	Parameters []QueryParameter `json:"parameters,omitempty"`
+	Auth       *DatabaseAuth    `json:"auth,omitempty"`
 }
 
-type PostgresAuth struct {
+// QueryRuntimeValue names a value known to the controller at fetch time.
+// +kubebuilder:validation:Enum=lastSuccessfulFetchTime;fetchTime
+type QueryRuntimeValue string
+
+const (
+	// LastSuccessfulFetchTimeValue is the start of the last successful fetch,
+	// as a timestamp, or NULL before the first one.
+	LastSuccessfulFetchTimeValue QueryRuntimeValue = "lastSuccessfulFetchTime"
+	// FetchTimeValue is the start of the current fetch, as a timestamp.
+	FetchTimeValue QueryRuntimeValue = "fetchTime"
+)
+
+// QueryParameter is the value of a query placeholder. Exactly one of Value,
+// SecretRef, ConfigMapRef or Runtime must be set.
+type QueryParameter struct {
+	// Name describes the parameter in errors and logs. It is not sent to the
+	// database.
+	// +kubebuilder:validation:Optional
+	Name string `json:"name,omitempty"`
+	// Value is a literal value.
+	// +kubebuilder:validation:Optional
+	Value *string `json:"value,omitempty"`
+	// SecretRef reads the value from a Secret key.
+	// +kubebuilder:validation:Optional
+	SecretRef *SecretRef `json:"secretRef,omitempty"`
+	// ConfigMapRef reads the value from a ConfigMap key.
+	// +kubebuilder:validation:Optional
+	ConfigMapRef *ConfigMapRef `json:"configMapRef,omitempty"`
+	// Runtime takes the value from the ListSource at fetch time.
+	// +kubebuilder:validation:Optional
+	Runtime QueryRuntimeValue `json:"runtime,omitempty"`
+}
+
+// DatabaseAuth reads the credentials of a database from a Secret.
+type DatabaseAuth struct {
 	// +kubebuilder:validation:Required
 	SecretRef SecretRef `json:"secretRef"`
+	// UsernameKey is the key of the user name in the Secret. Unset uses User.
+	// +kubebuilder:validation:Optional
+	UsernameKey string `json:"usernameKey,omitempty"`
 	// +kubebuilder:validation:Required
 	PasswordKey string `json:"passwordKey"`
 }
 
+type DatabaseTLSConfig struct {
+	// CA verifies the server certificate in the verifying TLS modes.
+	// +kubebuilder:validation:Optional
+	CA *CABundleRef `json:"ca,omitempty"`
+	// ClientCertificate authenticates the client to the server.
+	// +kubebuilder:validation:Optional
+	ClientCertificate *ClientCertificateRef `json:"clientCertificate,omitempty"`
+}
+
+// MySQLSSLMode selects whether and how MySQL connections use TLS, with the
+// meaning of the --ssl-mode option of the mysql client.
+// +kubebuilder:validation:Enum=disabled;preferred;required;verify-ca;verify-identity
+type MySQLSSLMode string
+
+const (
+	// MySQLSSLModeDisabled connects without TLS.
+	MySQLSSLModeDisabled MySQLSSLMode = "disabled"
+	// MySQLSSLModePreferred uses TLS when the server supports it, without
+	// verifying the server unless a CA is configured.
+	MySQLSSLModePreferred MySQLSSLMode = "preferred"
+	// MySQLSSLModeRequired connects with TLS without verifying the server,
+	// unless a CA is configured, which makes it verify-ca.
+	MySQLSSLModeRequired MySQLSSLMode = "required"
+	// MySQLSSLModeVerifyCA verifies that the server certificate is signed by
+	// the CA.
+	MySQLSSLModeVerifyCA MySQLSSLMode = "verify-ca"
+	// MySQLSSLModeVerifyIdentity verifies the server certificate and its host
+	// name.
+	MySQLSSLModeVerifyIdentity MySQLSSLMode = "verify-identity"
+)
+
+// MySQLConfig queries a MySQL or MariaDB database.
+// +kubebuilder:validation:XValidation:rule="has(self.dsn) != has(self.host)",message="exactly one of dsn or host is required"
+type MySQLConfig struct {
+	// DSN is a data source name of the form
+	// [user[:password]@][protocol[(address)]]/dbname[?param=value], instead of
+	// Host. The credentials of Auth are added to it.
+	// +kubebuilder:validation:Optional
+	DSN string `json:"dsn,omitempty"`
+	// Host is the server to connect to, instead of a DSN.
+	// +kubebuilder:validation:Optional
+	Host string `json:"host,omitempty"`
+	// Port of the server. Defaults to 3306.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port,omitempty"`
+	// Database to connect to.
+	// +kubebuilder:validation:Optional
+	Database string `json:"database,omitempty"`
+	// User to connect as, unless Auth takes it from the Secret.
+	// +kubebuilder:validation:Optional
+	User string `json:"user,omitempty"`
+	// SSLMode overrides the TLS setting of the connection. Unset uses the tls
+	// parameter of the DSN, or preferred.
+	// +kubebuilder:validation:Optional
+	SSLMode MySQLSSLMode `json:"sslMode,omitempty"`
+	// TLS holds the CA and client certificate of TLS connections.
+	// +kubebuilder:validation:Optional
+	TLS *DatabaseTLSConfig `json:"tls,omitempty"`
+	// ConnectTimeout bounds establishing a connection. The whole query is
+	// bounded by the timeout of the FetchPolicy.
+	// +kubebuilder:validation:Optional
+	ConnectTimeout *metav1.Duration `json:"connectTimeout,omitempty"`
+	// +kubebuilder:validation:Required
+	Query string `json:"query"`
+	// Parameters are bound to the ? placeholders of the Query, in order.
+	// Their values are never spliced into the query text.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxItems=100
+	Parameters []QueryParameter `json:"parameters,omitempty"`
+	Auth       *DatabaseAuth    `json:"auth,omitempty"`
+}
+
+// SQLDriver names a database driver compiled into the operator.
+// +kubebuilder:validation:Enum=postgres;mysql;sqlite;sqlserver
+type SQLDriver string
+
+const (
+	// PostgresDriver takes libpq connection strings or postgres:// URLs and
+	// $1, $2, ... placeholders.
+	PostgresDriver SQLDriver = "postgres"
+	// MySQLDriver takes MySQL data source names and ? placeholders.
+	MySQLDriver SQLDriver = "mysql"
+	// SQLiteDriver takes the path of a database file, or a file: URI, and ?
+	// placeholders. It is only available in operator builds with cgo.
+	SQLiteDriver SQLDriver = "sqlite"
+	// SQLServerDriver takes sqlserver:// URLs or ADO connection strings and
+	// @p1, @p2, ... placeholders.
+	SQLServerDriver SQLDriver = "sqlserver"
+)
+
+// SQLConfig queries a database through a driver of the operator's registry.
+// The DSN is passed to the driver as is, in its own format.
+// +kubebuilder:validation:XValidation:rule="has(self.dsn) != has(self.dsnFrom)",message="exactly one of dsn or dsnFrom is required"
+type SQLConfig struct {
+	// +kubebuilder:validation:Required
+	Driver SQLDriver `json:"driver"`
+	// DSN is the data source name in the format of the driver.
+	// +kubebuilder:validation:Optional
+	DSN string `json:"dsn,omitempty"`
+	// DSNFrom reads the DSN from a Secret key, for DSNs that hold credentials.
+	// +kubebuilder:validation:Optional
+	DSNFrom *SecretRef `json:"dsnFrom,omitempty"`
+	// Auth adds a user and password from a Secret to the DSN. Not supported
+	// by sqlite.
+	// +kubebuilder:validation:Optional
+	Auth *DatabaseAuth `json:"auth,omitempty"`
+	// +kubebuilder:validation:Required
+	Query string `json:"query"`
+	// Parameters are bound to the placeholders of the Query, in order, in the
+	// placeholder syntax of the driver.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxItems=100
+	Parameters []QueryParameter `json:"parameters,omitempty"`
+}
+
+// KubernetesConfig lists the objects of a kind, by the operator's own
+// permissions: it must allow the kind and be granted get, list and watch on it.
+// +kubebuilder:validation:XValidation:rule="!(has(self.namespace) && has(self.allNamespaces) && self.allNamespaces)",message="namespace and allNamespaces are mutually exclusive"
+type KubernetesConfig struct {
+	// APIVersion of the objects, e.g. "v1" or "apps/v1".
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	APIVersion string `json:"apiVersion"`
+	// Kind of the objects, e.g. "Namespace" or "Deployment".
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Kind string `json:"kind"`
+	// Namespace lists the objects of another namespace than the ListSource's,
+	// if the operator allows it. Ignored for cluster-scoped kinds.
+	// +kubebuilder:validation:Optional
+	Namespace string `json:"namespace,omitempty"`
+	// AllNamespaces lists the objects of every namespace, if the operator
+	// allows it.
+	// +kubebuilder:validation:Optional
+	AllNamespaces bool `json:"allNamespaces,omitempty"`
+	// LabelSelector selects the objects by label.
+	// +kubebuilder:validation:Optional
+	LabelSelector *metav1.LabelSelector `json:"labelSelector,omitempty"`
+	// FieldSelector selects the objects by field, e.g. "spec.nodeName=node-1",
+	// among the fields the API server supports for the kind.
+	// +kubebuilder:validation:Optional
+	FieldSelector string `json:"fieldSelector,omitempty"`
+	// JSONPath extracts the items from each object. Objects without a match
+	// yield no item. Defaults to $.metadata.name.
+	// +kubebuilder:validation:Optional
+	JSONPath string `json:"jsonPath,omitempty"`
+	// Watch refetches the list as soon as objects of the kind change, in
+	// addition to every intervalSeconds.
+	// +kubebuilder:validation:Optional
+	Watch bool `json:"watch,omitempty"`
+}
+
+// S3Config lists the objects of a bucket on Amazon S3 or any S3-compatible
+// storage, such as MinIO. The text format yields the object keys, the json
+// format objects with their key, size, etag and lastModified. Keys ending with
+// "/", the folder markers of some consoles, are skipped.
+type S3Config struct {
+	// Endpoint is the URL of the S3 API, e.g. http://minio.storage:9000.
+	// Defaults to https://s3.amazonaws.com.
+	// +kubebuilder:validation:Optional
+	Endpoint string `json:"endpoint,omitempty"`
+	// Region of the bucket. Looked up from the bucket when unset.
+	// +kubebuilder:validation:Optional
+	Region string `json:"region,omitempty"`
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Bucket string `json:"bucket"`
+	// Prefix lists the keys starting with it only, e.g. "incoming/2025/".
+	// +kubebuilder:validation:Optional
+	Prefix string `json:"prefix,omitempty"`
+	// Include keeps the keys matching one of these globs, matched against the
+	// full key. "*" and "?" do not match "/", "**" matches any number of
+	// path segments, e.g. "incoming/**/*.csv".
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxItems=100
+	Include []string `json:"include,omitempty"`
+	// Exclude drops the keys matching one of these globs.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxItems=100
+	Exclude []string `json:"exclude,omitempty"`
+	// KeyRegex keeps the keys matching this regular expression, in RE2
+	// syntax, e.g. "\\.(csv|tsv)$".
+	// +kubebuilder:validation:Optional
+	KeyRegex string `json:"keyRegex,omitempty"`
+	// ModifiedAfter keeps the objects last modified after this time.
+	// +kubebuilder:validation:Optional
+	ModifiedAfter *metav1.Time `json:"modifiedAfter,omitempty"`
+	// ModifiedWithin keeps the objects last modified within this duration
+	// before the fetch, e.g. "24h".
+	// +kubebuilder:validation:Optional
+	ModifiedWithin *metav1.Duration `json:"modifiedWithin,omitempty"`
+	// Auth signs the requests with access keys from a Secret. Unset sends
+	// anonymous requests, for public buckets.
+	// +kubebuilder:validation:Optional
+	Auth *S3Auth `json:"auth,omitempty"`
+	// TLS configures the verification of HTTPS endpoints, e.g. for a MinIO
+	// serving a certificate of a private CA.
+	// +kubebuilder:validation:Optional
+	TLS *APITLSConfig `json:"tls,omitempty"`
+}
+
+// S3Auth reads access keys from a Secret. The key of SecretRef holds the
+// secret access key.
+type S3Auth struct {
+	// +kubebuilder:validation:Required
+	SecretRef SecretRef `json:"secretRef"`
+	// AccessKeyIDKey is the key of the access key ID in the Secret.
+	// +kubebuilder:validation:Required
+	AccessKeyIDKey string `json:"accessKeyIDKey"`
+	// SessionTokenKey is the key of a session token in the Secret, for
+	// temporary credentials.
+	// +kubebuilder:validation:Optional
+	SessionTokenKey string `json:"sessionTokenKey,omitempty"`
+}
+
+// GitConfig reads the items of a git repository at a branch, tag or commit:
+// the paths matching globs, or the content of a file. The commit read is
+// recorded in status.revision.
+// +kubebuilder:validation:XValidation:rule="has(self.paths) != has(self.file)",message="exactly one of paths or file is required"
+type GitConfig struct {
+	// URL of the repository, e.g. https://github.com/org/tenants.git or
+	// git@github.com:org/tenants.git for SSH.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	URL string `json:"url"`
+	// Ref is the branch, tag or commit SHA to read. Defaults to the default
+	// branch of the repository.
+	// +kubebuilder:validation:Optional
+	Ref string `json:"ref,omitempty"`
+	// Paths yields the paths of the repository matching globs.
+	// +kubebuilder:validation:Optional
+	Paths *GitPaths `json:"paths,omitempty"`
+	// File yields the content of a file of the repository.
+	// +kubebuilder:validation:Optional
+	File *GitFile `json:"file,omitempty"`
+	// Auth reads the HTTPS or SSH credentials of the repository from a Secret.
+	// +kubebuilder:validation:Optional
+	Auth *GitAuth `json:"auth,omitempty"`
+	// CA is the bundle of certificate authorities trusted to sign the
+	// certificate of an HTTPS server, in addition to the system trust store.
+	// +kubebuilder:validation:Optional
+	CA *CABundleRef `json:"ca,omitempty"`
+}
+
+// GitPathType restricts the paths of a git source to files or directories.
+// +kubebuilder:validation:Enum=file;directory
+type GitPathType string
+
+const (
+	// GitFilePath keeps the paths of files.
+	GitFilePath GitPathType = "file"
+	// GitDirectoryPath keeps the paths of directories.
+	GitDirectoryPath GitPathType = "directory"
+)
+
+// GitPaths selects paths of a repository, relative to its root and without a
+// trailing slash, e.g. "tenants/acme".
+type GitPaths struct {
+	// Include keeps the paths matching one of these globs. "*" and "?" do not
+	// match "/", "**" matches any number of path segments, e.g. "tenants/*".
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=100
+	Include []string `json:"include"`
+	// Exclude drops the paths matching one of these globs.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxItems=100
+	Exclude []string `json:"exclude,omitempty"`
+	// Type keeps only files or only directories. Unset keeps both.
+	// +kubebuilder:validation:Optional
+	Type GitPathType `json:"type,omitempty"`
+}
+
+// GitFileFormat is how the items are read from a file.
+// +kubebuilder:validation:Enum=lines;json
+type GitFileFormat string
+
+const (
+	// GitLinesFormat yields every non-blank line of the file.
+	GitLinesFormat GitFileFormat = "lines"
+	// GitJSONFormat yields the results of a JSONPath over the file.
+	GitJSONFormat GitFileFormat = "json"
+)
+
+// GitFile reads the items of a file of a repository.
+type GitFile struct {
+	// Path of the file, relative to the root of the repository.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Path string `json:"path"`
+	// Format of the file. Defaults to lines.
+	// +kubebuilder:validation:Optional
+	Format GitFileFormat `json:"format,omitempty"`
+	// JSONPath extracts the items of a json file, e.g. $.tenants[*].name.
+	// Defaults to $[*], the elements of a top-level array.
+	// +kubebuilder:validation:Optional
+	JSONPath string `json:"jsonPath,omitempty"`
+}
+
+// GitAuth reads the credentials of a repository from a Secret. The key of
+// SecretRef holds the password or token for HTTPS URLs, and the PEM encoded
+// private key for SSH URLs.
+type GitAuth struct {
+	// +kubebuilder:validation:Required
+	SecretRef SecretRef `json:"secretRef"`
+	// UsernameKey is the key of the HTTPS user name in the Secret. Unset
+	// sends "git", which hosts authenticating by token accept.
+	// +kubebuilder:validation:Optional
+	UsernameKey string `json:"usernameKey,omitempty"`
+	// PassphraseKey is the key of the passphrase of an encrypted SSH key.
+	// +kubebuilder:validation:Optional
+	PassphraseKey string `json:"passphraseKey,omitempty"`
+	// KnownHostsKey is the key of the known_hosts entries that verify the
+	// host key of SSH servers. Required for SSH unless InsecureIgnoreHostKey
+	// is set.
+	// +kubebuilder:validation:Optional
+	KnownHostsKey string `json:"knownHostsKey,omitempty"`
+	// InsecureIgnoreHostKey accepts any SSH host key.
+	// +kubebuilder:validation:Optional
+	InsecureIgnoreHostKey bool `json:"insecureIgnoreHostKey,omitempty"`
+}
+
+// ItemFormat controls how each item is serialized into the ListSource ConfigMap.
+// +kubebuilder:validation:Enum=text;json
+type ItemFormat string
+
+const (
+	// TextItemFormat stores every item as a plain string, one per line.
+	TextItemFormat ItemFormat = "text"
+	// JSONItemFormat stores every item as a compact JSON document, one per line,
+	// so that objects keep their structure and can be split into fields by consumers.
+	JSONItemFormat ItemFormat = "json"
+)
+
+// ListCompression controls how items are encoded in the ListSource ConfigMaps.
+// +kubebuilder:validation:Enum=none;gzip
+type ListCompression string
+
+const (
+	// NoCompression stores the items as text under the "items" key.
+	NoCompression ListCompression = "none"
+	// GzipCompression stores the gzip-compressed items under the "items.gz"
+	// binaryData key.
+	GzipCompression ListCompression = "gzip"
+)
+
 type SecretRef struct {
 	// +kubebuilder:validation:Required
 	Name string `json:"name"`
@@ -82,13 +699,102 @@ type SecretRef struct {
 
 type ListSourceSpec struct {
 	// +kubebuilder:validation:Required
-	// +kubebuilder:validation:Enum=static;api;postgresql
+	// +kubebuilder:validation:Enum=static;api;postgresql;mysql;sql;kubernetes;s3;git
 	Type ListSourceType `json:"type"`
 	// +kubebuilder:validation:Minimum=1
-	IntervalSeconds int             `json:"intervalSeconds,omitempty"`
-	API             *APIConfig      `json:"api,omitempty"`
-	Postgres        *PostgresConfig `json:"postgres,omitempty"`
-	StaticList      []string        `json:"staticList,omitempty"`
+	IntervalSeconds int `json:"intervalSeconds,omitempty"`
+	// ItemFormat selects how items are stored in the ConfigMap. Defaults to text.
+	// +kubebuilder:validation:Optional
+	ItemFormat ItemFormat `json:"itemFormat,omitempty"`
+	// Compression selects how items are encoded in the ConfigMap. Lists larger
+	// than a single ConfigMap are split across <name>-shard-<n> ConfigMaps
+	// either way. Defaults to none.
+	// +kubebuilder:validation:Optional
+	Compression ListCompression   `json:"compression,omitempty"`
+	API         *APIConfig        `json:"api,omitempty"`
+	Postgres    *PostgresConfig   `json:"postgres,omitempty"`
+	MySQL       *MySQLConfig      `json:"mysql,omitempty"`
+	SQL         *SQLConfig        `json:"sql,omitempty"`
+	Kubernetes  *KubernetesConfig `json:"kubernetes,omitempty"`
+	S3          *S3Config         `json:"s3,omitempty"`
+	Git         *GitConfig        `json:"git,omitempty"`
+	StaticList  []string          `json:"staticList,omitempty"`
+	// FetchPolicy controls the timeouts and retries of fetching the list.
+	// +kubebuilder:validation:Optional
+	FetchPolicy *FetchPolicy `json:"fetchPolicy,omitempty"`
+	// UpdatePolicy guards the served list against failing sources and
+	// suspicious updates.
+	// +kubebuilder:validation:Optional
+	UpdatePolicy *UpdatePolicy `json:"updatePolicy,omitempty"`
+}
+
+// UpdatePolicy decides how long the last good list is served and which
+// fetched lists replace it. A rejected list counts as a failed fetch.
+type UpdatePolicy struct {
+	// MaxStaleness is how long the last good list is served while fetches
+	// fail or are rejected. Once the list is older, the ListSource is no
+	// longer Ready and ListJobs referencing it fail to start. Unset serves the
+	// list indefinitely.
+	// +kubebuilder:validation:Optional
+	MaxStaleness *metav1.Duration `json:"maxStaleness,omitempty"`
+	// MinItems rejects fetched lists with fewer items.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	MinItems *int32 `json:"minItems,omitempty"`
+	// MaxChangePercent rejects fetched lists that add and remove more items,
+	// together, than this percentage of the served list. The first list is
+	// always accepted.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	MaxChangePercent *int32 `json:"maxChangePercent,omitempty"`
+}
+
+// Defaults of FetchPolicy.
+const (
+	DefaultFetchTimeout      = 30 * time.Second
+	DefaultRetryBackoff      = time.Second
+	DefaultMaxRetryBackoff   = 30 * time.Second
+	DefaultFailureBackoff    = 10 * time.Second
+	DefaultMaxFailureBackoff = 10 * time.Minute
+)
+
+// FetchPolicy controls how hard a fetch tries before it fails, and how soon a
+// failed fetch is started over.
+type FetchPolicy struct {
+	// Timeout bounds every attempt: one API request, or connecting to and
+	// querying the database. Defaults to 30s.
+	// +kubebuilder:validation:Optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// Retries is the number of times a failed attempt is repeated before the
+	// fetch fails. Defaults to 0.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=10
+	Retries int32 `json:"retries,omitempty"`
+	// RetryBackoff is the delay between retries. Defaults to 1s, doubling up to 30s.
+	// +kubebuilder:validation:Optional
+	RetryBackoff *Backoff `json:"retryBackoff,omitempty"`
+	// RetryOnStatusCodes are the API response statuses that are retried.
+	// Connection errors and timeouts are always retried. A Retry-After header
+	// sent with the response is honored. Defaults to 429, 500, 502, 503 and 504.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:items:Minimum=400
+	// +kubebuilder:validation:items:Maximum=599
+	RetryOnStatusCodes []int32 `json:"retryOnStatusCodes,omitempty"`
+	// FailureBackoff is the delay before a failed fetch is started over,
+	// independent of IntervalSeconds. It grows with every fetch that fails in
+	// a row. Defaults to 10s, doubling up to 10m.
+	// +kubebuilder:validation:Optional
+	FailureBackoff *Backoff `json:"failureBackoff,omitempty"`
+}
+
+// Backoff is an exponential delay. Every delay is doubled from Initial up to
+// Max, and randomly shortened by up to half to spread out retries.
+type Backoff struct {
+	// +kubebuilder:validation:Optional
+	Initial *metav1.Duration `json:"initial,omitempty"`
+	// +kubebuilder:validation:Optional
+	Max *metav1.Duration `json:"max,omitempty"`
 }
 
 type ListSourceStatus struct {
@@ -96,14 +802,46 @@ type ListSourceStatus struct {
 	ItemCount      int          `json:"itemCount,omitempty"`
 	Error          string       `json:"error,omitempty"`
 	State          string       `json:"state,omitempty"`
+	// Shards is the number of ConfigMaps holding the items, when more than one.
+	Shards int `json:"shards,omitempty"`
+	// LastRefreshRequest is the value of the refresh-requested annotation seen
+	// by the last successful fetch.
+	LastRefreshRequest string `json:"lastRefreshRequest,omitempty"`
+	// LastSuccessfulFetchTime is when the fetch of the served list started.
+	LastSuccessfulFetchTime *metav1.Time `json:"lastSuccessfulFetchTime,omitempty"`
+	// FetchAttempts is the number of attempts the last fetch made, counting
+	// the retries of all its requests.
+	FetchAttempts int32 `json:"fetchAttempts,omitempty"`
+	// ConsecutiveFailures is the number of fetches that failed in a row.
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
+	// NextRetryTime is when a failed fetch is started over.
+	NextRetryTime *metav1.Time `json:"nextRetryTime,omitempty"`
+	// ObservedGeneration is the generation the status was computed for.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Revision is the version of the source the served list was read from:
+	// the commit SHA for git sources.
+	Revision string `json:"revision,omitempty"`
+	// Conditions describe the state of the ListSource, see the Condition
+	// constants for their types.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
+// RefreshRequestedAnnotation asks for the items of a ListSource to be fetched
+// right away. Once a fetch that saw it succeeds, its value is copied to
+// status.lastRefreshRequest.
+const RefreshRequestedAnnotation = "batchops.io/refresh-requested"
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="Type",type="string",JSONPath=".spec.type"
 // +kubebuilder:printcolumn:name="Items",type="integer",JSONPath=".status.itemCount"
 // +kubebuilder:printcolumn:name="Last Update",type="date",JSONPath=".status.lastUpdateTime"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
 // +kubebuilder:printcolumn:name="Error",type="string",JSONPath=".status.error"
+// +kubebuilder:printcolumn:name="Revision",type="string",JSONPath=".status.revision",priority=1
 // +kubebuilder:validation:Required
 type ListSource struct {
 	metav1.TypeMeta   `json:",inline"`
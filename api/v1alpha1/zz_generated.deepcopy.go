@@ -21,15 +21,21 @@ limitations under the License.
 package v1alpha1
 
 import (
+	batchv1 "k8s.io/api/batch/v1"
 	"k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *APIAuth) DeepCopyInto(out *APIAuth) {
 	*out = *in
 	out.SecretRef = in.SecretRef
+	if in.OAuth2 != nil {
+		in, out := &in.OAuth2, &out.OAuth2
+		*out = new(OAuth2ClientCredentials)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIAuth.
@@ -55,8 +61,28 @@ func (in *APIConfig) DeepCopyInto(out *APIConfig) {
 	if in.Auth != nil {
 		in, out := &in.Auth, &out.Auth
 		*out = new(APIAuth)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BodyFrom != nil {
+		in, out := &in.BodyFrom, &out.BodyFrom
+		*out = new(SecretRef)
 		**out = **in
 	}
+	if in.GraphQL != nil {
+		in, out := &in.GraphQL, &out.GraphQL
+		*out = new(GraphQLRequest)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(APITLSConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Pagination != nil {
+		in, out := &in.Pagination, &out.Pagination
+		*out = new(APIPagination)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIConfig.
@@ -69,6 +95,393 @@ func (in *APIConfig) DeepCopy() *APIConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *APIPagination) DeepCopyInto(out *APIPagination) {
+	*out = *in
+	if in.StartPage != nil {
+		in, out := &in.StartPage, &out.StartPage
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIPagination.
+func (in *APIPagination) DeepCopy() *APIPagination {
+	if in == nil {
+		return nil
+	}
+	out := new(APIPagination)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *APITLSConfig) DeepCopyInto(out *APITLSConfig) {
+	*out = *in
+	if in.CA != nil {
+		in, out := &in.CA, &out.CA
+		*out = new(CABundleRef)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClientCertificate != nil {
+		in, out := &in.ClientCertificate, &out.ClientCertificate
+		*out = new(ClientCertificateRef)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APITLSConfig.
+func (in *APITLSConfig) DeepCopy() *APITLSConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(APITLSConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Backoff) DeepCopyInto(out *Backoff) {
+	*out = *in
+	if in.Initial != nil {
+		in, out := &in.Initial, &out.Initial
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Max != nil {
+		in, out := &in.Max, &out.Max
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Backoff.
+func (in *Backoff) DeepCopy() *Backoff {
+	if in == nil {
+		return nil
+	}
+	out := new(Backoff)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CABundleRef) DeepCopyInto(out *CABundleRef) {
+	*out = *in
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(SecretRef)
+		**out = **in
+	}
+	if in.ConfigMapRef != nil {
+		in, out := &in.ConfigMapRef, &out.ConfigMapRef
+		*out = new(ConfigMapRef)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CABundleRef.
+func (in *CABundleRef) DeepCopy() *CABundleRef {
+	if in == nil {
+		return nil
+	}
+	out := new(CABundleRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClientCertificateRef) DeepCopyInto(out *ClientCertificateRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClientCertificateRef.
+func (in *ClientCertificateRef) DeepCopy() *ClientCertificateRef {
+	if in == nil {
+		return nil
+	}
+	out := new(ClientCertificateRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigMapRef) DeepCopyInto(out *ConfigMapRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigMapRef.
+func (in *ConfigMapRef) DeepCopy() *ConfigMapRef {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigMapRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseAuth) DeepCopyInto(out *DatabaseAuth) {
+	*out = *in
+	out.SecretRef = in.SecretRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseAuth.
+func (in *DatabaseAuth) DeepCopy() *DatabaseAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseTLSConfig) DeepCopyInto(out *DatabaseTLSConfig) {
+	*out = *in
+	if in.CA != nil {
+		in, out := &in.CA, &out.CA
+		*out = new(CABundleRef)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClientCertificate != nil {
+		in, out := &in.ClientCertificate, &out.ClientCertificate
+		*out = new(ClientCertificateRef)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseTLSConfig.
+func (in *DatabaseTLSConfig) DeepCopy() *DatabaseTLSConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseTLSConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FetchPolicy) DeepCopyInto(out *FetchPolicy) {
+	*out = *in
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.RetryBackoff != nil {
+		in, out := &in.RetryBackoff, &out.RetryBackoff
+		*out = new(Backoff)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RetryOnStatusCodes != nil {
+		in, out := &in.RetryOnStatusCodes, &out.RetryOnStatusCodes
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	if in.FailureBackoff != nil {
+		in, out := &in.FailureBackoff, &out.FailureBackoff
+		*out = new(Backoff)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FetchPolicy.
+func (in *FetchPolicy) DeepCopy() *FetchPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(FetchPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitAuth) DeepCopyInto(out *GitAuth) {
+	*out = *in
+	out.SecretRef = in.SecretRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitAuth.
+func (in *GitAuth) DeepCopy() *GitAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(GitAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitConfig) DeepCopyInto(out *GitConfig) {
+	*out = *in
+	if in.Paths != nil {
+		in, out := &in.Paths, &out.Paths
+		*out = new(GitPaths)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.File != nil {
+		in, out := &in.File, &out.File
+		*out = new(GitFile)
+		**out = **in
+	}
+	if in.Auth != nil {
+		in, out := &in.Auth, &out.Auth
+		*out = new(GitAuth)
+		**out = **in
+	}
+	if in.CA != nil {
+		in, out := &in.CA, &out.CA
+		*out = new(CABundleRef)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitConfig.
+func (in *GitConfig) DeepCopy() *GitConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(GitConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitFile) DeepCopyInto(out *GitFile) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitFile.
+func (in *GitFile) DeepCopy() *GitFile {
+	if in == nil {
+		return nil
+	}
+	out := new(GitFile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitPaths) DeepCopyInto(out *GitPaths) {
+	*out = *in
+	if in.Include != nil {
+		in, out := &in.Include, &out.Include
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Exclude != nil {
+		in, out := &in.Exclude, &out.Exclude
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitPaths.
+func (in *GitPaths) DeepCopy() *GitPaths {
+	if in == nil {
+		return nil
+	}
+	out := new(GitPaths)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GraphQLRequest) DeepCopyInto(out *GraphQLRequest) {
+	*out = *in
+	if in.Variables != nil {
+		in, out := &in.Variables, &out.Variables
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GraphQLRequest.
+func (in *GraphQLRequest) DeepCopy() *GraphQLRequest {
+	if in == nil {
+		return nil
+	}
+	out := new(GraphQLRequest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ItemField) DeepCopyInto(out *ItemField) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ItemField.
+func (in *ItemField) DeepCopy() *ItemField {
+	if in == nil {
+		return nil
+	}
+	out := new(ItemField)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ItemStatus) DeepCopyInto(out *ItemStatus) {
+	*out = *in
+	if in.ExitCode != nil {
+		in, out := &in.ExitCode, &out.ExitCode
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ItemStatus.
+func (in *ItemStatus) DeepCopy() *ItemStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ItemStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobFailurePolicy) DeepCopyInto(out *JobFailurePolicy) {
+	*out = *in
+	if in.BackoffLimit != nil {
+		in, out := &in.BackoffLimit, &out.BackoffLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.BackoffLimitPerIndex != nil {
+		in, out := &in.BackoffLimitPerIndex, &out.BackoffLimitPerIndex
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxFailedIndexes != nil {
+		in, out := &in.MaxFailedIndexes, &out.MaxFailedIndexes
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ActiveDeadlineSeconds != nil {
+		in, out := &in.ActiveDeadlineSeconds, &out.ActiveDeadlineSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.PodFailurePolicy != nil {
+		in, out := &in.PodFailurePolicy, &out.PodFailurePolicy
+		*out = new(batchv1.PodFailurePolicy)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobFailurePolicy.
+func (in *JobFailurePolicy) DeepCopy() *JobFailurePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(JobFailurePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *JobTemplateSpec) DeepCopyInto(out *JobTemplateSpec) {
 	*out = *in
@@ -78,6 +491,16 @@ func (in *JobTemplateSpec) DeepCopyInto(out *JobTemplateSpec) {
 		copy(*out, *in)
 	}
 	in.Resources.DeepCopyInto(&out.Resources)
+	if in.Fields != nil {
+		in, out := &in.Fields, &out.Fields
+		*out = make([]ItemField, len(*in))
+		copy(*out, *in)
+	}
+	if in.PodTemplate != nil {
+		in, out := &in.PodTemplate, &out.PodTemplate
+		*out = new(v1.PodTemplateSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobTemplateSpec.
@@ -90,6 +513,26 @@ func (in *JobTemplateSpec) DeepCopy() *JobTemplateSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubernetesConfig) DeepCopyInto(out *KubernetesConfig) {
+	*out = *in
+	if in.LabelSelector != nil {
+		in, out := &in.LabelSelector, &out.LabelSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubernetesConfig.
+func (in *KubernetesConfig) DeepCopy() *KubernetesConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(KubernetesConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ListCronJob) DeepCopyInto(out *ListCronJob) {
 	*out = *in
@@ -183,6 +626,7 @@ func (in *ListCronJobSpec) DeepCopyInto(out *ListCronJobSpec) {
 		*out = new(bool)
 		**out = **in
 	}
+	in.JobFailurePolicy.DeepCopyInto(&out.JobFailurePolicy)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListCronJobSpec.
@@ -207,6 +651,21 @@ func (in *ListCronJobStatus) DeepCopyInto(out *ListCronJobStatus) {
 		in, out := &in.LastScheduleTime, &out.LastScheduleTime
 		*out = (*in).DeepCopy()
 	}
+	if in.LastSuccessfulTime != nil {
+		in, out := &in.LastSuccessfulTime, &out.LastSuccessfulTime
+		*out = (*in).DeepCopy()
+	}
+	if in.NextScheduleTime != nil {
+		in, out := &in.NextScheduleTime, &out.NextScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListCronJobStatus.
@@ -225,7 +684,7 @@ func (in *ListJob) DeepCopyInto(out *ListJob) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListJob.
@@ -297,6 +756,7 @@ func (in *ListJobSpec) DeepCopyInto(out *ListJobSpec) {
 		*out = new(metav1.Duration)
 		**out = **in
 	}
+	in.JobFailurePolicy.DeepCopyInto(&out.JobFailurePolicy)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListJobSpec.
@@ -312,6 +772,38 @@ func (in *ListJobSpec) DeepCopy() *ListJobSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ListJobStatus) DeepCopyInto(out *ListJobStatus) {
 	*out = *in
+	if in.JobNames != nil {
+		in, out := &in.JobNames, &out.JobNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ItemStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Retries != nil {
+		in, out := &in.Retries, &out.Retries
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListJobStatus.
@@ -396,11 +888,46 @@ func (in *ListSourceSpec) DeepCopyInto(out *ListSourceSpec) {
 		*out = new(PostgresConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.MySQL != nil {
+		in, out := &in.MySQL, &out.MySQL
+		*out = new(MySQLConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SQL != nil {
+		in, out := &in.SQL, &out.SQL
+		*out = new(SQLConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Kubernetes != nil {
+		in, out := &in.Kubernetes, &out.Kubernetes
+		*out = new(KubernetesConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.S3 != nil {
+		in, out := &in.S3, &out.S3
+		*out = new(S3Config)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Git != nil {
+		in, out := &in.Git, &out.Git
+		*out = new(GitConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.StaticList != nil {
 		in, out := &in.StaticList, &out.StaticList
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.FetchPolicy != nil {
+		in, out := &in.FetchPolicy, &out.FetchPolicy
+		*out = new(FetchPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.UpdatePolicy != nil {
+		in, out := &in.UpdatePolicy, &out.UpdatePolicy
+		*out = new(UpdatePolicy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListSourceSpec.
@@ -420,6 +947,21 @@ func (in *ListSourceStatus) DeepCopyInto(out *ListSourceStatus) {
 		in, out := &in.LastUpdateTime, &out.LastUpdateTime
 		*out = (*in).DeepCopy()
 	}
+	if in.LastSuccessfulFetchTime != nil {
+		in, out := &in.LastSuccessfulFetchTime, &out.LastSuccessfulFetchTime
+		*out = (*in).DeepCopy()
+	}
+	if in.NextRetryTime != nil {
+		in, out := &in.NextRetryTime, &out.NextRetryTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListSourceStatus.
@@ -433,17 +975,65 @@ func (in *ListSourceStatus) DeepCopy() *ListSourceStatus {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PostgresAuth) DeepCopyInto(out *PostgresAuth) {
+func (in *MySQLConfig) DeepCopyInto(out *MySQLConfig) {
 	*out = *in
-	out.SecretRef = in.SecretRef
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(DatabaseTLSConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConnectTimeout != nil {
+		in, out := &in.ConnectTimeout, &out.ConnectTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make([]QueryParameter, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Auth != nil {
+		in, out := &in.Auth, &out.Auth
+		*out = new(DatabaseAuth)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MySQLConfig.
+func (in *MySQLConfig) DeepCopy() *MySQLConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(MySQLConfig)
+	in.DeepCopyInto(out)
+	return out
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresAuth.
-func (in *PostgresAuth) DeepCopy() *PostgresAuth {
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OAuth2ClientCredentials) DeepCopyInto(out *OAuth2ClientCredentials) {
+	*out = *in
+	if in.Scopes != nil {
+		in, out := &in.Scopes, &out.Scopes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.EndpointParams != nil {
+		in, out := &in.EndpointParams, &out.EndpointParams
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OAuth2ClientCredentials.
+func (in *OAuth2ClientCredentials) DeepCopy() *OAuth2ClientCredentials {
 	if in == nil {
 		return nil
 	}
-	out := new(PostgresAuth)
+	out := new(OAuth2ClientCredentials)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -451,9 +1041,21 @@ func (in *PostgresAuth) DeepCopy() *PostgresAuth {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PostgresConfig) DeepCopyInto(out *PostgresConfig) {
 	*out = *in
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(DatabaseTLSConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make([]QueryParameter, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Auth != nil {
 		in, out := &in.Auth, &out.Auth
-		*out = new(PostgresAuth)
+		*out = new(DatabaseAuth)
 		**out = **in
 	}
 }
@@ -468,6 +1070,128 @@ func (in *PostgresConfig) DeepCopy() *PostgresConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QueryParameter) DeepCopyInto(out *QueryParameter) {
+	*out = *in
+	if in.Value != nil {
+		in, out := &in.Value, &out.Value
+		*out = new(string)
+		**out = **in
+	}
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(SecretRef)
+		**out = **in
+	}
+	if in.ConfigMapRef != nil {
+		in, out := &in.ConfigMapRef, &out.ConfigMapRef
+		*out = new(ConfigMapRef)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QueryParameter.
+func (in *QueryParameter) DeepCopy() *QueryParameter {
+	if in == nil {
+		return nil
+	}
+	out := new(QueryParameter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *S3Auth) DeepCopyInto(out *S3Auth) {
+	*out = *in
+	out.SecretRef = in.SecretRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3Auth.
+func (in *S3Auth) DeepCopy() *S3Auth {
+	if in == nil {
+		return nil
+	}
+	out := new(S3Auth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *S3Config) DeepCopyInto(out *S3Config) {
+	*out = *in
+	if in.Include != nil {
+		in, out := &in.Include, &out.Include
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Exclude != nil {
+		in, out := &in.Exclude, &out.Exclude
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ModifiedAfter != nil {
+		in, out := &in.ModifiedAfter, &out.ModifiedAfter
+		*out = (*in).DeepCopy()
+	}
+	if in.ModifiedWithin != nil {
+		in, out := &in.ModifiedWithin, &out.ModifiedWithin
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Auth != nil {
+		in, out := &in.Auth, &out.Auth
+		*out = new(S3Auth)
+		**out = **in
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(APITLSConfig)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3Config.
+func (in *S3Config) DeepCopy() *S3Config {
+	if in == nil {
+		return nil
+	}
+	out := new(S3Config)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SQLConfig) DeepCopyInto(out *SQLConfig) {
+	*out = *in
+	if in.DSNFrom != nil {
+		in, out := &in.DSNFrom, &out.DSNFrom
+		*out = new(SecretRef)
+		**out = **in
+	}
+	if in.Auth != nil {
+		in, out := &in.Auth, &out.Auth
+		*out = new(DatabaseAuth)
+		**out = **in
+	}
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make([]QueryParameter, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SQLConfig.
+func (in *SQLConfig) DeepCopy() *SQLConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SQLConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SecretRef) DeepCopyInto(out *SecretRef) {
 	*out = *in
@@ -482,3 +1206,33 @@ func (in *SecretRef) DeepCopy() *SecretRef {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpdatePolicy) DeepCopyInto(out *UpdatePolicy) {
+	*out = *in
+	if in.MaxStaleness != nil {
+		in, out := &in.MaxStaleness, &out.MaxStaleness
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.MinItems != nil {
+		in, out := &in.MinItems, &out.MinItems
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxChangePercent != nil {
+		in, out := &in.MaxChangePercent, &out.MaxChangePercent
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpdatePolicy.
+func (in *UpdatePolicy) DeepCopy() *UpdatePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(UpdatePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -21,16 +21,22 @@ import (
 	"flag"
 	"os"
 	"path/filepath"
+	"strings"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
@@ -39,6 +45,7 @@ import (
 
 	batchopsv1alpha1 "github.com/matanryngler/parallax/api/v1alpha1"
 	"github.com/matanryngler/parallax/internal/controller"
+	webhookbatchopsv1alpha1 "github.com/matanryngler/parallax/internal/webhook/v1alpha1"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -63,6 +70,9 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var maxConcurrentFetches int
+	var kubernetesSourceKinds string
+	var kubernetesSourceCrossNamespace bool
 	var tlsOpts []func(*tls.Config)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
@@ -81,6 +91,13 @@ func main() {
 	flag.StringVar(&metricsCertKey, "metrics-cert-key", "tls.key", "The name of the metrics server key file.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.IntVar(&maxConcurrentFetches, "max-concurrent-fetches", 4,
+		"The number of ListSources fetched at the same time.")
+	flag.StringVar(&kubernetesSourceKinds, "kubernetes-source-kinds", "",
+		"Comma-separated kinds kubernetes ListSources may list, as Kind for the core group or Kind.group, "+
+			"e.g. Node,Namespace,Deployment.apps. Secrets are never listed.")
+	flag.BoolVar(&kubernetesSourceCrossNamespace, "kubernetes-source-cross-namespace", false,
+		"If set, kubernetes ListSources may list objects of other namespaces than their own and of all namespaces.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -178,6 +195,13 @@ func main() {
 		})
 	}
 
+	// Only the pods of ListJobs are watched, don't cache every pod in the cluster
+	listJobPods, err := labels.Parse("listjob")
+	if err != nil {
+		setupLog.Error(err, "unable to parse pod label selector")
+		os.Exit(1)
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
 		Metrics:                metricsServerOptions,
@@ -185,6 +209,11 @@ func main() {
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "b4d5402e.batchops.io",
+		Cache: cache.Options{
+			ByObject: map[client.Object]cache.ByObject{
+				&corev1.Pod{}: {Label: listJobPods},
+			},
+		},
 		// LeaderElectionReleaseOnCancel defines if the leader should step down voluntarily
 		// when the Manager ends. This requires the binary to immediately end when the
 		// Manager is stopped, otherwise, this setting is unsafe. Setting this significantly
@@ -202,30 +231,56 @@ func main() {
 		os.Exit(1)
 	}
 
+	var kinds []schema.GroupKind
+	for _, kind := range strings.Split(kubernetesSourceKinds, ",") {
+		if kind = strings.TrimSpace(kind); kind != "" {
+			kinds = append(kinds, schema.ParseGroupKind(kind))
+		}
+	}
 	if err = (&controller.ListSourceReconciler{
-		Client:   mgr.GetClient(),
-		Scheme:   mgr.GetScheme(),
-		Recorder: mgr.GetEventRecorderFor("listsource-controller"),
+		Client:                   mgr.GetClient(),
+		Scheme:                   mgr.GetScheme(),
+		Recorder:                 mgr.GetEventRecorderFor("listsource-controller"),
+		MaxConcurrentReconciles:  maxConcurrentFetches,
+		KubernetesKinds:          kinds,
+		KubernetesCrossNamespace: kubernetesSourceCrossNamespace,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ListSource")
 		os.Exit(1)
 	}
 
 	if err = (&controller.ListJobReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("listjob-controller"),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ListJob")
 		os.Exit(1)
 	}
 
 	if err = (&controller.ListCronJobReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("listcronjob-controller"),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ListCronJob")
 		os.Exit(1)
 	}
+	// nolint:goconst
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err = webhookbatchopsv1alpha1.SetupListSourceWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ListSource")
+			os.Exit(1)
+		}
+		if err = webhookbatchopsv1alpha1.SetupListJobWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ListJob")
+			os.Exit(1)
+		}
+		if err = webhookbatchopsv1alpha1.SetupListCronJobWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ListCronJob")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	if metricsCertWatcher != nil {
@@ -0,0 +1,80 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	batchopsv1alpha1 "github.com/matanryngler/parallax/api/v1alpha1"
+)
+
+// setCondition sets a condition observed at generation. The transition time
+// only changes when the status of the condition does.
+func setCondition(conditions *[]metav1.Condition, conditionType string, status bool, reason, message string, generation int64) {
+	conditionStatus := metav1.ConditionFalse
+	if status {
+		conditionStatus = metav1.ConditionTrue
+	}
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             conditionStatus,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: generation,
+	})
+}
+
+// setListJobConditions derives the conditions of a ListJob from its status.
+func setListJobConditions(status *batchopsv1alpha1.ListJobStatus, generation int64) {
+	status.ObservedGeneration = generation
+
+	if status.JobName != "" {
+		jobs := max(len(status.JobNames), 1)
+		setCondition(&status.Conditions, batchopsv1alpha1.ConditionReady, true, "JobsCreated",
+			fmt.Sprintf("Created %d Jobs for %d items", jobs, status.Total), generation)
+	}
+
+	phase := string(status.Phase)
+	if phase == "" {
+		phase = string(batchopsv1alpha1.ListJobPending)
+	}
+	switch status.Phase {
+	case batchopsv1alpha1.ListJobSucceeded:
+		setCondition(&status.Conditions, batchopsv1alpha1.ConditionComplete, true, "Succeeded",
+			fmt.Sprintf("All %d items succeeded", status.Total), generation)
+		setCondition(&status.Conditions, batchopsv1alpha1.ConditionFailed, false, phase, "", generation)
+	case batchopsv1alpha1.ListJobFailed:
+		setCondition(&status.Conditions, batchopsv1alpha1.ConditionComplete, false, phase,
+			fmt.Sprintf("%s items succeeded", status.Progress), generation)
+		setCondition(&status.Conditions, batchopsv1alpha1.ConditionFailed, true, "JobFailed",
+			fmt.Sprintf("%d of %d items failed", status.Failed, status.Total), generation)
+	default:
+		setCondition(&status.Conditions, batchopsv1alpha1.ConditionComplete, false, phase,
+			fmt.Sprintf("%s items succeeded", status.Progress), generation)
+		setCondition(&status.Conditions, batchopsv1alpha1.ConditionFailed, false, phase, "", generation)
+	}
+
+	if status.Failed > 0 {
+		setCondition(&status.Conditions, batchopsv1alpha1.ConditionDegraded, true, "ItemsFailed",
+			fmt.Sprintf("%d of %d items failed", status.Failed, status.Total), generation)
+	} else {
+		setCondition(&status.Conditions, batchopsv1alpha1.ConditionDegraded, false, "NoFailedItems", "", generation)
+	}
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	batchopsv1alpha1 "github.com/matanryngler/parallax/api/v1alpha1"
+)
+
+func TestSetListJobConditions(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       batchopsv1alpha1.ListJobStatus
+		wantReady    metav1.ConditionStatus
+		wantComplete metav1.ConditionStatus
+		wantFailed   metav1.ConditionStatus
+		wantDegraded metav1.ConditionStatus
+	}{
+		{
+			name: "Running",
+			status: batchopsv1alpha1.ListJobStatus{
+				JobName: "job", Phase: batchopsv1alpha1.ListJobRunning, Total: 3, Succeeded: 1, Progress: "1/3",
+			},
+			wantReady:    metav1.ConditionTrue,
+			wantComplete: metav1.ConditionFalse,
+			wantFailed:   metav1.ConditionFalse,
+			wantDegraded: metav1.ConditionFalse,
+		},
+		{
+			name: "Running With Failed Items",
+			status: batchopsv1alpha1.ListJobStatus{
+				JobName: "job", Phase: batchopsv1alpha1.ListJobRunning, Total: 3, Failed: 1, Progress: "0/3",
+			},
+			wantReady:    metav1.ConditionTrue,
+			wantComplete: metav1.ConditionFalse,
+			wantFailed:   metav1.ConditionFalse,
+			wantDegraded: metav1.ConditionTrue,
+		},
+		{
+			name: "Succeeded",
+			status: batchopsv1alpha1.ListJobStatus{
+				JobName: "job", Phase: batchopsv1alpha1.ListJobSucceeded, Total: 3, Succeeded: 3, Progress: "3/3",
+			},
+			wantReady:    metav1.ConditionTrue,
+			wantComplete: metav1.ConditionTrue,
+			wantFailed:   metav1.ConditionFalse,
+			wantDegraded: metav1.ConditionFalse,
+		},
+		{
+			name: "Failed",
+			status: batchopsv1alpha1.ListJobStatus{
+				JobName: "job", Phase: batchopsv1alpha1.ListJobFailed, Total: 3, Succeeded: 2, Failed: 1, Progress: "2/3",
+			},
+			wantReady:    metav1.ConditionTrue,
+			wantComplete: metav1.ConditionFalse,
+			wantFailed:   metav1.ConditionTrue,
+			wantDegraded: metav1.ConditionTrue,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := tt.status
+			setListJobConditions(&status, 4)
+
+			assert.Equal(t, int64(4), status.ObservedGeneration)
+			for conditionType, want := range map[string]metav1.ConditionStatus{
+				batchopsv1alpha1.ConditionReady:    tt.wantReady,
+				batchopsv1alpha1.ConditionComplete: tt.wantComplete,
+				batchopsv1alpha1.ConditionFailed:   tt.wantFailed,
+				batchopsv1alpha1.ConditionDegraded: tt.wantDegraded,
+			} {
+				condition := meta.FindStatusCondition(status.Conditions, conditionType)
+				require.NotNil(t, condition, conditionType)
+				assert.Equal(t, want, condition.Status, conditionType)
+				assert.Equal(t, int64(4), condition.ObservedGeneration, conditionType)
+			}
+		})
+	}
+}
+
+func TestSetListJobConditions_KeepsTransitionTime(t *testing.T) {
+	status := batchopsv1alpha1.ListJobStatus{JobName: "job", Phase: batchopsv1alpha1.ListJobRunning, Total: 2, Progress: "0/2"}
+	setListJobConditions(&status, 1)
+	ready := meta.FindStatusCondition(status.Conditions, batchopsv1alpha1.ConditionReady)
+	require.NotNil(t, ready)
+	transition := metav1.NewTime(ready.LastTransitionTime.Add(-time.Hour))
+	ready.LastTransitionTime = transition
+
+	status.Succeeded, status.Progress = 1, "1/2"
+	setListJobConditions(&status, 1)
+	assert.Equal(t, transition, meta.FindStatusCondition(status.Conditions, batchopsv1alpha1.ConditionReady).LastTransitionTime)
+}
+
+func TestListSourceController_Conditions(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, batchopsv1alpha1.AddToScheme(scheme))
+	require.NoError(t, corev1.AddToScheme(scheme))
+
+	listSource := &batchopsv1alpha1.ListSource{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "source",
+			Namespace:  "default",
+			Generation: 2,
+			Finalizers: []string{listSourceFinalizer},
+		},
+		Spec: batchopsv1alpha1.ListSourceSpec{
+			Type:       batchopsv1alpha1.StaticList,
+			StaticList: []string{"a", "b"},
+		},
+	}
+	r := &ListSourceReconciler{
+		Client: fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(listSource).
+			WithStatusSubresource(&batchopsv1alpha1.ListSource{}).
+			Build(),
+		Scheme:   scheme,
+		Recorder: record.NewFakeRecorder(10),
+	}
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(listSource)}
+
+	_, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	require.NoError(t, r.Get(ctx, req.NamespacedName, listSource))
+	assert.Equal(t, int64(2), listSource.Status.ObservedGeneration)
+	assert.True(t, meta.IsStatusConditionTrue(listSource.Status.Conditions, batchopsv1alpha1.ConditionReady))
+	assert.True(t, meta.IsStatusConditionFalse(listSource.Status.Conditions, batchopsv1alpha1.ConditionFetching))
+	assert.True(t, meta.IsStatusConditionFalse(listSource.Status.Conditions, batchopsv1alpha1.ConditionDegraded))
+
+	// A failed fetch keeps serving the previous list
+	listSource.Spec.Type = "unknown"
+	require.NoError(t, r.Update(ctx, listSource))
+	result, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.Positive(t, result.RequeueAfter)
+	require.NoError(t, r.Get(ctx, req.NamespacedName, listSource))
+	assert.True(t, meta.IsStatusConditionTrue(listSource.Status.Conditions, batchopsv1alpha1.ConditionReady))
+	assert.True(t, meta.IsStatusConditionFalse(listSource.Status.Conditions, batchopsv1alpha1.ConditionFetching))
+	degraded := meta.FindStatusCondition(listSource.Status.Conditions, batchopsv1alpha1.ConditionDegraded)
+	require.NotNil(t, degraded)
+	assert.Equal(t, metav1.ConditionTrue, degraded.Status)
+	assert.Equal(t, "FetchFailed", degraded.Reason)
+}
@@ -0,0 +1,423 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	batchopsv1alpha1 "github.com/matanryngler/parallax/api/v1alpha1"
+)
+
+const (
+	// itemsKey is the ConfigMap key holding the newline-separated items.
+	itemsKey = "items"
+	// fieldKeyPrefix prefixes the ConfigMap keys holding one value per item for
+	// every configured ItemField, e.g. "field.ITEM_ID".
+	fieldKeyPrefix = "field."
+	// defaultEnvName is used when the template does not set EnvName.
+	defaultEnvName = batchopsv1alpha1.DefaultEnvName
+)
+
+var nonEnvChars = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// itemEnvName returns the environment variable that receives the full item.
+func itemEnvName(template batchopsv1alpha1.JobTemplateSpec) string {
+	if template.EnvName == "" {
+		return defaultEnvName
+	}
+	return template.EnvName
+}
+
+// fieldEnvName returns the environment variable that receives a single field
+// of a structured item, e.g. ITEM_ID for the "id" key.
+func fieldEnvName(template batchopsv1alpha1.JobTemplateSpec, field batchopsv1alpha1.ItemField) string {
+	if field.EnvName != "" {
+		return field.EnvName
+	}
+	return itemEnvName(template) + "_" + strings.ToUpper(nonEnvChars.ReplaceAllString(field.Name, "_"))
+}
+
+// getListSourceItems reads the items published by a ListSource into its ConfigMap.
+func getListSourceItems(ctx context.Context, c client.Reader, namespace, name string) ([]string, error) {
+	if err := checkListSourceFresh(ctx, c, namespace, name); err != nil {
+		return nil, err
+	}
+	list, err := readItems(ctx, c, namespace, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ListSource ConfigMap %s: %w", name, err)
+	}
+	if len(list) == 0 {
+		return nil, fmt.Errorf("ListSource ConfigMap has no items")
+	}
+
+	// Trim whitespace around every item
+	for i, item := range list {
+		list[i] = strings.TrimSpace(item)
+	}
+	return list, nil
+}
+
+// buildItemsData renders the data of the ConfigMap mounted into the worker pods:
+// the items themselves plus one line-aligned key per configured field.
+func buildItemsData(list []string, template batchopsv1alpha1.JobTemplateSpec) map[string]string {
+	data := map[string]string{
+		itemsKey: strings.Join(list, "\n"),
+	}
+	for _, field := range template.Fields {
+		values := make([]string, len(list))
+		for i, item := range list {
+			values[i] = extractItemField(item, field.Name)
+		}
+		data[fieldKeyPrefix+fieldEnvName(template, field)] = strings.Join(values, "\n")
+	}
+	return data
+}
+
+// jobItemCost returns the number of bytes an item takes in the data rendered by
+// buildItemsData, counting the item and every configured field.
+func jobItemCost(template batchopsv1alpha1.JobTemplateSpec) func(string) int {
+	return func(item string) int {
+		cost := itemCost(item)
+		for _, field := range template.Fields {
+			cost += len(extractItemField(item, field.Name)) + 1
+		}
+		return cost
+	}
+}
+
+// extractItemField returns the value stored under key in a JSON object item.
+// Non-object items and missing keys yield an empty string, scalars are rendered
+// verbatim and nested values as compact JSON. Values spanning several lines are
+// JSON-encoded so that every item keeps exactly one line.
+func extractItemField(item, key string) string {
+	decoder := json.NewDecoder(strings.NewReader(item))
+	decoder.UseNumber()
+	var obj map[string]interface{}
+	if err := decoder.Decode(&obj); err != nil {
+		return ""
+	}
+
+	var value string
+	switch v := obj[key].(type) {
+	case nil:
+		return ""
+	case string:
+		value = v
+	case json.Number:
+		value = v.String()
+	case bool:
+		value = fmt.Sprintf("%t", v)
+	default:
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return ""
+		}
+		value = string(raw)
+	}
+
+	if strings.ContainsAny(value, "\r\n") {
+		raw, _ := json.Marshal(value)
+		value = string(raw)
+	}
+	return value
+}
+
+// Names of the volumes and the init container injected into every item pod.
+const (
+	listVolumeName    = "list"
+	sharedVolumeName  = "shared"
+	initContainerName = "init"
+	mainContainerName = "main"
+)
+
+// buildItemPodTemplate builds the pod template shared by ListJob and ListCronJob:
+// an init container resolves the item for JOB_COMPLETION_INDEX from the list
+// ConfigMap and hands it to the item container according to the template's
+// Delivery. When the template embeds a PodTemplate, the plumbing is merged into
+// it and Image, Command and Resources act as overrides of the item container.
+// With a batchSize above 1 every pod receives that many contiguous items,
+// newline-separated, in place of a single item.
+func buildItemPodTemplate(template batchopsv1alpha1.JobTemplateSpec, listConfigMap string, optionalList bool, batchSize int32) (corev1.PodTemplateSpec, error) {
+	podTemplate := corev1.PodTemplateSpec{}
+	if template.PodTemplate != nil {
+		podTemplate = *template.PodTemplate.DeepCopy()
+	}
+	podSpec := &podTemplate.Spec
+
+	for _, volume := range podSpec.Volumes {
+		if volume.Name == listVolumeName || volume.Name == sharedVolumeName {
+			return podTemplate, fmt.Errorf("volume name %q is reserved by the operator", volume.Name)
+		}
+	}
+	for _, container := range podSpec.InitContainers {
+		if container.Name == initContainerName {
+			return podTemplate, fmt.Errorf("init container name %q is reserved by the operator", container.Name)
+		}
+	}
+
+	mainContainer, err := itemContainer(podSpec, template.Container)
+	if err != nil {
+		return podTemplate, err
+	}
+	if template.Image != "" {
+		mainContainer.Image = template.Image
+	}
+	if mainContainer.Image == "" {
+		return podTemplate, fmt.Errorf("container %q has no image", mainContainer.Name)
+	}
+	if len(template.Command) > 0 {
+		mainContainer.Command = template.Command
+		mainContainer.Args = nil
+	}
+	if len(template.Resources.Limits) > 0 || len(template.Resources.Requests) > 0 || len(template.Resources.Claims) > 0 {
+		mainContainer.Resources = template.Resources
+	}
+
+	listVolume := corev1.ConfigMapVolumeSource{
+		LocalObjectReference: corev1.LocalObjectReference{Name: listConfigMap},
+	}
+	if optionalList {
+		listVolume.Optional = func() *bool { b := true; return &b }()
+	}
+	podSpec.Volumes = append(podSpec.Volumes,
+		corev1.Volume{
+			Name:         listVolumeName,
+			VolumeSource: corev1.VolumeSource{ConfigMap: &listVolume},
+		},
+		corev1.Volume{
+			Name:         sharedVolumeName,
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		},
+	)
+
+	initContainer := corev1.Container{
+		Name:  initContainerName,
+		Image: "busybox",
+		Env: []corev1.EnvVar{
+			{
+				Name: "JOB_COMPLETION_INDEX",
+				ValueFrom: &corev1.EnvVarSource{
+					FieldRef: &corev1.ObjectFieldSelector{
+						FieldPath: "metadata.annotations['batch.kubernetes.io/job-completion-index']",
+					},
+				},
+			},
+		},
+		VolumeMounts: []corev1.VolumeMount{
+			{Name: listVolumeName, MountPath: "/list", ReadOnly: true},
+			{Name: sharedVolumeName, MountPath: "/shared"},
+		},
+	}
+	if batchSize > 1 {
+		initContainer.Env = append(initContainer.Env, corev1.EnvVar{Name: "BATCH_SIZE", Value: strconv.Itoa(int(batchSize))})
+	}
+
+	switch template.Delivery {
+	case batchopsv1alpha1.EnvDelivery, batchopsv1alpha1.FileDelivery:
+		initContainer.Command = []string{"sh", "-c", resolveItemFilesScript}
+		initContainer.Env = append(initContainer.Env, corev1.EnvVar{Name: "ITEM_ENV_NAME", Value: itemEnvName(template)})
+		mainContainer.VolumeMounts = append(mainContainer.VolumeMounts, corev1.VolumeMount{Name: sharedVolumeName, MountPath: itemMountPath, ReadOnly: true})
+		mainContainer.Env = append(mainContainer.Env, itemFileEnv(template)...)
+		if template.Delivery == batchopsv1alpha1.EnvDelivery {
+			if len(mainContainer.Command) == 0 {
+				return podTemplate, fmt.Errorf("env delivery requires a command for container %q", mainContainer.Name)
+			}
+			argv := append(append([]string{}, mainContainer.Command...), mainContainer.Args...)
+			mainContainer.Command = append([]string{"sh", "-c", execWithItemEnvScript, "parallax-exec"}, argv...)
+			mainContainer.Args = nil
+		}
+	default:
+		if len(mainContainer.Command) == 0 && len(mainContainer.Args) == 0 {
+			return podTemplate, fmt.Errorf("shell delivery requires a command for container %q", mainContainer.Name)
+		}
+		initContainer.Command = []string{"sh", "-c", legacyEnvScript(template, batchSize)}
+		argv := append(append([]string{}, mainContainer.Command...), mainContainer.Args...)
+		// The shell expands the item in the command, which is why this mode is
+		// only meant for trusted lists
+		mainContainer.Command = []string{"sh", "-c", ". /shared/env.sh && " + strings.Join(argv, " ")}
+		mainContainer.Args = nil
+		mainContainer.VolumeMounts = append(mainContainer.VolumeMounts, corev1.VolumeMount{Name: sharedVolumeName, MountPath: "/shared"})
+	}
+
+	podSpec.InitContainers = append([]corev1.Container{initContainer}, podSpec.InitContainers...)
+	if podSpec.RestartPolicy == "" {
+		podSpec.RestartPolicy = corev1.RestartPolicyNever
+	}
+
+	return podTemplate, nil
+}
+
+// itemContainer returns the container of podSpec that processes the item,
+// adding an empty "main" container when the pod has none.
+func itemContainer(podSpec *corev1.PodSpec, name string) (*corev1.Container, error) {
+	if len(podSpec.Containers) == 0 {
+		if name == "" {
+			name = mainContainerName
+		}
+		podSpec.Containers = []corev1.Container{{Name: name}}
+		return &podSpec.Containers[0], nil
+	}
+
+	if name == "" {
+		for i := range podSpec.Containers {
+			if podSpec.Containers[i].Name == mainContainerName {
+				return &podSpec.Containers[i], nil
+			}
+		}
+		return &podSpec.Containers[0], nil
+	}
+
+	for i := range podSpec.Containers {
+		if podSpec.Containers[i].Name == name {
+			return &podSpec.Containers[i], nil
+		}
+	}
+	return nil, fmt.Errorf("container %q not found in pod template", name)
+}
+
+// itemContainerName returns the name of the container that processes the item.
+func itemContainerName(template batchopsv1alpha1.JobTemplateSpec) string {
+	var podSpec corev1.PodSpec
+	if template.PodTemplate != nil {
+		podSpec = template.PodTemplate.Spec
+	}
+	container, err := itemContainer(&podSpec, template.Container)
+	if err != nil {
+		return template.Container
+	}
+	return container.Name
+}
+
+// batchCompletions returns the number of completion indexes needed to run
+// itemCount items in batches of batchSize.
+func batchCompletions(itemCount int, batchSize int32) int32 {
+	if batchSize <= 1 {
+		return int32(itemCount)
+	}
+	return int32((itemCount + int(batchSize) - 1) / int(batchSize))
+}
+
+// applyJobFailurePolicy copies the failure handling settings onto the Job spec.
+// The spec must already carry its completions and pod template.
+func applyJobFailurePolicy(jobSpec *batchv1.JobSpec, policy batchopsv1alpha1.JobFailurePolicy) error {
+	policy = *policy.DeepCopy()
+	restartNever := jobSpec.Template.Spec.RestartPolicy == corev1.RestartPolicyNever
+	if policy.BackoffLimitPerIndex != nil && !restartNever {
+		return fmt.Errorf("backoffLimitPerIndex requires the pod restartPolicy to be Never")
+	}
+	if policy.PodFailurePolicy != nil && !restartNever {
+		return fmt.Errorf("podFailurePolicy requires the pod restartPolicy to be Never")
+	}
+
+	// Jobs reject maxFailedIndexes above completions, which only means that
+	// the run never fails early.
+	if policy.MaxFailedIndexes != nil && jobSpec.Completions != nil && *policy.MaxFailedIndexes > *jobSpec.Completions {
+		policy.MaxFailedIndexes = jobSpec.Completions
+	}
+
+	jobSpec.BackoffLimit = policy.BackoffLimit
+	jobSpec.BackoffLimitPerIndex = policy.BackoffLimitPerIndex
+	jobSpec.MaxFailedIndexes = policy.MaxFailedIndexes
+	jobSpec.ActiveDeadlineSeconds = policy.ActiveDeadlineSeconds
+	jobSpec.PodFailurePolicy = policy.PodFailurePolicy
+	return nil
+}
+
+// itemMountPath is where the env and file delivery modes expose the item files
+// to the main container.
+const itemMountPath = "/parallax"
+
+// resolveItemFilesScript copies the lines for JOB_COMPLETION_INDEX out of every
+// list key into its own file, one line unless BATCH_SIZE is set. Items only
+// ever flow through files and quoted expansions, so no part of an item is
+// evaluated by the shell. The resulting layout, as seen by the main container
+// under /parallax, is:
+//
+//	item            the full item, or the newline-separated batch of items
+//	fields/<NAME>   one file per configured field
+//	env/<NAME>      the variables exported by the env delivery mode
+const resolveItemFilesScript = `set -e
+B=${BATCH_SIZE:-1}
+FIRST=$((JOB_COMPLETION_INDEX*B+1))
+LAST=$((FIRST+B-1))
+mkdir -p /shared/fields /shared/env
+printf '%s' "$(sed -n "${FIRST},${LAST}p;${LAST}q" /list/items)" > /shared/item
+cp /shared/item "/shared/env/${ITEM_ENV_NAME}"
+for f in /list/field.*; do
+  [ -e "$f" ] || continue
+  name="${f#/list/field.}"
+  printf '%s' "$(sed -n "${FIRST},${LAST}p;${LAST}q" "$f")" > "/shared/fields/${name}"
+  cp "/shared/fields/${name}" "/shared/env/${name}"
+done
+`
+
+// execWithItemEnvScript exports every file in /parallax/env as a variable and
+// execs the user command, which is passed as positional arguments.
+const execWithItemEnvScript = `for f in /parallax/env/*; do
+  [ -e "$f" ] || continue
+  export "${f##*/}=$(cat "$f")"
+done
+exec "$@"
+`
+
+// itemFileEnv points <NAME>_FILE variables at the item files so workloads can
+// locate them without hardcoding paths.
+func itemFileEnv(template batchopsv1alpha1.JobTemplateSpec) []corev1.EnvVar {
+	env := []corev1.EnvVar{{Name: itemEnvName(template) + "_FILE", Value: itemMountPath + "/item"}}
+	for _, field := range template.Fields {
+		name := fieldEnvName(template, field)
+		env = append(env, corev1.EnvVar{Name: name + "_FILE", Value: itemMountPath + "/fields/" + name})
+	}
+	return env
+}
+
+// legacyEnvScript renders the init script of the shell delivery mode, which
+// writes "export NAME=value" lines to /shared/env.sh for the main container to source.
+// Batches are exported double-quoted so the newline-separated items survive.
+func legacyEnvScript(template batchopsv1alpha1.JobTemplateSpec, batchSize int32) string {
+	lines := `"$((JOB_COMPLETION_INDEX+1))p"`
+	quote := ""
+	if batchSize > 1 {
+		lines = fmt.Sprintf(`"$((JOB_COMPLETION_INDEX*%[1]d+1)),$((JOB_COMPLETION_INDEX*%[1]d+%[1]d))p"`, batchSize)
+		quote = `\"`
+	}
+	script := fmt.Sprintf(`
+					# Read the items file
+					ITEMS=$(cat /list/items)
+					# Get the item at the given index (0-based)
+					VAL=$(echo "$ITEMS" | sed -n %s)
+					# Export the value
+					echo "export %s=%s$VAL%s" > /shared/env.sh
+				`, lines, itemEnvName(template), quote, quote)
+	for _, field := range template.Fields {
+		fieldEnv := fieldEnvName(template, field)
+		script += fmt.Sprintf(`# Export the %q field
+					VAL=$(sed -n %s /list/%s%s)
+					echo "export %s=%s$VAL%s" >> /shared/env.sh
+				`, field.Name, lines, fieldKeyPrefix, fieldEnv, fieldEnv, quote, quote)
+	}
+	return script
+}
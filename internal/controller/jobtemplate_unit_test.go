@@ -0,0 +1,352 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	batchopsv1alpha1 "github.com/matanryngler/parallax/api/v1alpha1"
+)
+
+func TestFieldEnvName(t *testing.T) {
+	template := batchopsv1alpha1.JobTemplateSpec{EnvName: "USER"}
+
+	assert.Equal(t, "USER_ID", fieldEnvName(template, batchopsv1alpha1.ItemField{Name: "id"}))
+	assert.Equal(t, "USER_FIRST_NAME", fieldEnvName(template, batchopsv1alpha1.ItemField{Name: "first-name"}))
+	assert.Equal(t, "OWNER", fieldEnvName(template, batchopsv1alpha1.ItemField{Name: "owner", EnvName: "OWNER"}))
+	assert.Equal(t, "ITEM_ID", fieldEnvName(batchopsv1alpha1.JobTemplateSpec{}, batchopsv1alpha1.ItemField{Name: "id"}))
+}
+
+func TestExtractItemField(t *testing.T) {
+	item := `{"id":12345678901234567890,"name":"alice","admin":true,"tags":["a","b"],"bio":"line1\nline2","none":null}`
+
+	assert.Equal(t, "12345678901234567890", extractItemField(item, "id"))
+	assert.Equal(t, "alice", extractItemField(item, "name"))
+	assert.Equal(t, "true", extractItemField(item, "admin"))
+	assert.Equal(t, `["a","b"]`, extractItemField(item, "tags"))
+	assert.Equal(t, `"line1\nline2"`, extractItemField(item, "bio"))
+	assert.Equal(t, "", extractItemField(item, "none"))
+	assert.Equal(t, "", extractItemField(item, "missing"))
+	assert.Equal(t, "", extractItemField("plain-text-item", "id"))
+}
+
+func TestBuildItemsData(t *testing.T) {
+	template := batchopsv1alpha1.JobTemplateSpec{
+		Image:   "busybox",
+		Command: []string{"worker"},
+		Fields:  []batchopsv1alpha1.ItemField{{Name: "id"}, {Name: "name", EnvName: "USERNAME"}},
+	}
+	list := []string{`{"id":1,"name":"alice"}`, `{"id":2}`}
+
+	data := buildItemsData(list, template)
+	assert.Equal(t, map[string]string{
+		"items":          `{"id":1,"name":"alice"}` + "\n" + `{"id":2}`,
+		"field.ITEM_ID":  "1\n2",
+		"field.USERNAME": "alice\n",
+	}, data)
+
+	podTemplate, err := buildItemPodTemplate(template, "test-list", false, 1)
+	require.NoError(t, err)
+	podSpec := podTemplate.Spec
+	script := podSpec.InitContainers[0].Command[2]
+	assert.Contains(t, script, "/list/field.ITEM_ID")
+	assert.Contains(t, script, "export ITEM_ID=$VAL")
+	assert.Contains(t, script, "export USERNAME=$VAL")
+	assert.Nil(t, podSpec.Volumes[0].ConfigMap.Optional)
+}
+
+func TestBuildItemPodSpec_Delivery(t *testing.T) {
+	template := batchopsv1alpha1.JobTemplateSpec{
+		Image:   "worker",
+		Command: []string{"process", "--item", "$ITEM"},
+		Fields:  []batchopsv1alpha1.ItemField{{Name: "id"}},
+	}
+
+	t.Run("Shell Delivery", func(t *testing.T) {
+		podTemplate, err := buildItemPodTemplate(template, "test-list", false, 1)
+		require.NoError(t, err)
+		podSpec := podTemplate.Spec
+		assert.Equal(t, []string{"sh", "-c", ". /shared/env.sh && process --item $ITEM"}, podSpec.Containers[0].Command)
+		assert.Empty(t, podSpec.Containers[0].Args)
+	})
+
+	t.Run("Shell Delivery Without Command", func(t *testing.T) {
+		template := template
+		template.Command = nil
+		_, err := buildItemPodTemplate(template, "test-list", false, 1)
+		assert.ErrorContains(t, err, `shell delivery requires a command for container "main"`)
+	})
+
+	t.Run("Env Delivery", func(t *testing.T) {
+		template := template
+		template.Delivery = batchopsv1alpha1.EnvDelivery
+		podTemplate, err := buildItemPodTemplate(template, "test-list", false, 1)
+		require.NoError(t, err)
+		podSpec := podTemplate.Spec
+
+		container := podSpec.Containers[0]
+		assert.Equal(t, []string{"sh", "-c", execWithItemEnvScript, "parallax-exec", "process", "--item", "$ITEM"}, container.Command)
+		assert.Equal(t, "/parallax", container.VolumeMounts[0].MountPath)
+		assert.True(t, container.VolumeMounts[0].ReadOnly)
+		assert.Contains(t, container.Env, corev1.EnvVar{Name: "ITEM_FILE", Value: "/parallax/item"})
+		assert.Contains(t, container.Env, corev1.EnvVar{Name: "ITEM_ID_FILE", Value: "/parallax/fields/ITEM_ID"})
+		assert.Equal(t, resolveItemFilesScript, podSpec.InitContainers[0].Command[2])
+	})
+
+	t.Run("File Delivery", func(t *testing.T) {
+		template := template
+		template.Delivery = batchopsv1alpha1.FileDelivery
+		podTemplate, err := buildItemPodTemplate(template, "test-list", true, 1)
+		require.NoError(t, err)
+		podSpec := podTemplate.Spec
+
+		assert.Equal(t, []string{"process", "--item", "$ITEM"}, podSpec.Containers[0].Command)
+		assert.True(t, *podSpec.Volumes[0].ConfigMap.Optional)
+	})
+}
+
+func TestBuildItemPodTemplate_PodTemplate(t *testing.T) {
+	base := &corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"team": "data"}},
+		Spec: corev1.PodSpec{
+			ServiceAccountName: "worker",
+			NodeSelector:       map[string]string{"pool": "batch"},
+			Volumes:            []corev1.Volume{{Name: "creds"}},
+			InitContainers:     []corev1.Container{{Name: "warmup", Image: "busybox"}},
+			Containers: []corev1.Container{
+				{Name: "proxy", Image: "envoy"},
+				{
+					Name:    "worker",
+					Image:   "worker:v1",
+					Command: []string{"./run"},
+					Args:    []string{"--fast"},
+					Env:     []corev1.EnvVar{{Name: "MODE", Value: "prod"}},
+				},
+			},
+		},
+	}
+
+	t.Run("Injects Into Named Container", func(t *testing.T) {
+		template := batchopsv1alpha1.JobTemplateSpec{
+			PodTemplate: base,
+			Container:   "worker",
+			Delivery:    batchopsv1alpha1.EnvDelivery,
+		}
+		podTemplate, err := buildItemPodTemplate(template, "test-list", false, 1)
+		require.NoError(t, err)
+
+		assert.Equal(t, "data", podTemplate.Labels["team"])
+		assert.Equal(t, "worker", podTemplate.Spec.ServiceAccountName)
+		assert.Equal(t, map[string]string{"pool": "batch"}, podTemplate.Spec.NodeSelector)
+		assert.Equal(t, corev1.RestartPolicyNever, podTemplate.Spec.RestartPolicy)
+		assert.Equal(t, []string{"creds", "list", "shared"}, []string{
+			podTemplate.Spec.Volumes[0].Name, podTemplate.Spec.Volumes[1].Name, podTemplate.Spec.Volumes[2].Name,
+		})
+		assert.Equal(t, "init", podTemplate.Spec.InitContainers[0].Name)
+		assert.Equal(t, "warmup", podTemplate.Spec.InitContainers[1].Name)
+
+		proxy := podTemplate.Spec.Containers[0]
+		assert.Empty(t, proxy.VolumeMounts)
+		worker := podTemplate.Spec.Containers[1]
+		assert.Equal(t, "worker:v1", worker.Image)
+		assert.Equal(t, []string{"sh", "-c", execWithItemEnvScript, "parallax-exec", "./run", "--fast"}, worker.Command)
+		assert.Nil(t, worker.Args)
+		assert.Contains(t, worker.Env, corev1.EnvVar{Name: "MODE", Value: "prod"})
+		assert.Contains(t, worker.Env, corev1.EnvVar{Name: "ITEM_FILE", Value: "/parallax/item"})
+
+		// The embedded template must not be mutated
+		assert.Len(t, base.Spec.Volumes, 1)
+		assert.Equal(t, []string{"./run"}, base.Spec.Containers[1].Command)
+	})
+
+	t.Run("Overrides Image And Command", func(t *testing.T) {
+		template := batchopsv1alpha1.JobTemplateSpec{
+			PodTemplate: base,
+			Container:   "worker",
+			Image:       "worker:v2",
+			Command:     []string{"echo", "$ITEM"},
+		}
+		podTemplate, err := buildItemPodTemplate(template, "test-list", false, 1)
+		require.NoError(t, err)
+
+		worker := podTemplate.Spec.Containers[1]
+		assert.Equal(t, "worker:v2", worker.Image)
+		assert.Equal(t, []string{"sh", "-c", ". /shared/env.sh && echo $ITEM"}, worker.Command)
+	})
+
+	t.Run("Unknown Container", func(t *testing.T) {
+		template := batchopsv1alpha1.JobTemplateSpec{PodTemplate: base, Container: "missing"}
+		_, err := buildItemPodTemplate(template, "test-list", false, 1)
+		assert.ErrorContains(t, err, `container "missing" not found`)
+	})
+
+	t.Run("Reserved Volume Name", func(t *testing.T) {
+		podTemplate := base.DeepCopy()
+		podTemplate.Spec.Volumes = append(podTemplate.Spec.Volumes, corev1.Volume{Name: "shared"})
+		template := batchopsv1alpha1.JobTemplateSpec{PodTemplate: podTemplate}
+		_, err := buildItemPodTemplate(template, "test-list", false, 1)
+		assert.ErrorContains(t, err, "reserved")
+	})
+
+	t.Run("Missing Image", func(t *testing.T) {
+		_, err := buildItemPodTemplate(batchopsv1alpha1.JobTemplateSpec{Command: []string{"true"}}, "test-list", false, 1)
+		assert.ErrorContains(t, err, "has no image")
+	})
+}
+
+// TestItemDeliveryScripts runs the generated scripts against hostile items to
+// make sure nothing in an item is ever evaluated by the shell.
+func TestItemDeliveryScripts(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+
+	dir := t.TempDir()
+	hostile := `x'; touch PWNED; echo "$(touch PWNED)" ` + "`touch PWNED`" + ` $HOME \\ *`
+	items := []string{"first", `{"id":"$(touch PWNED)","name":"a b"}`, hostile}
+	template := batchopsv1alpha1.JobTemplateSpec{Fields: []batchopsv1alpha1.ItemField{{Name: "id"}}}
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "list"), 0o755))
+	for key, value := range buildItemsData(items, template) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "list", key), []byte(value), 0o644))
+	}
+	localize := func(script string) string {
+		return strings.NewReplacer("/list/", dir+"/list/", "/shared/", dir+"/shared/", "/parallax/", dir+"/shared/").Replace(script)
+	}
+
+	run := func(index, batchSize string, args ...string) string {
+		cmd := exec.Command("sh", "-c", localize(resolveItemFilesScript))
+		cmd.Dir = dir
+		cmd.Env = []string{"JOB_COMPLETION_INDEX=" + index, "ITEM_ENV_NAME=ITEM", "BATCH_SIZE=" + batchSize, "PATH=" + os.Getenv("PATH")}
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+
+		cmd = exec.Command("sh", append([]string{"-c", localize(execWithItemEnvScript), "parallax-exec"}, args...)...)
+		cmd.Dir = dir
+		cmd.Env = []string{"PATH=" + os.Getenv("PATH")}
+		out, err = cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+		return string(out)
+	}
+
+	assert.Equal(t, hostile+"\n", run("2", "", "printenv", "ITEM"))
+	content, err := os.ReadFile(filepath.Join(dir, "shared", "item"))
+	require.NoError(t, err)
+	assert.Equal(t, hostile, string(content))
+
+	assert.Equal(t, "$(touch PWNED)\n", run("1", "", "printenv", "ITEM_ID"))
+	assert.Equal(t, "first\n", run("0", "", "printenv", "ITEM"))
+
+	// Batches hold contiguous newline-separated items, the last one may be short
+	assert.Equal(t, "first\n"+items[1]+"\n", run("0", "2", "printenv", "ITEM"))
+	assert.Equal(t, "\n$(touch PWNED)\n", run("0", "2", "printenv", "ITEM_ID"))
+	assert.Equal(t, hostile+"\n", run("1", "2", "printenv", "ITEM"))
+
+	_, err = os.Stat(filepath.Join(dir, "PWNED"))
+	assert.True(t, os.IsNotExist(err), "item content was executed by the shell")
+}
+
+func TestApplyJobFailurePolicy(t *testing.T) {
+	newJobSpec := func(restartPolicy corev1.RestartPolicy) batchv1.JobSpec {
+		return batchv1.JobSpec{
+			Completions: &[]int32{5}[0],
+			Template:    corev1.PodTemplateSpec{Spec: corev1.PodSpec{RestartPolicy: restartPolicy}},
+		}
+	}
+	policy := batchopsv1alpha1.JobFailurePolicy{
+		BackoffLimit:          &[]int32{10}[0],
+		BackoffLimitPerIndex:  &[]int32{2}[0],
+		MaxFailedIndexes:      &[]int32{3}[0],
+		ActiveDeadlineSeconds: &[]int64{3600}[0],
+		PodFailurePolicy: &batchv1.PodFailurePolicy{Rules: []batchv1.PodFailurePolicyRule{{
+			Action: batchv1.PodFailurePolicyActionFailIndex,
+			OnExitCodes: &batchv1.PodFailurePolicyOnExitCodesRequirement{
+				Operator: batchv1.PodFailurePolicyOnExitCodesOpIn,
+				Values:   []int32{42},
+			},
+		}}},
+	}
+
+	t.Run("Copies Settings", func(t *testing.T) {
+		jobSpec := newJobSpec(corev1.RestartPolicyNever)
+		require.NoError(t, applyJobFailurePolicy(&jobSpec, policy))
+		assert.Equal(t, int32(10), *jobSpec.BackoffLimit)
+		assert.Equal(t, int32(2), *jobSpec.BackoffLimitPerIndex)
+		assert.Equal(t, int32(3), *jobSpec.MaxFailedIndexes)
+		assert.Equal(t, int64(3600), *jobSpec.ActiveDeadlineSeconds)
+		assert.Equal(t, policy.PodFailurePolicy, jobSpec.PodFailurePolicy)
+		assert.NotSame(t, policy.PodFailurePolicy, jobSpec.PodFailurePolicy)
+	})
+
+	t.Run("Clamps MaxFailedIndexes To Completions", func(t *testing.T) {
+		jobSpec := newJobSpec(corev1.RestartPolicyNever)
+		large := policy
+		large.MaxFailedIndexes = &[]int32{100}[0]
+		require.NoError(t, applyJobFailurePolicy(&jobSpec, large))
+		assert.Equal(t, int32(5), *jobSpec.MaxFailedIndexes)
+	})
+
+	t.Run("Requires RestartPolicy Never", func(t *testing.T) {
+		jobSpec := newJobSpec(corev1.RestartPolicyOnFailure)
+		assert.ErrorContains(t, applyJobFailurePolicy(&jobSpec, policy), "restartPolicy to be Never")
+
+		jobSpec = newJobSpec(corev1.RestartPolicyOnFailure)
+		require.NoError(t, applyJobFailurePolicy(&jobSpec, batchopsv1alpha1.JobFailurePolicy{BackoffLimit: &[]int32{1}[0]}))
+	})
+}
+
+func TestBatchCompletions(t *testing.T) {
+	assert.Equal(t, int32(5), batchCompletions(5, 0))
+	assert.Equal(t, int32(5), batchCompletions(5, 1))
+	assert.Equal(t, int32(3), batchCompletions(5, 2))
+	assert.Equal(t, int32(1), batchCompletions(5, 10))
+	assert.Equal(t, int32(0), batchCompletions(0, 10))
+}
+
+func TestLegacyEnvScript_Batch(t *testing.T) {
+	template := batchopsv1alpha1.JobTemplateSpec{EnvName: "ITEM"}
+	assert.Contains(t, legacyEnvScript(template, 1), `sed -n "$((JOB_COMPLETION_INDEX+1))p"`)
+	assert.Contains(t, legacyEnvScript(template, 1), `echo "export ITEM=$VAL"`)
+
+	script := legacyEnvScript(template, 3)
+	assert.Contains(t, script, `sed -n "$((JOB_COMPLETION_INDEX*3+1)),$((JOB_COMPLETION_INDEX*3+3))p"`)
+	assert.Contains(t, script, `echo "export ITEM=\"$VAL\""`)
+}
+
+func TestBuildItemPodTemplate_BatchSize(t *testing.T) {
+	template := batchopsv1alpha1.JobTemplateSpec{Image: "busybox", Command: []string{"./run"}, Delivery: batchopsv1alpha1.FileDelivery}
+
+	podTemplate, err := buildItemPodTemplate(template, "test-list", false, 1)
+	require.NoError(t, err)
+	for _, env := range podTemplate.Spec.InitContainers[0].Env {
+		assert.NotEqual(t, "BATCH_SIZE", env.Name)
+	}
+
+	podTemplate, err = buildItemPodTemplate(template, "test-list", false, 50)
+	require.NoError(t, err)
+	assert.Contains(t, podTemplate.Spec.InitContainers[0].Env, corev1.EnvVar{Name: "BATCH_SIZE", Value: "50"})
+}
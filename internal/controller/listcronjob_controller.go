@@ -19,45 +19,66 @@ package controller
 import (
 	"context"
 	"fmt"
-	"strings"
+	"sort"
+	"time"
 
-	batchopsv1alpha1 "github.com/matanryngler/parallax/api/v1alpha1"
+	"github.com/robfig/cron/v3"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/clock"
 	ctrl "sigs.k8s.io/controller-runtime"
-	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
-	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	batchopsv1alpha1 "github.com/matanryngler/parallax/api/v1alpha1"
 )
 
 // ListCronJobReconciler reconciles a ListCronJob object
 type ListCronJobReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	// Clock tells the time schedules are evaluated at. Defaults to the real
+	// clock.
+	Clock clock.PassiveClock
 }
 
-const listCronJobFinalizer = "listcronjob.batchops.io/finalizer"
+const (
+	listCronJobFinalizer = "listcronjob.batchops.io/finalizer"
+	// listCronJobLabel is set on the ListJobs of a ListCronJob to its name.
+	listCronJobLabel = "listcronjob"
+	// scheduledTimeAnnotation records the schedule time a ListJob was created for.
+	scheduledTimeAnnotation = "batchops.io/scheduled-time"
+	// listSourceRefreshTimeout bounds how long a run waits for its ListSource to
+	// fetch a fresh list before it runs with the current one.
+	listSourceRefreshTimeout = 2 * time.Minute
+	// listSourceRefreshPoll is how often a waiting run checks its ListSource.
+	listSourceRefreshPoll = 5 * time.Second
+)
 
 // +kubebuilder:rbac:groups=batchops.io,resources=listcronjobs,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=batchops.io,resources=listcronjobs/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=batchops.io,resources=listcronjobs/finalizers,verbs=update
-// +kubebuilder:rbac:groups=batch,resources=cronjobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=batchops.io,resources=listjobs,verbs=get;list;watch;create;update;patch;delete;deletecollection
+// +kubebuilder:rbac:groups=batchops.io,resources=listsources,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups=batch,resources=cronjobs,verbs=get;list;watch;delete
 // +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=configmaps/status,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
-// TODO(user): Modify the Reconcile function to compare the state specified by
-// the ListCronJob object against the actual cluster state, and then
-// perform operations to make the cluster state reflect the state specified by
-// the user.
+// A ListCronJob is scheduled by the operator itself: at every schedule time a
+// ListJob is created, which resolves the list when it starts, so every run
+// gets the current items and the matching completion count.
 //
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.20.2/pkg/reconcile
@@ -88,10 +109,17 @@ func (r *ListCronJobReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	if !listCronJob.DeletionTimestamp.IsZero() {
 		if controllerutil.ContainsFinalizer(&listCronJob, listCronJobFinalizer) {
 			log.Info("Cleaning up child resources before deletion")
-			cronJob := &batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{Name: listCronJob.Name, Namespace: listCronJob.Namespace}}
-			_ = r.Delete(ctx, cronJob)
-			cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("%s-list", listCronJob.Name), Namespace: listCronJob.Namespace}}
-			_ = r.Delete(ctx, cm)
+			if err := r.deleteLegacyResources(ctx, &listCronJob); err != nil {
+				log.Error(err, "Failed to delete CronJob resources")
+				return ctrl.Result{}, err
+			}
+			if err := r.DeleteAllOf(ctx, &batchopsv1alpha1.ListJob{},
+				client.InNamespace(listCronJob.Namespace),
+				client.MatchingLabels{listCronJobLabel: listCronJob.Name},
+			); err != nil {
+				log.Error(err, "Failed to delete ListJobs")
+				return ctrl.Result{}, err
+			}
 
 			controllerutil.RemoveFinalizer(&listCronJob, listCronJobFinalizer)
 			if err := r.Update(ctx, &listCronJob); err != nil {
@@ -111,245 +139,417 @@ func (r *ListCronJobReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return ctrl.Result{Requeue: true}, nil
 	}
 
-	var list []string
-	if len(listCronJob.Spec.StaticList) > 0 {
-		list = listCronJob.Spec.StaticList
-	} else if listCronJob.Spec.ListSourceRef != "" {
-		// Get the ListSource's ConfigMap
-		var listSourceCM corev1.ConfigMap
-		if err := r.Get(ctx, client.ObjectKey{Name: listCronJob.Spec.ListSourceRef, Namespace: req.Namespace}, &listSourceCM); err != nil {
-			log.Error(err, "Failed to get ListSource ConfigMap", "configMap", listCronJob.Spec.ListSourceRef)
-			return ctrl.Result{}, err
+	if len(listCronJob.Spec.StaticList) == 0 && listCronJob.Spec.ListSourceRef == "" {
+		log.Error(nil, "Neither StaticList nor ListSourceRef specified")
+		err := fmt.Errorf("either StaticList or ListSourceRef must be specified")
+		status := listCronJob.Status.DeepCopy()
+		status.ObservedGeneration = listCronJob.Generation
+		setCondition(&status.Conditions, batchopsv1alpha1.ConditionReady, false, "NoList", err.Error(), listCronJob.Generation)
+		if statusErr := r.updateStatus(ctx, &listCronJob, status); statusErr != nil {
+			return ctrl.Result{}, statusErr
 		}
+		return ctrl.Result{}, err
+	}
+
+	// Earlier versions ran ListCronJobs as a native CronJob over a fixed list
+	if err := r.deleteLegacyResources(ctx, &listCronJob); err != nil {
+		log.Error(err, "Failed to delete CronJob resources")
+		return ctrl.Result{}, err
+	}
+
+	schedule, err := cron.ParseStandard(listCronJob.Spec.Schedule)
+	if err != nil {
+		log.Error(err, "Invalid schedule", "schedule", listCronJob.Spec.Schedule)
+		r.Recorder.Event(&listCronJob, corev1.EventTypeWarning, "InvalidSchedule",
+			fmt.Sprintf("Unable to parse schedule %q: %v", listCronJob.Spec.Schedule, err))
+		status := listCronJob.Status.DeepCopy()
+		status.ObservedGeneration = listCronJob.Generation
+		status.NextScheduleTime = nil
+		setCondition(&status.Conditions, batchopsv1alpha1.ConditionReady, false, "InvalidSchedule",
+			fmt.Sprintf("Unable to parse schedule %q: %v", listCronJob.Spec.Schedule, err), listCronJob.Generation)
+		return ctrl.Result{}, r.updateStatus(ctx, &listCronJob, status)
+	}
 
-		// Parse the items from the ConfigMap
-		itemsStr := listSourceCM.Data["items"]
-		if itemsStr == "" {
-			log.Error(nil, "ListSource ConfigMap has no items", "configMap", listCronJob.Spec.ListSourceRef)
-			return ctrl.Result{}, fmt.Errorf("ListSource ConfigMap has no items")
+	var listJobs batchopsv1alpha1.ListJobList
+	if err := r.List(ctx, &listJobs,
+		client.InNamespace(listCronJob.Namespace),
+		client.MatchingLabels{listCronJobLabel: listCronJob.Name},
+	); err != nil {
+		log.Error(err, "Failed to list ListJobs")
+		return ctrl.Result{}, err
+	}
+	var active, successful, failed []*batchopsv1alpha1.ListJob
+	for i := range listJobs.Items {
+		listJob := &listJobs.Items[i]
+		if !metav1.IsControlledBy(listJob, &listCronJob) {
+			continue
 		}
+		switch listJob.Status.Phase {
+		case batchopsv1alpha1.ListJobSucceeded:
+			successful = append(successful, listJob)
+		case batchopsv1alpha1.ListJobFailed:
+			failed = append(failed, listJob)
+		default:
+			active = append(active, listJob)
+		}
+	}
+
+	if err := r.pruneHistory(ctx, successful, historyLimit(listCronJob.Spec.SuccessfulJobsHistoryLimit, batchopsv1alpha1.DefaultSuccessfulJobsHistoryLimit)); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.pruneHistory(ctx, failed, historyLimit(listCronJob.Spec.FailedJobsHistoryLimit, batchopsv1alpha1.DefaultFailedJobsHistoryLimit)); err != nil {
+		return ctrl.Result{}, err
+	}
 
-		// Split by newlines and trim whitespace
-		list = strings.Split(itemsStr, "\n")
-		for i, item := range list {
-			list[i] = strings.TrimSpace(item)
+	status := listCronJob.Status.DeepCopy()
+	status.ObservedGeneration = listCronJob.Generation
+	status.Active = listJobReferences(active)
+	for _, listJob := range successful {
+		if t := listJob.Status.CompletionTime; t != nil && (status.LastSuccessfulTime == nil || status.LastSuccessfulTime.Before(t)) {
+			status.LastSuccessfulTime = t
 		}
-	} else {
-		log.Error(nil, "Neither StaticList nor ListSourceRef specified")
-		return ctrl.Result{}, fmt.Errorf("either StaticList or ListSourceRef must be specified")
 	}
+	setListCronJobConditions(&listCronJob, status, successful, failed)
 
-	// Create ConfigMap with newline-separated items
-	jobCm := &corev1.ConfigMap{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      fmt.Sprintf("%s-list", listCronJob.Name),
-			Namespace: req.Namespace,
-		},
-		Data: map[string]string{
-			"items": strings.Join(list, "\n"),
-		},
+	now := r.now()
+	next := schedule.Next(now)
+	status.NextScheduleTime = &metav1.Time{Time: next}
+	result := ctrl.Result{RequeueAfter: next.Sub(now)}
+
+	if listCronJob.Spec.Suspend != nil && *listCronJob.Spec.Suspend {
+		log.V(1).Info("ListCronJob is suspended")
+		status.NextScheduleTime = nil
+		return ctrl.Result{}, r.updateStatus(ctx, &listCronJob, status)
+	}
+
+	scheduledTime, due := mostRecentScheduleTime(&listCronJob, schedule, now)
+	if scheduledTime == nil {
+		log.V(1).Info("No run due", "nextScheduleTime", next)
+		return result, r.updateStatus(ctx, &listCronJob, status)
+	}
+	if due > maxMissedSchedules {
+		r.Recorder.Eventf(&listCronJob, corev1.EventTypeWarning, "TooManyMissedTimes",
+			"too many missed start times: %d. Set or decrease .spec.startingDeadlineSeconds or check clock skew", due)
+		log.Info("Too many missed schedule times", "missed", due)
+	}
+	if due > 1 {
+		log.Info("Skipping missed schedule times, running the most recent one",
+			"missed", due-1, "scheduledTime", scheduledTime)
+	}
+
+	listJob := scheduledListJob(&listCronJob, *scheduledTime)
+	for i := range listJobs.Items {
+		if listJobs.Items[i].Name == listJob.Name {
+			log.V(1).Info("ListJob already exists", "listJob", listJob.Name)
+			status.LastScheduleTime = &metav1.Time{Time: *scheduledTime}
+			return result, r.updateStatus(ctx, &listCronJob, status)
+		}
+	}
+
+	switch listCronJob.Spec.ConcurrencyPolicy {
+	case batchv1.ForbidConcurrent:
+		if len(active) > 0 {
+			// The run is skipped rather than started late, as CronJob does
+			log.Info("Skipping run, ListJobs are still active", "active", len(active), "scheduledTime", scheduledTime)
+			r.Recorder.Eventf(&listCronJob, corev1.EventTypeNormal, "JobAlreadyActive",
+				"Skipped run scheduled at %s, ListJobs are still active", scheduledTime.UTC().Format(time.RFC3339))
+			status.LastScheduleTime = &metav1.Time{Time: *scheduledTime}
+			return result, r.updateStatus(ctx, &listCronJob, status)
+		}
+	case batchv1.ReplaceConcurrent:
+		for _, listJob := range active {
+			log.Info("Replacing active ListJob", "listJob", listJob.Name)
+			if err := r.Delete(ctx, listJob, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !apierrors.IsNotFound(err) {
+				log.Error(err, "Failed to delete active ListJob", "listJob", listJob.Name)
+				return ctrl.Result{}, err
+			}
+			r.Recorder.Event(&listCronJob, corev1.EventTypeNormal, "SuccessfulDelete",
+				fmt.Sprintf("Deleted ListJob %s to replace it", listJob.Name))
+		}
+		active = nil
+	}
+
+	if listCronJob.Spec.RefreshListSource && listCronJob.Spec.ListSourceRef != "" {
+		ready, err := r.refreshListSource(ctx, &listCronJob, *scheduledTime, now)
+		if err != nil {
+			log.Error(err, "Failed to refresh ListSource", "listSource", listCronJob.Spec.ListSourceRef)
+			return ctrl.Result{}, err
+		}
+		if !ready {
+			return ctrl.Result{RequeueAfter: listSourceRefreshPoll}, r.updateStatus(ctx, &listCronJob, status)
+		}
 	}
-	if err := ctrl.SetControllerReference(&listCronJob, jobCm, r.Scheme); err != nil {
+
+	if err := ctrl.SetControllerReference(&listCronJob, listJob, r.Scheme); err != nil {
 		return ctrl.Result{}, err
 	}
-	if err := r.Create(ctx, jobCm); err != nil {
+	if err := r.Create(ctx, listJob); err != nil {
 		if !apierrors.IsAlreadyExists(err) {
-			log.Error(err, "Failed to create ConfigMap")
+			log.Error(err, "Failed to create ListJob", "listJob", listJob.Name)
 			return ctrl.Result{}, err
 		}
-		// Update existing ConfigMap
-		existingCm := &corev1.ConfigMap{}
-		if err := r.Get(ctx, client.ObjectKey{Name: jobCm.Name, Namespace: jobCm.Namespace}, existingCm); err != nil {
-			log.Error(err, "Failed to get existing ConfigMap")
-			return ctrl.Result{}, err
+		log.V(1).Info("ListJob already exists", "listJob", listJob.Name)
+	} else {
+		log.Info("Created ListJob", "listJob", listJob.Name, "scheduledTime", scheduledTime)
+		r.Recorder.Event(&listCronJob, corev1.EventTypeNormal, "SuccessfulCreate",
+			fmt.Sprintf("Created ListJob %s", listJob.Name))
+	}
+
+	status.Active = listJobReferences(append(active, listJob))
+	status.LastScheduleTime = &metav1.Time{Time: *scheduledTime}
+	return result, r.updateStatus(ctx, &listCronJob, status)
+}
+
+// now returns the current time of the reconciler's clock.
+func (r *ListCronJobReconciler) now() time.Time {
+	if r.Clock == nil {
+		return time.Now()
+	}
+	return r.Clock.Now()
+}
+
+// updateStatus writes status when it differs from the stored one.
+func (r *ListCronJobReconciler) updateStatus(ctx context.Context, listCronJob *batchopsv1alpha1.ListCronJob, status *batchopsv1alpha1.ListCronJobStatus) error {
+	if equality.Semantic.DeepEqual(listCronJob.Status, *status) {
+		return nil
+	}
+	listCronJob.Status = *status
+	if err := r.Status().Update(ctx, listCronJob); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "Failed to update ListCronJob status")
+		return err
+	}
+	return nil
+}
+
+// setListCronJobConditions sets the conditions of a ListCronJob with a valid
+// schedule. It is degraded while its most recent finished run failed.
+func setListCronJobConditions(listCronJob *batchopsv1alpha1.ListCronJob, status *batchopsv1alpha1.ListCronJobStatus, successful, failed []*batchopsv1alpha1.ListJob) {
+	generation := listCronJob.Generation
+	setCondition(&status.Conditions, batchopsv1alpha1.ConditionReady, true, "Scheduled",
+		fmt.Sprintf("Runs on schedule %q", listCronJob.Spec.Schedule), generation)
+
+	if listCronJob.Spec.Suspend != nil && *listCronJob.Spec.Suspend {
+		setCondition(&status.Conditions, batchopsv1alpha1.ConditionSuspended, true, "Suspended", "No new runs are scheduled", generation)
+	} else {
+		setCondition(&status.Conditions, batchopsv1alpha1.ConditionSuspended, false, "NotSuspended", "", generation)
+	}
+
+	var lastSucceeded, lastFailed *batchopsv1alpha1.ListJob
+	for _, listJob := range successful {
+		if lastSucceeded == nil || listJob.Name > lastSucceeded.Name {
+			lastSucceeded = listJob
 		}
-		existingCm.Data = jobCm.Data
-		if err := r.Update(ctx, existingCm); err != nil {
-			log.Error(err, "Failed to update ConfigMap")
-			return ctrl.Result{}, err
+	}
+	for _, listJob := range failed {
+		if lastFailed == nil || listJob.Name > lastFailed.Name {
+			lastFailed = listJob
 		}
-		log.Info("Updated ConfigMap with new items",
-			"configmap", jobCm.Name,
-			"items", strings.Join(list, ","),
-			"itemCount", len(list),
-			"resourceVersion", existingCm.ResourceVersion)
+	}
+	if lastFailed != nil && (lastSucceeded == nil || lastFailed.Name > lastSucceeded.Name) {
+		setCondition(&status.Conditions, batchopsv1alpha1.ConditionDegraded, true, "LastRunFailed",
+			fmt.Sprintf("ListJob %s failed", lastFailed.Name), generation)
 	} else {
-		log.Info("Created new ConfigMap with items",
-			"configmap", jobCm.Name,
-			"items", strings.Join(list, ","),
-			"itemCount", len(list),
-			"resourceVersion", jobCm.ResourceVersion)
-	}
-
-	envName := listCronJob.Spec.Template.EnvName
-	if envName == "" {
-		envName = "ITEM"
-	}
-
-	podSpec := corev1.PodSpec{
-		Volumes: []corev1.Volume{
-			{
-				Name: "list",
-				VolumeSource: corev1.VolumeSource{
-					ConfigMap: &corev1.ConfigMapVolumeSource{
-						LocalObjectReference: corev1.LocalObjectReference{Name: jobCm.Name},
-						Optional:             func() *bool { b := true; return &b }(),
-					},
-				},
-			},
-			{
-				Name:         "shared",
-				VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
-			},
-		},
-		InitContainers: []corev1.Container{
-			{
-				Name:  "init",
-				Image: "busybox",
-				Command: []string{"sh", "-c", fmt.Sprintf(`
-					# Read the items file
-					ITEMS=$(cat /list/items)
-					# Get the item at the given index (0-based)
-					VAL=$(echo "$ITEMS" | sed -n "$((JOB_COMPLETION_INDEX+1))p")
-					# Export the value
-					echo "export %s=$VAL" > /shared/env.sh
-				`, envName)},
-				Env: []corev1.EnvVar{
-					{
-						Name: "JOB_COMPLETION_INDEX",
-						ValueFrom: &corev1.EnvVarSource{
-							FieldRef: &corev1.ObjectFieldSelector{
-								FieldPath: "metadata.annotations['batch.kubernetes.io/job-completion-index']",
-							},
-						},
-					},
-				},
-				VolumeMounts: []corev1.VolumeMount{
-					{Name: "list", MountPath: "/list", ReadOnly: true},
-					{Name: "shared", MountPath: "/shared"},
-				},
-			},
-		},
-		Containers: []corev1.Container{
-			{
-				Name:      "main",
-				Image:     listCronJob.Spec.Template.Image,
-				Command:   []string{"sh", "-c", ". /shared/env.sh && " + strings.Join(listCronJob.Spec.Template.Command, " ")},
-				Resources: listCronJob.Spec.Template.Resources,
-				VolumeMounts: []corev1.VolumeMount{
-					{Name: "shared", MountPath: "/shared"},
-				},
-			},
-		},
-		RestartPolicy: corev1.RestartPolicyNever,
+		setCondition(&status.Conditions, batchopsv1alpha1.ConditionDegraded, false, "LastRunSucceeded", "", generation)
 	}
+}
 
-	jobSpec := batchv1.JobSpec{
-		Parallelism:             &listCronJob.Spec.Parallelism,
-		Completions:             &[]int32{int32(len(list))}[0],
-		CompletionMode:          func() *batchv1.CompletionMode { mode := batchv1.IndexedCompletion; return &mode }(),
-		TTLSecondsAfterFinished: listCronJob.Spec.TTLSecondsAfterFinished,
-		Template: corev1.PodTemplateSpec{
-			Spec: podSpec,
-		},
+// maxMissedSchedules is the number of due schedule times mostRecentScheduleTime
+// steps through, more are counted by the interval of the schedule and reported.
+const maxMissedSchedules = 100
+
+// mostRecentScheduleTime returns the latest schedule time after the last run,
+// or the creation of the ListCronJob, that is not after now, together with the
+// number of schedule times due. Schedule times older than the starting
+// deadline are not considered. Beyond maxMissedSchedules, the times are
+// skipped by the interval of the last two, so the number due is an estimate
+// for irregular schedules.
+func mostRecentScheduleTime(listCronJob *batchopsv1alpha1.ListCronJob, schedule cron.Schedule, now time.Time) (*time.Time, int) {
+	earliest := listCronJob.CreationTimestamp.Time
+	if listCronJob.Status.LastScheduleTime != nil {
+		earliest = listCronJob.Status.LastScheduleTime.Time
+	}
+	if deadline := listCronJob.Spec.StartingDeadlineSeconds; deadline != nil {
+		if start := now.Add(-time.Duration(*deadline) * time.Second); start.After(earliest) {
+			earliest = start
+		}
 	}
 
-	// Create or update CronJob
-	cronJob := &batchv1.CronJob{
+	var recent *time.Time
+	due := 0
+	for t := schedule.Next(earliest); !t.IsZero() && !t.After(now); t = schedule.Next(t) {
+		previous := recent
+		scheduled := t
+		recent = &scheduled
+		due++
+		if due == maxMissedSchedules {
+			// Stepping through the times of a long outage one by one could
+			// take long, skip ahead to the last interval before now instead
+			interval := t.Sub(*previous)
+			if skip := now.Sub(t) / interval; skip > 1 {
+				t = t.Add((skip - 1) * interval)
+				due += int(skip - 1)
+			}
+		}
+	}
+	return recent, due
+}
+
+// scheduledListJob builds the ListJob that runs listCronJob for scheduledTime.
+// The name is derived from the schedule time, so a run is created only once.
+func scheduledListJob(listCronJob *batchopsv1alpha1.ListCronJob, scheduledTime time.Time) *batchopsv1alpha1.ListJob {
+	spec := listCronJob.Spec.DeepCopy()
+	return &batchopsv1alpha1.ListJob{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      listCronJob.Name,
-			Namespace: req.Namespace,
+			Name:      fmt.Sprintf("%s-%d", listCronJob.Name, scheduledTime.Unix()/60),
+			Namespace: listCronJob.Namespace,
 			Labels: map[string]string{
-				"listcronjob": listCronJob.Name,
+				listCronJobLabel: listCronJob.Name,
 			},
-		},
-		Spec: batchv1.CronJobSpec{
-			Schedule:                   listCronJob.Spec.Schedule,
-			ConcurrencyPolicy:          batchv1.ConcurrencyPolicy(listCronJob.Spec.ConcurrencyPolicy),
-			StartingDeadlineSeconds:    listCronJob.Spec.StartingDeadlineSeconds,
-			SuccessfulJobsHistoryLimit: listCronJob.Spec.SuccessfulJobsHistoryLimit,
-			FailedJobsHistoryLimit:     listCronJob.Spec.FailedJobsHistoryLimit,
-			Suspend:                    listCronJob.Spec.Suspend,
-			JobTemplate: batchv1.JobTemplateSpec{
-				Spec: jobSpec,
+			Annotations: map[string]string{
+				scheduledTimeAnnotation: scheduledTime.UTC().Format(time.RFC3339),
 			},
 		},
+		Spec: batchopsv1alpha1.ListJobSpec{
+			ListSourceRef:           spec.ListSourceRef,
+			StaticList:              spec.StaticList,
+			Parallelism:             spec.Parallelism,
+			Template:                spec.Template,
+			TTLSecondsAfterFinished: spec.TTLSecondsAfterFinished,
+			BatchSize:               spec.BatchSize,
+			JobFailurePolicy:        spec.JobFailurePolicy,
+		},
 	}
+}
 
-	if err := ctrl.SetControllerReference(&listCronJob, cronJob, r.Scheme); err != nil {
-		return ctrl.Result{}, err
+// refreshListSource asks the ListSource of a due run to fetch its items and
+// reports whether the run can start. A run waits up to
+// listSourceRefreshTimeout for the fetch and then uses the current list.
+func (r *ListCronJobReconciler) refreshListSource(ctx context.Context, listCronJob *batchopsv1alpha1.ListCronJob, scheduledTime, now time.Time) (bool, error) {
+	log := ctrl.LoggerFrom(ctx).WithValues("listSource", listCronJob.Spec.ListSourceRef)
+
+	var listSource batchopsv1alpha1.ListSource
+	if err := r.Get(ctx, client.ObjectKey{Name: listCronJob.Spec.ListSourceRef, Namespace: listCronJob.Namespace}, &listSource); err != nil {
+		return false, fmt.Errorf("failed to get ListSource %s: %w", listCronJob.Spec.ListSourceRef, err)
 	}
 
-	// Check if CronJob already exists
-	existingCronJob := &batchv1.CronJob{}
-	err := r.Get(ctx, client.ObjectKey{Name: listCronJob.Name, Namespace: req.Namespace}, existingCronJob)
-	if err != nil {
-		if apierrors.IsNotFound(err) {
-			// Create new CronJob
-			if err := r.Create(ctx, cronJob); err != nil {
-				log.Error(err, "Failed to create CronJob")
-				return ctrl.Result{}, err
+	request := scheduledTime.UTC().Format(time.RFC3339)
+	if listSource.Status.LastRefreshRequest == request {
+		log.V(1).Info("ListSource refreshed for run", "scheduledTime", request)
+		return true, nil
+	}
+	if now.Sub(scheduledTime) > listSourceRefreshTimeout {
+		log.Info("ListSource did not refresh in time, running with its current list", "scheduledTime", request)
+		r.Recorder.Event(listCronJob, corev1.EventTypeWarning, "RefreshTimeout",
+			fmt.Sprintf("ListSource %s did not refresh within %s, running with its current list", listSource.Name, listSourceRefreshTimeout))
+		return true, nil
+	}
+
+	if listSource.Annotations[batchopsv1alpha1.RefreshRequestedAnnotation] != request {
+		patch := client.MergeFrom(listSource.DeepCopy())
+		if listSource.Annotations == nil {
+			listSource.Annotations = map[string]string{}
+		}
+		listSource.Annotations[batchopsv1alpha1.RefreshRequestedAnnotation] = request
+		if err := r.Patch(ctx, &listSource, patch); err != nil {
+			return false, fmt.Errorf("failed to request refresh of ListSource %s: %w", listSource.Name, err)
+		}
+		log.Info("Requested ListSource refresh", "scheduledTime", request)
+	}
+	return false, nil
+}
+
+// pruneHistory deletes the oldest of the finished ListJobs beyond limit.
+func (r *ListCronJobReconciler) pruneHistory(ctx context.Context, listJobs []*batchopsv1alpha1.ListJob, limit int) error {
+	if len(listJobs) <= limit {
+		return nil
+	}
+	sort.Slice(listJobs, func(i, j int) bool {
+		if !listJobs[i].CreationTimestamp.Equal(&listJobs[j].CreationTimestamp) {
+			return listJobs[i].CreationTimestamp.Before(&listJobs[j].CreationTimestamp)
+		}
+		return listJobs[i].Name < listJobs[j].Name
+	})
+	for _, listJob := range listJobs[:len(listJobs)-limit] {
+		ctrl.LoggerFrom(ctx).Info("Deleting ListJob beyond history limit", "listJob", listJob.Name)
+		if err := r.Delete(ctx, listJob, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete ListJob %s: %w", listJob.Name, err)
+		}
+	}
+	return nil
+}
+
+// deleteLegacyResources removes the CronJob and ConfigMap earlier versions
+// created for a ListCronJob.
+func (r *ListCronJobReconciler) deleteLegacyResources(ctx context.Context, listCronJob *batchopsv1alpha1.ListCronJob) error {
+	legacy := []client.Object{
+		&batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{Name: listCronJob.Name, Namespace: listCronJob.Namespace}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("%s-list", listCronJob.Name), Namespace: listCronJob.Namespace}},
+	}
+	for _, obj := range legacy {
+		if err := r.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
 			}
-			log.Info("Created new CronJob",
-				"name", cronJob.Name,
-				"completions", *jobSpec.Completions,
-				"configmap", jobCm.Name,
-				"configmapVersion", jobCm.ResourceVersion)
-		} else {
-			log.Error(err, "Failed to get CronJob")
-			return ctrl.Result{}, err
+			return err
 		}
-	} else {
-		// Update existing CronJob
-		existingCronJob.Spec = cronJob.Spec
-		// Force a new revision by updating the template
-		existingCronJob.Spec.JobTemplate.Spec.Template.ObjectMeta.Annotations = map[string]string{
-			"configmap-version": jobCm.ResourceVersion,
+		if !metav1.IsControlledBy(obj, listCronJob) {
+			continue
 		}
-		if err := r.Update(ctx, existingCronJob); err != nil {
-			log.Error(err, "Failed to update CronJob")
-			return ctrl.Result{}, err
+		ctrl.LoggerFrom(ctx).Info("Deleting resource created by an earlier version", "name", obj.GetName())
+		if err := r.Delete(ctx, obj, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !apierrors.IsNotFound(err) {
+			return err
 		}
-		log.Info("Updated existing CronJob",
-			"name", cronJob.Name,
-			"completions", *jobSpec.Completions,
-			"configmap", jobCm.Name,
-			"configmapVersion", jobCm.ResourceVersion,
-			"oldCompletions", *existingCronJob.Spec.JobTemplate.Spec.Completions)
 	}
+	return nil
+}
 
-	return ctrl.Result{}, nil
+// historyLimit returns the configured limit, or def when it is unset.
+func historyLimit(limit *int32, def int) int {
+	if limit == nil {
+		return def
+	}
+	return int(*limit)
+}
+
+// listJobReferences returns references to listJobs, sorted by name.
+func listJobReferences(listJobs []*batchopsv1alpha1.ListJob) []corev1.ObjectReference {
+	var refs []corev1.ObjectReference
+	for _, listJob := range listJobs {
+		refs = append(refs, corev1.ObjectReference{
+			APIVersion: batchopsv1alpha1.GroupVersion.String(),
+			Kind:       "ListJob",
+			Name:       listJob.Name,
+			Namespace:  listJob.Namespace,
+			UID:        listJob.UID,
+		})
+	}
+	sort.Slice(refs, func(i, j int) bool { return refs[i].Name < refs[j].Name })
+	return refs
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *ListCronJobReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&batchopsv1alpha1.ListCronJob{}).
+		Owns(&batchopsv1alpha1.ListJob{}).
 		Watches(
-			&corev1.ConfigMap{},
-			handler.EnqueueRequestsFromMapFunc(r.findObjectsForConfigMap),
-			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}),
+			&batchopsv1alpha1.ListSource{},
+			handler.EnqueueRequestsFromMapFunc(r.findObjectsForListSource),
 		).
 		Complete(r)
 }
 
-// findObjectsForConfigMap maps a ConfigMap to ListCronJobs that reference it
-func (r *ListCronJobReconciler) findObjectsForConfigMap(ctx context.Context, obj client.Object) []reconcile.Request {
-	configMap, ok := obj.(*corev1.ConfigMap)
-	if !ok {
-		return []reconcile.Request{}
-	}
-
-	// Get all ListCronJobs in the same namespace
+// findObjectsForListSource maps a ListSource to the ListCronJobs that reference
+// it, so runs waiting for a refresh start as soon as it completes.
+func (r *ListCronJobReconciler) findObjectsForListSource(ctx context.Context, obj client.Object) []reconcile.Request {
 	var listCronJobs batchopsv1alpha1.ListCronJobList
-	if err := r.List(ctx, &listCronJobs, client.InNamespace(configMap.Namespace)); err != nil {
+	if err := r.List(ctx, &listCronJobs, client.InNamespace(obj.GetNamespace())); err != nil {
 		return []reconcile.Request{}
 	}
 
 	requests := []reconcile.Request{}
 	for _, listCronJob := range listCronJobs.Items {
-		// Check if this ListCronJob references our ConfigMap
-		if listCronJob.Spec.ListSourceRef == configMap.Name {
+		if listCronJob.Spec.RefreshListSource && listCronJob.Spec.ListSourceRef == obj.GetName() {
 			requests = append(requests, reconcile.Request{
 				NamespacedName: types.NamespacedName{
 					Name:      listCronJob.Name,
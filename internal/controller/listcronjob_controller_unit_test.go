@@ -18,14 +18,25 @@ package controller
 
 import (
 	"context"
+	"fmt"
 	"testing"
+	"time"
 
+	"github.com/robfig/cron/v3"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	clocktesting "k8s.io/utils/clock/testing"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	batchopsv1alpha1 "github.com/matanryngler/parallax/api/v1alpha1"
@@ -110,3 +121,358 @@ func TestListCronJobController_CronJobCreation(t *testing.T) {
 	assert.Equal(t, int32(3), *listCronJob.Spec.SuccessfulJobsHistoryLimit)
 	assert.Equal(t, int32(1), *listCronJob.Spec.FailedJobsHistoryLimit)
 }
+
+func newListCronJobReconciler(t *testing.T, now time.Time, objs ...client.Object) *ListCronJobReconciler {
+	scheme := runtime.NewScheme()
+	require.NoError(t, batchopsv1alpha1.AddToScheme(scheme))
+	require.NoError(t, corev1.AddToScheme(scheme))
+	require.NoError(t, batchv1.AddToScheme(scheme))
+
+	return &ListCronJobReconciler{
+		Client: fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(objs...).
+			WithStatusSubresource(&batchopsv1alpha1.ListCronJob{}, &batchopsv1alpha1.ListSource{}).
+			Build(),
+		Scheme:   scheme,
+		Recorder: record.NewFakeRecorder(10),
+		Clock:    clocktesting.NewFakePassiveClock(now),
+	}
+}
+
+func scheduledListCronJob(created time.Time) *batchopsv1alpha1.ListCronJob {
+	return &batchopsv1alpha1.ListCronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "nightly",
+			Namespace:         "default",
+			Finalizers:        []string{listCronJobFinalizer},
+			CreationTimestamp: metav1.NewTime(created),
+		},
+		Spec: batchopsv1alpha1.ListCronJobSpec{
+			ListSourceRef: "source",
+			Parallelism:   2,
+			Template: batchopsv1alpha1.JobTemplateSpec{
+				Image:   "busybox",
+				Command: []string{"echo"},
+				EnvName: "ITEM",
+			},
+			Schedule:  "0 * * * *",
+			BatchSize: 5,
+		},
+	}
+}
+
+func TestMostRecentScheduleTime(t *testing.T) {
+	schedule, err := cron.ParseStandard("0 * * * *")
+	require.NoError(t, err)
+	created := time.Date(2025, 1, 1, 9, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		last     *time.Time
+		deadline *int64
+		now      time.Time
+		want     *time.Time
+		wantDue  int
+	}{
+		{
+			name: "Not Yet Due",
+			now:  created.Add(20 * time.Minute),
+		},
+		{
+			name:    "Due",
+			now:     created.Add(31 * time.Minute),
+			want:    ptr.To(created.Add(30 * time.Minute)),
+			wantDue: 1,
+		},
+		{
+			name:    "Missed Runs",
+			now:     created.Add(3 * time.Hour),
+			want:    ptr.To(created.Add(150 * time.Minute)),
+			wantDue: 3,
+		},
+		{
+			name:    "Too Many Missed Runs",
+			now:     created.Add(1000 * time.Hour),
+			want:    ptr.To(created.Add(999*time.Hour + 30*time.Minute)),
+			wantDue: 1000,
+		},
+		{
+			name: "Already Run",
+			last: ptr.To(created.Add(30 * time.Minute)),
+			now:  created.Add(40 * time.Minute),
+		},
+		{
+			name:     "Past Starting Deadline",
+			deadline: ptr.To(int64(60)),
+			now:      created.Add(35 * time.Minute),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listCronJob := scheduledListCronJob(created)
+			listCronJob.Spec.StartingDeadlineSeconds = tt.deadline
+			if tt.last != nil {
+				listCronJob.Status.LastScheduleTime = &metav1.Time{Time: *tt.last}
+			}
+
+			got, due := mostRecentScheduleTime(listCronJob, schedule, tt.now)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantDue, due)
+		})
+	}
+}
+
+func TestListCronJobController_Schedule(t *testing.T) {
+	ctx := context.Background()
+	created := time.Date(2025, 1, 1, 9, 30, 0, 0, time.UTC)
+	scheduled := created.Add(30 * time.Minute)
+	now := scheduled.Add(10 * time.Second)
+	listCronJob := scheduledListCronJob(created)
+	r := newListCronJobReconciler(t, now, listCronJob)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "nightly", Namespace: "default"}}
+
+	result, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, time.Hour-10*time.Second, result.RequeueAfter)
+
+	var listJobs batchopsv1alpha1.ListJobList
+	require.NoError(t, r.List(ctx, &listJobs, client.InNamespace("default")))
+	require.Len(t, listJobs.Items, 1)
+	listJob := listJobs.Items[0]
+	assert.Equal(t, fmt.Sprintf("nightly-%d", scheduled.Unix()/60), listJob.Name)
+	assert.Equal(t, "nightly", listJob.Labels[listCronJobLabel])
+	assert.Equal(t, "2025-01-01T10:00:00Z", listJob.Annotations[scheduledTimeAnnotation])
+	assert.True(t, metav1.IsControlledBy(&listJob, listCronJob))
+	assert.Equal(t, "source", listJob.Spec.ListSourceRef)
+	assert.Equal(t, int32(2), listJob.Spec.Parallelism)
+	assert.Equal(t, int32(5), listJob.Spec.BatchSize)
+	assert.Equal(t, "busybox", listJob.Spec.Template.Image)
+
+	var updated batchopsv1alpha1.ListCronJob
+	require.NoError(t, r.Get(ctx, req.NamespacedName, &updated))
+	require.NotNil(t, updated.Status.LastScheduleTime)
+	assert.True(t, updated.Status.LastScheduleTime.Equal(&metav1.Time{Time: scheduled}))
+	require.Len(t, updated.Status.Active, 1)
+	assert.Equal(t, listJob.Name, updated.Status.Active[0].Name)
+	assert.Equal(t, "ListJob", updated.Status.Active[0].Kind)
+	assert.True(t, meta.IsStatusConditionTrue(updated.Status.Conditions, batchopsv1alpha1.ConditionReady))
+	assert.True(t, meta.IsStatusConditionFalse(updated.Status.Conditions, batchopsv1alpha1.ConditionSuspended))
+
+	// A second reconcile within the same period creates nothing
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	require.NoError(t, r.List(ctx, &listJobs, client.InNamespace("default")))
+	assert.Len(t, listJobs.Items, 1)
+}
+
+func TestListCronJobController_Suspend(t *testing.T) {
+	ctx := context.Background()
+	created := time.Date(2025, 1, 1, 9, 30, 0, 0, time.UTC)
+	listCronJob := scheduledListCronJob(created)
+	listCronJob.Spec.Suspend = ptr.To(true)
+	r := newListCronJobReconciler(t, created.Add(time.Hour), listCronJob)
+
+	result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: "nightly", Namespace: "default"}})
+	require.NoError(t, err)
+	assert.Zero(t, result.RequeueAfter)
+
+	var listJobs batchopsv1alpha1.ListJobList
+	require.NoError(t, r.List(ctx, &listJobs, client.InNamespace("default")))
+	assert.Empty(t, listJobs.Items)
+
+	var updated batchopsv1alpha1.ListCronJob
+	require.NoError(t, r.Get(ctx, client.ObjectKeyFromObject(listCronJob), &updated))
+	assert.True(t, meta.IsStatusConditionTrue(updated.Status.Conditions, batchopsv1alpha1.ConditionSuspended))
+}
+
+func TestListCronJobController_InvalidSchedule(t *testing.T) {
+	ctx := context.Background()
+	created := time.Date(2025, 1, 1, 9, 30, 0, 0, time.UTC)
+	listCronJob := scheduledListCronJob(created)
+	listCronJob.Spec.Schedule = "every hour"
+	r := newListCronJobReconciler(t, created.Add(time.Hour), listCronJob)
+
+	_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: "nightly", Namespace: "default"}})
+	require.NoError(t, err)
+
+	var updated batchopsv1alpha1.ListCronJob
+	require.NoError(t, r.Get(ctx, client.ObjectKeyFromObject(listCronJob), &updated))
+	ready := meta.FindStatusCondition(updated.Status.Conditions, batchopsv1alpha1.ConditionReady)
+	require.NotNil(t, ready)
+	assert.Equal(t, metav1.ConditionFalse, ready.Status)
+	assert.Equal(t, "InvalidSchedule", ready.Reason)
+}
+
+func TestListCronJobController_ConcurrencyPolicy(t *testing.T) {
+	ctx := context.Background()
+	created := time.Date(2025, 1, 1, 9, 30, 0, 0, time.UTC)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "nightly", Namespace: "default"}}
+
+	running := func(listCronJob *batchopsv1alpha1.ListCronJob) *batchopsv1alpha1.ListJob {
+		listJob := scheduledListJob(listCronJob, created)
+		listJob.Name = "nightly-running"
+		listJob.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(listCronJob, batchopsv1alpha1.GroupVersion.WithKind("ListCronJob"))}
+		listJob.Status.Phase = batchopsv1alpha1.ListJobRunning
+		return listJob
+	}
+
+	tests := []struct {
+		name   string
+		policy batchv1.ConcurrencyPolicy
+		want   []string
+	}{
+		{
+			name:   "Allow",
+			policy: batchv1.AllowConcurrent,
+			want:   []string{"nightly-28928760", "nightly-running"},
+		},
+		{
+			name:   "Forbid",
+			policy: batchv1.ForbidConcurrent,
+			want:   []string{"nightly-running"},
+		},
+		{
+			name:   "Replace",
+			policy: batchv1.ReplaceConcurrent,
+			want:   []string{"nightly-28928760"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listCronJob := scheduledListCronJob(created)
+			listCronJob.Spec.ConcurrencyPolicy = tt.policy
+			r := newListCronJobReconciler(t, created.Add(31*time.Minute), listCronJob, running(listCronJob))
+
+			_, err := r.Reconcile(ctx, req)
+			require.NoError(t, err)
+
+			var listJobs batchopsv1alpha1.ListJobList
+			require.NoError(t, r.List(ctx, &listJobs, client.InNamespace("default")))
+			var names []string
+			for _, listJob := range listJobs.Items {
+				names = append(names, listJob.Name)
+			}
+			assert.ElementsMatch(t, tt.want, names)
+
+			// A run skipped for an active ListJob is not started later
+			var updated batchopsv1alpha1.ListCronJob
+			require.NoError(t, r.Get(ctx, req.NamespacedName, &updated))
+			require.NotNil(t, updated.Status.LastScheduleTime)
+			assert.True(t, updated.Status.LastScheduleTime.Equal(&metav1.Time{Time: created.Add(30 * time.Minute)}))
+		})
+	}
+}
+
+func TestListCronJobController_History(t *testing.T) {
+	ctx := context.Background()
+	created := time.Date(2025, 1, 1, 9, 30, 0, 0, time.UTC)
+	listCronJob := scheduledListCronJob(created)
+	listCronJob.Spec.SuccessfulJobsHistoryLimit = ptr.To(int32(1))
+	listCronJob.Status.LastScheduleTime = &metav1.Time{Time: created.Add(30 * time.Minute)}
+
+	objs := []client.Object{listCronJob}
+	for i, phase := range []batchopsv1alpha1.ListJobPhase{
+		batchopsv1alpha1.ListJobSucceeded,
+		batchopsv1alpha1.ListJobFailed,
+		batchopsv1alpha1.ListJobSucceeded,
+		batchopsv1alpha1.ListJobFailed,
+	} {
+		listJob := scheduledListJob(listCronJob, created.Add(time.Duration(i)*time.Minute))
+		listJob.CreationTimestamp = metav1.NewTime(created.Add(time.Duration(i) * time.Minute))
+		listJob.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(listCronJob, batchopsv1alpha1.GroupVersion.WithKind("ListCronJob"))}
+		listJob.Status.Phase = phase
+		listJob.Status.CompletionTime = &metav1.Time{Time: created.Add(time.Duration(i) * time.Minute)}
+		objs = append(objs, listJob)
+	}
+	r := newListCronJobReconciler(t, created.Add(40*time.Minute), objs...)
+
+	_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: "nightly", Namespace: "default"}})
+	require.NoError(t, err)
+
+	var listJobs batchopsv1alpha1.ListJobList
+	require.NoError(t, r.List(ctx, &listJobs, client.InNamespace("default")))
+	var names []string
+	for _, listJob := range listJobs.Items {
+		names = append(names, listJob.Name)
+	}
+	// The newest successful and the newest failed ListJob are kept
+	assert.ElementsMatch(t, []string{"nightly-28928732", "nightly-28928733"}, names)
+
+	var updated batchopsv1alpha1.ListCronJob
+	require.NoError(t, r.Get(ctx, client.ObjectKeyFromObject(listCronJob), &updated))
+	require.NotNil(t, updated.Status.LastSuccessfulTime)
+	assert.True(t, updated.Status.LastSuccessfulTime.Equal(&metav1.Time{Time: created.Add(2 * time.Minute)}))
+	assert.Empty(t, updated.Status.Active)
+	// The most recent run failed
+	assert.True(t, meta.IsStatusConditionTrue(updated.Status.Conditions, batchopsv1alpha1.ConditionDegraded))
+}
+
+func TestListCronJobController_RefreshListSource(t *testing.T) {
+	ctx := context.Background()
+	created := time.Date(2025, 1, 1, 9, 30, 0, 0, time.UTC)
+	listCronJob := scheduledListCronJob(created)
+	listCronJob.Spec.RefreshListSource = true
+	listSource := &batchopsv1alpha1.ListSource{
+		ObjectMeta: metav1.ObjectMeta{Name: "source", Namespace: "default"},
+		Spec:       batchopsv1alpha1.ListSourceSpec{Type: batchopsv1alpha1.StaticList, StaticList: []string{"a"}},
+	}
+	r := newListCronJobReconciler(t, created.Add(31*time.Minute), listCronJob, listSource)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "nightly", Namespace: "default"}}
+
+	// The run waits for the ListSource to fetch its items
+	result, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, listSourceRefreshPoll, result.RequeueAfter)
+
+	var listJobs batchopsv1alpha1.ListJobList
+	require.NoError(t, r.List(ctx, &listJobs, client.InNamespace("default")))
+	assert.Empty(t, listJobs.Items)
+	require.NoError(t, r.Get(ctx, client.ObjectKeyFromObject(listSource), listSource))
+	assert.Equal(t, "2025-01-01T10:00:00Z", listSource.Annotations[batchopsv1alpha1.RefreshRequestedAnnotation])
+
+	// Once the ListSource echoes the request, the ListJob is created
+	listSource.Status.LastRefreshRequest = "2025-01-01T10:00:00Z"
+	require.NoError(t, r.Status().Update(ctx, listSource))
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	require.NoError(t, r.List(ctx, &listJobs, client.InNamespace("default")))
+	assert.Len(t, listJobs.Items, 1)
+}
+
+func TestListCronJobController_RefreshTimeout(t *testing.T) {
+	ctx := context.Background()
+	created := time.Date(2025, 1, 1, 9, 30, 0, 0, time.UTC)
+	listCronJob := scheduledListCronJob(created)
+	listCronJob.Spec.RefreshListSource = true
+	listSource := &batchopsv1alpha1.ListSource{
+		ObjectMeta: metav1.ObjectMeta{Name: "source", Namespace: "default"},
+	}
+	r := newListCronJobReconciler(t, created.Add(30*time.Minute+listSourceRefreshTimeout+time.Second), listCronJob, listSource)
+
+	_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: "nightly", Namespace: "default"}})
+	require.NoError(t, err)
+
+	var listJobs batchopsv1alpha1.ListJobList
+	require.NoError(t, r.List(ctx, &listJobs, client.InNamespace("default")))
+	assert.Len(t, listJobs.Items, 1)
+	assert.Contains(t, <-r.Recorder.(*record.FakeRecorder).Events, "RefreshTimeout")
+}
+
+func TestListCronJobController_DeletesLegacyCronJob(t *testing.T) {
+	ctx := context.Background()
+	created := time.Date(2025, 1, 1, 9, 30, 0, 0, time.UTC)
+	listCronJob := scheduledListCronJob(created)
+	owner := []metav1.OwnerReference{*metav1.NewControllerRef(listCronJob, batchopsv1alpha1.GroupVersion.WithKind("ListCronJob"))}
+	cronJob := &batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "default", OwnerReferences: owner}}
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "nightly-list", Namespace: "default", OwnerReferences: owner}}
+	r := newListCronJobReconciler(t, created.Add(time.Minute), listCronJob, cronJob, configMap)
+
+	_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: "nightly", Namespace: "default"}})
+	require.NoError(t, err)
+
+	assert.True(t, apierrors.IsNotFound(r.Get(ctx, client.ObjectKeyFromObject(cronJob), &batchv1.CronJob{})))
+	assert.True(t, apierrors.IsNotFound(r.Get(ctx, client.ObjectKeyFromObject(configMap), &corev1.ConfigMap{})))
+}
@@ -3,23 +3,34 @@ package controller
 import (
 	"context"
 	"fmt"
-	"strings"
+	"sort"
+	"strconv"
 
 	batchopsv1alpha1 "github.com/matanryngler/parallax/api/v1alpha1"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 // ListJobReconciler reconciles a ListJob object
 type ListJobReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+
+	// items caches the decoded items of the Jobs, which every pod event
+	// would otherwise read and decompress again
+	items itemsCache
 }
 
 const listJobFinalizer = "listjob.batchops.io/finalizer"
@@ -27,8 +38,10 @@ const listJobFinalizer = "listjob.batchops.io/finalizer"
 // +kubebuilder:rbac:groups=batchops.io,resources=listjobs,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=batchops.io,resources=listjobs/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=batchops.io,resources=listjobs/finalizers,verbs=update
-// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
-// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=batchops.io,resources=listsources,verbs=get;list;watch
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete;deletecollection
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete;deletecollection
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 func (r *ListJobReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -42,6 +55,7 @@ func (r *ListJobReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	if err := r.Get(ctx, req.NamespacedName, &listJob); err != nil {
 		if apierrors.IsNotFound(err) {
 			log.V(1).Info("ListJob not found. Likely deleted.", "name", req.Name, "namespace", req.Namespace)
+			r.items.forget(req.NamespacedName)
 			return ctrl.Result{}, nil
 		}
 		log.Error(err, "Failed to get ListJob")
@@ -56,6 +70,7 @@ func (r *ListJobReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	)
 
 	if !listJob.DeletionTimestamp.IsZero() {
+		r.items.forget(req.NamespacedName)
 		if controllerutil.ContainsFinalizer(&listJob, listJobFinalizer) {
 			log.Info("Cleaning up child resources before deletion")
 			job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: listJob.Name, Namespace: listJob.Namespace}}
@@ -67,6 +82,16 @@ func (r *ListJobReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 			})
 			cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("%s-list", listJob.Name), Namespace: listJob.Namespace}}
 			_ = r.Delete(ctx, cm)
+			// Jobs and ConfigMaps of additional shards
+			_ = r.DeleteAllOf(ctx, &batchv1.Job{},
+				client.InNamespace(listJob.Namespace),
+				client.MatchingLabels{listJobLabel: listJob.Name},
+				client.PropagationPolicy(metav1.DeletePropagationBackground),
+			)
+			_ = r.DeleteAllOf(ctx, &corev1.ConfigMap{},
+				client.InNamespace(listJob.Namespace),
+				client.MatchingLabels{listJobLabel: listJob.Name},
+			)
 
 			controllerutil.RemoveFinalizer(&listJob, listJobFinalizer)
 			if err := r.Update(ctx, &listJob); err != nil {
@@ -98,155 +123,249 @@ func (r *ListJobReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		}
 	}
 
+	// The first Job is created last, so once it exists all shards do
+	var job batchv1.Job
+	err := r.Get(ctx, types.NamespacedName{Name: listJob.Name, Namespace: listJob.Namespace}, &job)
+	if err != nil && !apierrors.IsNotFound(err) {
+		log.Error(err, "Failed to get Job")
+		return ctrl.Result{}, err
+	}
+	var jobs []batchv1.Job
+	if apierrors.IsNotFound(err) {
+		if isListJobFinished(listJob.Status) {
+			// The Job was removed after it finished, e.g. by ttlSecondsAfterFinished
+			log.V(1).Info("Job of finished ListJob is gone, not recreating it")
+			if err := r.retryFailedItems(ctx, &listJob, nil); err != nil {
+				log.Error(err, "Failed to retry failed items")
+				return ctrl.Result{}, err
+			}
+			return r.requeueForDeleteAfter(&listJob), nil
+		}
+		if jobs, err = r.createJobs(ctx, &listJob); err != nil {
+			setCondition(&listJob.Status.Conditions, batchopsv1alpha1.ConditionReady, false, "CreateFailed", err.Error(), listJob.Generation)
+			listJob.Status.ObservedGeneration = listJob.Generation
+			if statusErr := r.Status().Update(ctx, &listJob); statusErr != nil {
+				log.Error(statusErr, "Failed to update ListJob status")
+			}
+			return ctrl.Result{}, err
+		}
+	} else if jobs, err = r.listJobs(ctx, &listJob); err != nil {
+		log.Error(err, "Failed to list Jobs")
+		return ctrl.Result{}, err
+	}
+
+	shards := r.jobShards(ctx, &listJob, jobs)
+	if err := r.updateStatus(ctx, &listJob, shards); err != nil {
+		log.Error(err, "Failed to update ListJob status")
+		return ctrl.Result{}, err
+	}
+
+	if err := r.retryFailedItems(ctx, &listJob, shards); err != nil {
+		log.Error(err, "Failed to retry failed items")
+		return ctrl.Result{}, err
+	}
+	if isListJobFinished(listJob.Status) {
+		// Few pod events follow, the items need not stay in memory
+		r.items.forget(req.NamespacedName)
+	}
+
+	return r.requeueForDeleteAfter(&listJob), nil
+}
+
+func (r *ListJobReconciler) requeueForDeleteAfter(listJob *batchopsv1alpha1.ListJob) ctrl.Result {
+	if listJob.Spec.DeleteAfter != nil {
+		return ctrl.Result{RequeueAfter: listJob.Spec.DeleteAfter.Duration}
+	}
+	return ctrl.Result{}
+}
+
+// createJobs creates the items ConfigMaps and the Indexed Jobs of a ListJob.
+// Lists that exceed a single ConfigMap or the completion limit of a Job are
+// split into shards, each with its own ConfigMap and Job.
+func (r *ListJobReconciler) createJobs(ctx context.Context, listJob *batchopsv1alpha1.ListJob) ([]batchv1.Job, error) {
+	log := ctrl.LoggerFrom(ctx)
+
 	var list []string
 	if len(listJob.Spec.StaticList) > 0 {
 		list = listJob.Spec.StaticList
 	} else if listJob.Spec.ListSourceRef != "" {
-		// Get the ListSource's ConfigMap
-		var listSourceCM corev1.ConfigMap
-		if err := r.Get(ctx, client.ObjectKey{Name: listJob.Spec.ListSourceRef, Namespace: req.Namespace}, &listSourceCM); err != nil {
-			log.Error(err, "Failed to get ListSource ConfigMap", "configMap", listJob.Spec.ListSourceRef)
-			return ctrl.Result{}, err
+		items, err := getListSourceItems(ctx, r.Client, listJob.Namespace, listJob.Spec.ListSourceRef)
+		if err != nil {
+			log.Error(err, "Failed to read items from ListSource ConfigMap", "configMap", listJob.Spec.ListSourceRef)
+			return nil, err
 		}
+		list = items
+	} else {
+		log.Error(nil, "Neither StaticList nor ListSourceRef specified")
+		return nil, fmt.Errorf("either StaticList or ListSourceRef must be specified")
+	}
 
-		// Parse the items from the ConfigMap
-		itemsStr := listSourceCM.Data["items"]
-		if itemsStr == "" {
-			log.Error(nil, "ListSource ConfigMap has no items", "configMap", listJob.Spec.ListSourceRef)
-			return ctrl.Result{}, fmt.Errorf("ListSource ConfigMap has no items")
+	batchSize := max(int(listJob.Spec.BatchSize), 1)
+	chunks := splitItems(list, batchSize, maxCompletionsPerJob*batchSize, jobItemCost(listJob.Spec.Template), maxConfigMapItemsSize)
+
+	jobs := make([]batchv1.Job, 0, len(chunks))
+	offset := 0
+	for i, chunk := range chunks {
+		name := jobShardName(listJob.Name, i)
+
+		// Create ConfigMap with newline-separated items
+		jobCm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("%s-list", name),
+				Namespace: listJob.Namespace,
+				Labels: map[string]string{
+					listJobLabel: listJob.Name,
+				},
+			},
+			Data: buildItemsData(chunk, listJob.Spec.Template),
+		}
+		if err := ctrl.SetControllerReference(listJob, jobCm, r.Scheme); err != nil {
+			return nil, err
+		}
+		if err := r.Create(ctx, jobCm); err != nil {
+			if !apierrors.IsAlreadyExists(err) {
+				log.Error(err, "Failed to create ConfigMap")
+				return nil, err
+			}
 		}
 
-		// Split by newlines and trim whitespace
-		list = strings.Split(itemsStr, "\n")
-		for i, item := range list {
-			list[i] = strings.TrimSpace(item)
+		job, err := r.buildJob(listJob, name, jobCm.Name, len(chunk), offset)
+		if err != nil {
+			log.Error(err, "Failed to build Job")
+			return nil, err
 		}
-	} else {
-		log.Error(nil, "Neither StaticList nor ListSourceRef specified")
-		return ctrl.Result{}, fmt.Errorf("either StaticList or ListSourceRef must be specified")
+		jobs = append(jobs, *job)
+		offset += len(chunk)
 	}
 
-	// Create ConfigMap with newline-separated items
-	jobCm := &corev1.ConfigMap{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      fmt.Sprintf("%s-list", listJob.Name),
-			Namespace: req.Namespace,
-		},
-		Data: map[string]string{
-			"items": strings.Join(list, "\n"),
-		},
-	}
-	if err := ctrl.SetControllerReference(&listJob, jobCm, r.Scheme); err != nil {
-		return ctrl.Result{}, err
-	}
-	if err := r.Create(ctx, jobCm); err != nil {
-		if !apierrors.IsAlreadyExists(err) {
-			log.Error(err, "Failed to create ConfigMap")
-			return ctrl.Result{}, err
+	for i := len(jobs) - 1; i >= 0; i-- {
+		job := &jobs[i]
+		if err := r.Create(ctx, job); err != nil {
+			if !apierrors.IsAlreadyExists(err) {
+				log.Error(err, "Failed to create Job")
+				return nil, err
+			}
+			if err := r.Get(ctx, client.ObjectKeyFromObject(job), job); err != nil {
+				return nil, err
+			}
 		}
 	}
+	log.Info("Created Jobs for ListJob", "jobs", len(jobs), "items", len(list))
+	return jobs, nil
+}
 
-	envName := listJob.Spec.Template.EnvName
-	if envName == "" {
-		envName = "ITEM"
+// buildJob builds the Indexed Job running itemCount items of the ConfigMap
+// listConfigMap, which start at position offset of the full list.
+func (r *ListJobReconciler) buildJob(listJob *batchopsv1alpha1.ListJob, name, listConfigMap string, itemCount, offset int) (*batchv1.Job, error) {
+	podTemplate, err := buildItemPodTemplate(listJob.Spec.Template, listConfigMap, false, listJob.Spec.BatchSize)
+	if err != nil {
+		return nil, fmt.Errorf("invalid job template: %w", err)
 	}
-
-	podSpec := corev1.PodSpec{
-		Volumes: []corev1.Volume{
-			{
-				Name: "list",
-				VolumeSource: corev1.VolumeSource{
-					ConfigMap: &corev1.ConfigMapVolumeSource{
-						LocalObjectReference: corev1.LocalObjectReference{Name: jobCm.Name},
-					},
-				},
-			},
-			{
-				Name:         "shared",
-				VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
-			},
-		},
-		InitContainers: []corev1.Container{
-			{
-				Name:  "init",
-				Image: "busybox",
-				Command: []string{"sh", "-c", fmt.Sprintf(`
-					# Read the items file
-					ITEMS=$(cat /list/items)
-					# Get the item at the given index (0-based)
-					VAL=$(echo "$ITEMS" | sed -n "$((JOB_COMPLETION_INDEX+1))p")
-					# Export the value
-					echo "export %s=$VAL" > /shared/env.sh
-				`, envName)},
-				Env: []corev1.EnvVar{
-					{
-						Name: "JOB_COMPLETION_INDEX",
-						ValueFrom: &corev1.EnvVarSource{
-							FieldRef: &corev1.ObjectFieldSelector{
-								FieldPath: "metadata.annotations['batch.kubernetes.io/job-completion-index']",
-							},
-						},
-					},
-				},
-				VolumeMounts: []corev1.VolumeMount{
-					{Name: "list", MountPath: "/list"},
-					{Name: "shared", MountPath: "/shared"},
-				},
-			},
-		},
-		Containers: []corev1.Container{
-			{
-				Name:      "main",
-				Image:     listJob.Spec.Template.Image,
-				Command:   []string{"sh", "-c", ". /shared/env.sh && " + strings.Join(listJob.Spec.Template.Command, " ")},
-				Resources: listJob.Spec.Template.Resources,
-				VolumeMounts: []corev1.VolumeMount{
-					{Name: "shared", MountPath: "/shared"},
-				},
-			},
-		},
-		RestartPolicy: corev1.RestartPolicyNever,
+	if podTemplate.Labels == nil {
+		podTemplate.Labels = map[string]string{}
 	}
+	podTemplate.Labels[listJobLabel] = listJob.Name
 
 	jobSpec := batchv1.JobSpec{
 		Parallelism:             &listJob.Spec.Parallelism,
-		Completions:             &[]int32{int32(len(list))}[0],
+		Completions:             &[]int32{batchCompletions(itemCount, listJob.Spec.BatchSize)}[0],
 		CompletionMode:          func() *batchv1.CompletionMode { mode := batchv1.IndexedCompletion; return &mode }(),
 		TTLSecondsAfterFinished: listJob.Spec.TTLSecondsAfterFinished,
-		Template: corev1.PodTemplateSpec{
-			Spec: podSpec,
-		},
+		Template:                podTemplate,
+	}
+	if err := applyJobFailurePolicy(&jobSpec, listJob.Spec.JobFailurePolicy); err != nil {
+		return nil, fmt.Errorf("invalid failure policy: %w", err)
 	}
 
 	job := &batchv1.Job{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      listJob.Name,
-			Namespace: req.Namespace,
+			Name:      name,
+			Namespace: listJob.Namespace,
 			Labels: map[string]string{
-				"listjob": listJob.Name,
+				listJobLabel: listJob.Name,
+			},
+			Annotations: map[string]string{
+				shardOffsetAnnotation: strconv.Itoa(offset),
 			},
 		},
 		Spec: jobSpec,
 	}
+	if err := ctrl.SetControllerReference(listJob, job, r.Scheme); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
 
-	if err := ctrl.SetControllerReference(&listJob, job, r.Scheme); err != nil {
-		return ctrl.Result{}, err
+// listJobs returns the Jobs of all shards of a ListJob.
+func (r *ListJobReconciler) listJobs(ctx context.Context, listJob *batchopsv1alpha1.ListJob) ([]batchv1.Job, error) {
+	var jobList batchv1.JobList
+	if err := r.List(ctx, &jobList,
+		client.InNamespace(listJob.Namespace),
+		client.MatchingLabels{listJobLabel: listJob.Name},
+	); err != nil {
+		return nil, err
 	}
-	if err := r.Create(ctx, job); err != nil {
-		if !apierrors.IsAlreadyExists(err) {
-			log.Error(err, "Failed to create Job")
-			return ctrl.Result{}, err
+	jobs := jobList.Items[:0]
+	for _, job := range jobList.Items {
+		if metav1.IsControlledBy(&job, listJob) {
+			jobs = append(jobs, job)
 		}
 	}
+	return jobs, nil
+}
 
-	if listJob.Spec.DeleteAfter != nil {
-		return ctrl.Result{RequeueAfter: listJob.Spec.DeleteAfter.Duration}, nil
+// jobShards pairs every Job of listJob with the items it runs, ordered by
+// position in the list.
+func (r *ListJobReconciler) jobShards(ctx context.Context, listJob *batchopsv1alpha1.ListJob, jobs []batchv1.Job) []listJobShard {
+	log := ctrl.LoggerFrom(ctx)
+
+	shards := make([]listJobShard, 0, len(jobs))
+	for i := range jobs {
+		job := &jobs[i]
+		offset, _ := strconv.Atoi(job.Annotations[shardOffsetAnnotation])
+		items, err := r.items.read(ctx, r.Client, client.ObjectKeyFromObject(listJob), fmt.Sprintf("%s-list", job.Name))
+		if err != nil {
+			// Item values are informational only, keep reporting outcomes without them
+			log.V(1).Info("Unable to read items of Job", "job", job.Name, "error", err.Error())
+		}
+		shards = append(shards, listJobShard{Job: job, Items: items, Offset: int32(offset)})
+	}
+	sort.Slice(shards, func(i, j int) bool { return shards[i].Offset < shards[j].Offset })
+	return shards
+}
+
+// updateStatus refreshes the ListJob status from its Jobs and pods.
+func (r *ListJobReconciler) updateStatus(ctx context.Context, listJob *batchopsv1alpha1.ListJob, shards []listJobShard) error {
+	var pods corev1.PodList
+	if err := r.List(ctx, &pods,
+		client.InNamespace(listJob.Namespace),
+		client.MatchingLabels{listJobLabel: listJob.Name},
+	); err != nil {
+		return err
 	}
-	return ctrl.Result{}, nil
+
+	status := computeListJobStatus(listJob.Status, shards, pods.Items, itemContainerName(listJob.Spec.Template), listJob.Spec.BatchSize)
+	setListJobConditions(&status, listJob.Generation)
+	if equality.Semantic.DeepEqual(listJob.Status, status) {
+		return nil
+	}
+	listJob.Status = status
+	return r.Status().Update(ctx, listJob)
 }
 
 func (r *ListJobReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&batchopsv1alpha1.ListJob{}).
+		Owns(&batchv1.Job{}).
+		Watches(&corev1.Pod{}, handler.EnqueueRequestsFromMapFunc(listJobForPod)).
 		Complete(r)
 }
+
+// listJobForPod maps an item pod to its ListJob through the listjob label.
+func listJobForPod(_ context.Context, obj client.Object) []reconcile.Request {
+	name, ok := obj.GetLabels()[listJobLabel]
+	if !ok {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: name, Namespace: obj.GetNamespace()}}}
+}
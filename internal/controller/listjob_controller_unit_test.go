@@ -18,14 +18,23 @@ package controller
 
 import (
 	"context"
+	"fmt"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	batchopsv1alpha1 "github.com/matanryngler/parallax/api/v1alpha1"
@@ -103,3 +112,495 @@ func TestListJobController_JobCreation_StaticList(t *testing.T) {
 	assert.Equal(t, "busybox", listJob.Spec.Template.Image)
 	assert.Equal(t, "ITEM", listJob.Spec.Template.EnvName)
 }
+
+func TestListJobController_ReconcileStatus(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, batchopsv1alpha1.AddToScheme(scheme))
+	require.NoError(t, corev1.AddToScheme(scheme))
+	require.NoError(t, batchv1.AddToScheme(scheme))
+
+	listJob := &batchopsv1alpha1.ListJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test-job",
+			Namespace:  "default",
+			Finalizers: []string{listJobFinalizer},
+		},
+		Spec: batchopsv1alpha1.ListJobSpec{
+			StaticList:  []string{"a", "b", "c"},
+			Parallelism: 2,
+			Template: batchopsv1alpha1.JobTemplateSpec{
+				Image:   "busybox",
+				Command: []string{"echo", "$ITEM"},
+				EnvName: "ITEM",
+			},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(listJob).
+		WithStatusSubresource(&batchopsv1alpha1.ListJob{}).
+		Build()
+	reconciler := &ListJobReconciler{Client: fakeClient, Scheme: scheme}
+	ctx := context.Background()
+	key := types.NamespacedName{Name: "test-job", Namespace: "default"}
+
+	_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	var job batchv1.Job
+	require.NoError(t, fakeClient.Get(ctx, key, &job))
+	assert.Equal(t, "test-job", job.Spec.Template.Labels[listJobLabel])
+
+	var updated batchopsv1alpha1.ListJob
+	require.NoError(t, fakeClient.Get(ctx, key, &updated))
+	assert.Equal(t, batchopsv1alpha1.ListJobPending, updated.Status.Phase)
+	assert.Equal(t, "0/3", updated.Status.Progress)
+	assert.Equal(t, int32(3), updated.Status.Pending)
+	require.Len(t, updated.Status.Items, 3)
+	assert.Equal(t, "b", updated.Status.Items[1].Item)
+
+	// Index 0 succeeds, index 1 is running
+	job.Status.CompletedIndexes = "0"
+	job.Status.Succeeded = 1
+	require.NoError(t, fakeClient.Status().Update(ctx, &job))
+	require.NoError(t, fakeClient.Create(ctx, itemPod(&job, "test-job-1-abcde", 1, corev1.PodRunning, nil)))
+
+	_, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+	require.NoError(t, fakeClient.Get(ctx, key, &updated))
+	assert.Equal(t, batchopsv1alpha1.ListJobRunning, updated.Status.Phase)
+	assert.Equal(t, "1/3", updated.Status.Progress)
+	assert.Equal(t, int32(1), updated.Status.Running)
+	assert.Equal(t, "test-job-1-abcde", updated.Status.Items[1].PodName)
+
+	// A finished ListJob whose Job was removed by its TTL is not run again
+	updated.Status.Phase = batchopsv1alpha1.ListJobSucceeded
+	require.NoError(t, fakeClient.Status().Update(ctx, &updated))
+	require.NoError(t, fakeClient.Delete(ctx, &job))
+	_, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+	assert.True(t, apierrors.IsNotFound(fakeClient.Get(ctx, key, &batchv1.Job{})))
+}
+
+func TestComputeListJobStatus(t *testing.T) {
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-job", Namespace: "default", UID: "job-uid"},
+		Spec:       batchv1.JobSpec{Completions: &[]int32{4}[0]},
+	}
+	items := []string{"a", "b", "c", "d"}
+	exitCode := func(code int32) *corev1.ContainerStateTerminated {
+		return &corev1.ContainerStateTerminated{ExitCode: code, Reason: "Error", Message: "boom"}
+	}
+
+	t.Run("Running", func(t *testing.T) {
+		job := job.DeepCopy()
+		job.Status.CompletedIndexes = "0"
+		older := itemPod(job, "test-job-1-old", 1, corev1.PodFailed, exitCode(2))
+		newer := itemPod(job, "test-job-1-new", 1, corev1.PodRunning, nil)
+		newer.CreationTimestamp = metav1.NewTime(older.CreationTimestamp.Add(time.Minute))
+		foreign := itemPod(job, "other-2", 2, corev1.PodRunning, nil)
+		foreign.OwnerReferences[0].UID = "other-uid"
+		pods := []corev1.Pod{*older, *newer, *foreign}
+
+		status := computeListJobStatus(batchopsv1alpha1.ListJobStatus{}, []listJobShard{{Job: job, Items: items}}, pods, mainContainerName, 1)
+		assert.Equal(t, batchopsv1alpha1.ListJobRunning, status.Phase)
+		assert.Equal(t, "1/4", status.Progress)
+		assert.Equal(t, int32(4), status.Total)
+		assert.Equal(t, int32(1), status.Succeeded)
+		assert.Equal(t, int32(1), status.Running)
+		assert.Equal(t, int32(2), status.Pending)
+		assert.Equal(t, batchopsv1alpha1.ItemSucceeded, status.Items[0].Phase)
+		assert.Equal(t, "test-job-1-new", status.Items[1].PodName)
+		assert.Equal(t, batchopsv1alpha1.ItemRunning, status.Items[1].Phase)
+		assert.Empty(t, status.Items[2].PodName)
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		job := job.DeepCopy()
+		job.Status.CompletedIndexes = "0-1,3"
+		job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue}}
+		pods := []corev1.Pod{*itemPod(job, "test-job-2-abcde", 2, corev1.PodFailed, exitCode(3))}
+
+		status := computeListJobStatus(batchopsv1alpha1.ListJobStatus{}, []listJobShard{{Job: job, Items: items}}, pods, mainContainerName, 1)
+		assert.Equal(t, batchopsv1alpha1.ListJobFailed, status.Phase)
+		assert.NotNil(t, status.CompletionTime)
+		assert.Equal(t, int32(3), status.Succeeded)
+		assert.Equal(t, int32(1), status.Failed)
+		failed := status.Items[2]
+		assert.Equal(t, batchopsv1alpha1.ItemFailed, failed.Phase)
+		assert.Equal(t, "c", failed.Item)
+		require.NotNil(t, failed.ExitCode)
+		assert.Equal(t, int32(3), *failed.ExitCode)
+		assert.Equal(t, "Error: boom", failed.Message)
+	})
+
+	t.Run("Keeps Records Of Removed Pods", func(t *testing.T) {
+		job := job.DeepCopy()
+		job.Status.CompletedIndexes = "0-3"
+		job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}}
+		previous := batchopsv1alpha1.ListJobStatus{Items: []batchopsv1alpha1.ItemStatus{
+			{Index: 2, PodName: "test-job-2-abcde", ExitCode: &[]int32{0}[0]},
+		}}
+
+		status := computeListJobStatus(previous, []listJobShard{{Job: job, Items: items}}, nil, mainContainerName, 1)
+		assert.Equal(t, batchopsv1alpha1.ListJobSucceeded, status.Phase)
+		assert.Equal(t, "4/4", status.Progress)
+		assert.Equal(t, "test-job-2-abcde", status.Items[2].PodName)
+	})
+
+	t.Run("Batches", func(t *testing.T) {
+		job := job.DeepCopy()
+		job.Spec.Completions = &[]int32{3}[0]
+		job.Status.CompletedIndexes = "0"
+		job.Status.FailedIndexes = &[]string{"1"}[0]
+		report := &corev1.ContainerStateTerminated{ExitCode: 1, Reason: "Error", Message: `{"failedItems":[1]}`}
+		pods := []corev1.Pod{
+			*itemPod(job, "test-job-0-abcde", 0, corev1.PodSucceeded, &corev1.ContainerStateTerminated{}),
+			*itemPod(job, "test-job-1-abcde", 1, corev1.PodFailed, report),
+			*itemPod(job, "test-job-2-abcde", 2, corev1.PodRunning, nil),
+		}
+		items := []string{"a", "b", "c", "d", "e"}
+
+		status := computeListJobStatus(batchopsv1alpha1.ListJobStatus{}, []listJobShard{{Job: job, Items: items}}, pods, mainContainerName, 2)
+		assert.Equal(t, int32(5), status.Total)
+		assert.Equal(t, "3/5", status.Progress)
+		assert.Equal(t, int32(1), status.Failed)
+		assert.Equal(t, int32(1), status.Running)
+		require.Len(t, status.Items, 5)
+		assert.Equal(t, "test-job-0-abcde", status.Items[1].PodName)
+		assert.Equal(t, batchopsv1alpha1.ItemSucceeded, status.Items[2].Phase)
+		assert.Equal(t, batchopsv1alpha1.ItemFailed, status.Items[3].Phase)
+		assert.Equal(t, "d", status.Items[3].Item)
+		assert.Equal(t, reportedFailureMessage, status.Items[3].Message)
+		assert.Equal(t, batchopsv1alpha1.ItemRunning, status.Items[4].Phase)
+	})
+
+	t.Run("Truncates Large Lists", func(t *testing.T) {
+		job := job.DeepCopy()
+		job.Spec.Completions = &[]int32{maxItemStatuses + 10}[0]
+		job.Status.CompletedIndexes = fmt.Sprintf("0-%d", maxItemStatuses+8)
+
+		status := computeListJobStatus(batchopsv1alpha1.ListJobStatus{}, []listJobShard{{Job: job, Items: nil}}, nil, mainContainerName, 1)
+		assert.True(t, status.ItemsTruncated)
+		require.Len(t, status.Items, maxItemStatuses)
+		assert.Equal(t, int32(maxItemStatuses+9), status.Items[maxItemStatuses-1].Index)
+		assert.Equal(t, batchopsv1alpha1.ItemPending, status.Items[maxItemStatuses-1].Phase)
+	})
+}
+
+func TestReportedFailedItems(t *testing.T) {
+	assert.Equal(t, map[int32]bool{0: true, 3: true}, reportedFailedItems(`{"failedItems":[0,3]}`))
+	assert.Equal(t, map[int32]bool{}, reportedFailedItems(`{"failedItems":[]}`))
+	assert.Nil(t, reportedFailedItems("connection refused"))
+	assert.Nil(t, reportedFailedItems(`{"error":"boom"}`))
+}
+
+func TestParseIndexes(t *testing.T) {
+	assert.Equal(t, map[int32]bool{1: true, 3: true, 4: true, 5: true, 7: true}, parseIndexes("1,3-5,7"))
+	assert.Empty(t, parseIndexes(""))
+}
+
+func itemPod(job *batchv1.Job, name string, index int, phase corev1.PodPhase, terminated *corev1.ContainerStateTerminated) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         job.Namespace,
+			CreationTimestamp: metav1.NewTime(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)),
+			Labels:            map[string]string{listJobLabel: job.Name},
+			Annotations:       map[string]string{batchv1.JobCompletionIndexAnnotation: fmt.Sprint(index)},
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: "batch/v1",
+				Kind:       "Job",
+				Name:       job.Name,
+				UID:        job.UID,
+				Controller: &[]bool{true}[0],
+			}},
+		},
+		Status: corev1.PodStatus{
+			Phase: phase,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:  mainContainerName,
+				State: corev1.ContainerState{Terminated: terminated},
+			}},
+		},
+	}
+}
+
+func TestListJobController_RetryFailedItems(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, batchopsv1alpha1.AddToScheme(scheme))
+	require.NoError(t, corev1.AddToScheme(scheme))
+	require.NoError(t, batchv1.AddToScheme(scheme))
+
+	listJob := &batchopsv1alpha1.ListJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "test-job",
+			Namespace:   "default",
+			Finalizers:  []string{listJobFinalizer},
+			Annotations: map[string]string{batchopsv1alpha1.RetryFailedAnnotation: "true"},
+		},
+		Spec: batchopsv1alpha1.ListJobSpec{
+			ListSourceRef: "test-source",
+			Parallelism:   2,
+			Template: batchopsv1alpha1.JobTemplateSpec{
+				Image:   "busybox",
+				Command: []string{"echo", "$ITEM"},
+				EnvName: "ITEM",
+			},
+		},
+	}
+	listConfigMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-job-list", Namespace: "default"},
+		Data:       map[string]string{"items": "a\nb\nc"},
+	}
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-job",
+			Namespace: "default",
+			Labels:    map[string]string{listJobLabel: "test-job"},
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: batchopsv1alpha1.GroupVersion.String(),
+				Kind:       "ListJob",
+				Name:       "test-job",
+				Controller: &[]bool{true}[0],
+			}},
+		},
+		Spec: batchv1.JobSpec{Completions: &[]int32{3}[0]},
+		Status: batchv1.JobStatus{
+			CompletedIndexes: "1",
+			Conditions:       []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue}},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(listJob, listConfigMap, job).
+		WithStatusSubresource(&batchopsv1alpha1.ListJob{}).
+		Build()
+	recorder := record.NewFakeRecorder(10)
+	reconciler := &ListJobReconciler{Client: fakeClient, Scheme: scheme, Recorder: recorder}
+	ctx := context.Background()
+	key := types.NamespacedName{Name: "test-job", Namespace: "default"}
+
+	_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	var retry batchopsv1alpha1.ListJob
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "test-job-retry-1", Namespace: "default"}, &retry))
+	assert.Empty(t, retry.Spec.StaticList)
+	assert.Equal(t, "test-job-retry-1-items", retry.Spec.ListSourceRef)
+	retryItems, err := readItems(ctx, fakeClient, "default", retry.Spec.ListSourceRef)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "c"}, retryItems)
+	assert.Equal(t, "test-job", retry.Labels[batchopsv1alpha1.RetryOfLabel])
+	assert.Equal(t, "test-job", metav1.GetControllerOf(&retry).Name)
+	assert.Equal(t, listJob.Spec.Template, retry.Spec.Template)
+	assert.Contains(t, <-recorder.Events, "RetryCreated")
+
+	var updated batchopsv1alpha1.ListJob
+	require.NoError(t, fakeClient.Get(ctx, key, &updated))
+	assert.Equal(t, batchopsv1alpha1.ListJobFailed, updated.Status.Phase)
+	assert.Equal(t, []string{"test-job-retry-1"}, updated.Status.Retries)
+	assert.NotContains(t, updated.Annotations, batchopsv1alpha1.RetryFailedAnnotation)
+
+	// A second retry gets its own name
+	updated.Annotations = map[string]string{batchopsv1alpha1.RetryFailedAnnotation: "true"}
+	require.NoError(t, fakeClient.Update(ctx, &updated))
+	_, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+	require.NoError(t, fakeClient.Get(ctx, key, &updated))
+	assert.Equal(t, []string{"test-job-retry-1", "test-job-retry-2"}, updated.Status.Retries)
+}
+
+func TestRetryName(t *testing.T) {
+	assert.Equal(t, "nightly-retry-2", retryName("nightly", 2))
+
+	// The ListJobs of ListCronJobs with the longest names
+	scheduled := strings.Repeat("a", batchopsv1alpha1.MaxListCronJobNameLength) + "-29000000"
+	for n := 1; n <= 12; n++ {
+		name := retryName(scheduled, n)
+		assert.LessOrEqual(t, len(name), batchopsv1alpha1.MaxListJobNameLength)
+		assert.True(t, strings.HasSuffix(name, fmt.Sprintf("-retry-%d", n)), name)
+		assert.Equal(t, name, retryName(scheduled, n))
+	}
+	assert.NotEqual(t, retryName(scheduled, 1), retryName(scheduled+"0", 1))
+}
+
+func TestListJobController_NextRetry(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, batchopsv1alpha1.AddToScheme(scheme))
+
+	listJob := &batchopsv1alpha1.ListJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-job", Namespace: "default", UID: "test-job-uid"},
+		Status:     batchopsv1alpha1.ListJobStatus{Retries: []string{"test-job-retry-1"}},
+	}
+	// A ListJob that merely has the name of the next retry
+	unrelated := &batchopsv1alpha1.ListJob{ObjectMeta: metav1.ObjectMeta{Name: "test-job-retry-2", Namespace: "default"}}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(unrelated).Build()
+	reconciler := &ListJobReconciler{Client: fakeClient, Scheme: scheme}
+	ctx := context.Background()
+
+	retry, created, err := reconciler.nextRetry(ctx, listJob)
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, "test-job-retry-3", retry.Name)
+	assert.Equal(t, "test-job-retry-3-items", retry.Spec.ListSourceRef)
+
+	// A retry created by an earlier attempt that failed to record it is reused
+	require.NoError(t, fakeClient.Create(ctx, retry))
+	again, created, err := reconciler.nextRetry(ctx, listJob)
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, "test-job-retry-3", again.Name)
+}
+
+func TestFailedItemIndexes(t *testing.T) {
+	status := batchopsv1alpha1.ListJobStatus{
+		Failed: 2,
+		Items: []batchopsv1alpha1.ItemStatus{
+			{Index: 0, Phase: batchopsv1alpha1.ItemSucceeded},
+			{Index: 1, Phase: batchopsv1alpha1.ItemFailed},
+			{Index: 2, Phase: batchopsv1alpha1.ItemFailed},
+		},
+	}
+	job := &batchv1.Job{
+		Spec:   batchv1.JobSpec{Completions: &[]int32{4}[0]},
+		Status: batchv1.JobStatus{CompletedIndexes: "0,2"},
+	}
+	failedIndexes := func(shards []listJobShard, batchSize int32) []int32 {
+		indexes, err := failedItemIndexes(status, shards, batchSize)
+		require.NoError(t, err)
+		return indexes
+	}
+	assert.Equal(t, []int32{1, 2}, failedIndexes([]listJobShard{{Job: job}}, 1))
+	assert.Equal(t, []int32{1, 2}, failedIndexes(nil, 1))
+
+	// Truncated records fall back to the batches the Job did not complete
+	status.Failed = 5
+	assert.Equal(t, []int32{1, 3}, failedIndexes([]listJobShard{{Job: job}}, 1))
+	assert.Equal(t, []int32{2, 3, 6, 7}, failedIndexes([]listJobShard{{Job: job}}, 2))
+
+	// and cannot be completed once the Jobs are gone
+	_, err := failedItemIndexes(status, nil, 1)
+	assert.EqualError(t, err, "the status records 2 of 5 failed items and the Jobs are gone")
+}
+
+func TestListJobController_RetryFailedItemsUnknown(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, batchopsv1alpha1.AddToScheme(scheme))
+	require.NoError(t, corev1.AddToScheme(scheme))
+
+	listJob := &batchopsv1alpha1.ListJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "test-job",
+			Namespace:   "default",
+			Annotations: map[string]string{batchopsv1alpha1.RetryFailedAnnotation: "true"},
+		},
+		Spec: batchopsv1alpha1.ListJobSpec{StaticList: []string{"a", "b", "c"}},
+		Status: batchopsv1alpha1.ListJobStatus{
+			Phase:  batchopsv1alpha1.ListJobFailed,
+			Failed: 2,
+			Items:  []batchopsv1alpha1.ItemStatus{{Index: 0, Phase: batchopsv1alpha1.ItemFailed}},
+		},
+	}
+	listConfigMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-job-list", Namespace: "default"},
+		Data:       map[string]string{"items": "a\nb\nc"},
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(listJob, listConfigMap).
+		WithStatusSubresource(&batchopsv1alpha1.ListJob{}).
+		Build()
+	recorder := record.NewFakeRecorder(10)
+	reconciler := &ListJobReconciler{Client: fakeClient, Scheme: scheme, Recorder: recorder}
+	ctx := context.Background()
+
+	// The truncated records are not retried as if they were all failed items
+	require.NoError(t, reconciler.retryFailedItems(ctx, listJob, nil))
+	assert.Contains(t, <-recorder.Events, "RetryFailed")
+	var retries batchopsv1alpha1.ListJobList
+	require.NoError(t, fakeClient.List(ctx, &retries))
+	assert.Len(t, retries.Items, 1)
+
+	var updated batchopsv1alpha1.ListJob
+	require.NoError(t, fakeClient.Get(ctx, client.ObjectKeyFromObject(listJob), &updated))
+	assert.NotContains(t, updated.Annotations, batchopsv1alpha1.RetryFailedAnnotation)
+}
+
+func TestListJobController_Shards(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, batchopsv1alpha1.AddToScheme(scheme))
+	require.NoError(t, corev1.AddToScheme(scheme))
+	require.NoError(t, batchv1.AddToScheme(scheme))
+
+	items := largeList(20000)
+	listJob := &batchopsv1alpha1.ListJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test-job",
+			Namespace:  "default",
+			Finalizers: []string{listJobFinalizer},
+		},
+		Spec: batchopsv1alpha1.ListJobSpec{
+			StaticList:  items,
+			Parallelism: 2,
+			BatchSize:   7,
+			Template: batchopsv1alpha1.JobTemplateSpec{
+				Image:   "busybox",
+				Command: []string{"echo", "$ITEM"},
+				EnvName: "ITEM",
+			},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(listJob).
+		WithStatusSubresource(&batchopsv1alpha1.ListJob{}).
+		Build()
+	reconciler := &ListJobReconciler{Client: fakeClient, Scheme: scheme}
+	ctx := context.Background()
+	key := types.NamespacedName{Name: "test-job", Namespace: "default"}
+
+	_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	var jobs batchv1.JobList
+	require.NoError(t, fakeClient.List(ctx, &jobs, client.MatchingLabels{listJobLabel: "test-job"}))
+	require.Len(t, jobs.Items, 3)
+
+	var updated batchopsv1alpha1.ListJob
+	require.NoError(t, fakeClient.Get(ctx, key, &updated))
+	assert.Equal(t, int32(20000), updated.Status.Total)
+	assert.Equal(t, "0/20000", updated.Status.Progress)
+	assert.Equal(t, []string{"test-job", "test-job-shard-1", "test-job-shard-2"}, updated.Status.JobNames)
+
+	var second batchv1.Job
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "test-job-shard-1", Namespace: "default"}, &second))
+	offset, err := strconv.Atoi(second.Annotations[shardOffsetAnnotation])
+	require.NoError(t, err)
+	assert.Zero(t, offset%7, "shards must not split a batch")
+	assert.Equal(t, "test-job-shard-1-list", second.Spec.Template.Spec.Volumes[0].ConfigMap.Name)
+
+	// A failed batch of the second shard is reported at its list position
+	second.Status.CompletedIndexes = "0"
+	second.Status.FailedIndexes = &[]string{"1"}[0]
+	require.NoError(t, fakeClient.Status().Update(ctx, &second))
+	_, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+	require.NoError(t, fakeClient.Get(ctx, key, &updated))
+	assert.Equal(t, "7/20000", updated.Status.Progress)
+	assert.Equal(t, int32(7), updated.Status.Failed)
+	assert.True(t, updated.Status.ItemsTruncated)
+	require.Len(t, updated.Status.Items, maxItemStatuses)
+	var failed []batchopsv1alpha1.ItemStatus
+	for _, item := range updated.Status.Items {
+		if item.Phase == batchopsv1alpha1.ItemFailed {
+			failed = append(failed, item)
+		}
+	}
+	require.Len(t, failed, 7)
+	assert.Equal(t, int32(offset+7), failed[0].Index)
+	assert.Equal(t, items[offset+7], failed[0].Item)
+}
@@ -0,0 +1,230 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	batchopsv1alpha1 "github.com/matanryngler/parallax/api/v1alpha1"
+)
+
+// retryFailedItems handles the retry-failed annotation of a finished ListJob by
+// creating a ListJob that runs only its failed items. shards may be nil when the
+// Jobs were already removed, the failed items are then taken from the status.
+func (r *ListJobReconciler) retryFailedItems(ctx context.Context, listJob *batchopsv1alpha1.ListJob, shards []listJobShard) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	if listJob.Annotations[batchopsv1alpha1.RetryFailedAnnotation] != "true" {
+		return nil
+	}
+	if !isListJobFinished(listJob.Status) {
+		log.V(1).Info("Retry of failed items requested, waiting for ListJob to finish")
+		return nil
+	}
+
+	// The items of all shards, in list order
+	jobNames := listJob.Status.JobNames
+	if len(jobNames) == 0 {
+		jobNames = []string{listJob.Name}
+	}
+	var items []string
+	for _, jobName := range jobNames {
+		shardItems, err := readItems(ctx, r.Client, listJob.Namespace, fmt.Sprintf("%s-list", jobName))
+		if err != nil {
+			return fmt.Errorf("failed to read items of ListJob: %w", err)
+		}
+		items = append(items, shardItems...)
+	}
+
+	indexes, err := failedItemIndexes(listJob.Status, shards, listJob.Spec.BatchSize)
+	if err != nil {
+		// Retrying the recorded items only would silently drop the others
+		log.Error(err, "Cannot retry failed items")
+		r.Recorder.Event(listJob, corev1.EventTypeWarning, "RetryFailed", fmt.Sprintf("Cannot retry failed items: %v", err))
+		delete(listJob.Annotations, batchopsv1alpha1.RetryFailedAnnotation)
+		return r.Update(ctx, listJob)
+	}
+	var failed []string
+	for _, index := range indexes {
+		if int(index) < len(items) {
+			failed = append(failed, items[index])
+		}
+	}
+
+	if len(failed) == 0 {
+		log.Info("Retry of failed items requested, but no item failed")
+		r.Recorder.Event(listJob, corev1.EventTypeNormal, "RetrySkipped", "No failed items to retry")
+	} else {
+		retry, created, err := r.nextRetry(ctx, listJob)
+		if err != nil {
+			return err
+		}
+		if !created {
+			if err := r.storeRetryItems(ctx, listJob, retry.Spec.ListSourceRef, failed); err != nil {
+				return err
+			}
+			if err := r.Create(ctx, retry); err != nil {
+				return fmt.Errorf("failed to create retry ListJob %s: %w", retry.Name, err)
+			}
+		}
+		log.Info("Created ListJob to retry failed items", "retry", retry.Name, "items", len(failed))
+		r.Recorder.Event(listJob, corev1.EventTypeNormal, "RetryCreated",
+			fmt.Sprintf("Created ListJob %s to retry %d failed items", retry.Name, len(failed)))
+
+		if !slices.Contains(listJob.Status.Retries, retry.Name) {
+			listJob.Status.Retries = append(listJob.Status.Retries, retry.Name)
+			if err := r.Status().Update(ctx, listJob); err != nil {
+				return err
+			}
+		}
+	}
+
+	delete(listJob.Annotations, batchopsv1alpha1.RetryFailedAnnotation)
+	return r.Update(ctx, listJob)
+}
+
+// failedItemIndexes returns the positions of the items that did not succeed.
+// The status records are used unless they were truncated, the Jobs are then
+// consulted for the batches they did not complete. Without Jobs the failed
+// items of truncated records are unknown, which is an error.
+func failedItemIndexes(status batchopsv1alpha1.ListJobStatus, shards []listJobShard, batchSize int32) ([]int32, error) {
+	var indexes []int32
+	for _, item := range status.Items {
+		if item.Phase == batchopsv1alpha1.ItemFailed {
+			indexes = append(indexes, item.Index)
+		}
+	}
+	if int32(len(indexes)) == status.Failed {
+		return indexes, nil
+	}
+	if len(shards) == 0 {
+		return nil, fmt.Errorf("the status records %d of %d failed items and the Jobs are gone", len(indexes), status.Failed)
+	}
+
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	indexes = nil
+	for _, shard := range shards {
+		if shard.Job.Spec.Completions == nil {
+			continue
+		}
+		completed := parseIndexes(shard.Job.Status.CompletedIndexes)
+		for index := int32(0); index < *shard.Job.Spec.Completions; index++ {
+			if completed[index] {
+				continue
+			}
+			for position := index * batchSize; position < (index+1)*batchSize; position++ {
+				indexes = append(indexes, shard.Offset+position)
+			}
+		}
+	}
+	return indexes, nil
+}
+
+// nextRetry returns the retry ListJob to create for listJob, under the first
+// free retry name. created reports a retry of an earlier attempt that was
+// created but not recorded in the status, which is used as is.
+func (r *ListJobReconciler) nextRetry(ctx context.Context, listJob *batchopsv1alpha1.ListJob) (*batchopsv1alpha1.ListJob, bool, error) {
+	for n := len(listJob.Status.Retries) + 1; ; n++ {
+		name := retryName(listJob.Name, n)
+		if slices.Contains(listJob.Status.Retries, name) {
+			continue
+		}
+		var existing batchopsv1alpha1.ListJob
+		err := r.Get(ctx, client.ObjectKey{Name: name, Namespace: listJob.Namespace}, &existing)
+		if apierrors.IsNotFound(err) {
+			retry, err := retryListJob(listJob, name, r.Scheme)
+			return retry, false, err
+		}
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to get ListJob %s: %w", name, err)
+		}
+		if metav1.IsControlledBy(&existing, listJob) {
+			return &existing, true, nil
+		}
+	}
+}
+
+// retryName returns the name of the n-th retry of a ListJob. Names that would
+// exceed MaxListJobNameLength are truncated and keep a hash of the ListJob
+// name, as jobShardName does.
+func retryName(name string, n int) string {
+	full := fmt.Sprintf("%s-retry-%d", name, n)
+	if len(full) <= batchopsv1alpha1.MaxListJobNameLength {
+		return full
+	}
+	sum := sha256.Sum256([]byte(name))
+	suffix := fmt.Sprintf("-%s-retry-%d", hex.EncodeToString(sum[:4]), n)
+	return strings.TrimRight(name[:batchopsv1alpha1.MaxListJobNameLength-len(suffix)], "-.") + suffix
+}
+
+// storeRetryItems stores the failed items a retry runs in the ConfigMaps of a
+// list named name, owned by listJob like the retry itself. Retries of large
+// lists would not fit a static list in the ListJob spec.
+func (r *ListJobReconciler) storeRetryItems(ctx context.Context, listJob *batchopsv1alpha1.ListJob, name string, items []string) error {
+	configMaps, err := buildListConfigMaps(name, listJob.Namespace, items, batchopsv1alpha1.GzipCompression)
+	if err != nil {
+		return fmt.Errorf("failed to encode failed items: %w", err)
+	}
+	// The first ConfigMap, which carries the shard count, is written last
+	for i := len(configMaps) - 1; i >= 0; i-- {
+		if err := ctrl.SetControllerReference(listJob, configMaps[i], r.Scheme); err != nil {
+			return err
+		}
+		if err := applyListConfigMap(ctx, r.Client, configMaps[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// retryListJob builds the ListJob named name that runs the failed items of
+// listJob, read from the list stored by storeRetryItems. It is owned by
+// listJob, so it is removed along with it.
+func retryListJob(listJob *batchopsv1alpha1.ListJob, name string, scheme *runtime.Scheme) (*batchopsv1alpha1.ListJob, error) {
+	spec := *listJob.Spec.DeepCopy()
+	spec.ListSourceRef = name + "-items"
+	spec.StaticList = nil
+
+	retry := &batchopsv1alpha1.ListJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: listJob.Namespace,
+			Labels: map[string]string{
+				batchopsv1alpha1.RetryOfLabel: listJob.Name,
+			},
+		},
+		Spec: spec,
+	}
+	if err := ctrl.SetControllerReference(listJob, retry, scheme); err != nil {
+		return nil, err
+	}
+	return retry, nil
+}
@@ -0,0 +1,380 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	batchopsv1alpha1 "github.com/matanryngler/parallax/api/v1alpha1"
+)
+
+const (
+	// listJobLabel is set on the Job and on every pod of a ListJob.
+	listJobLabel = "listjob"
+	// maxItemStatuses caps the per-index records kept in the ListJob status so
+	// large lists do not push the object past the etcd size limit.
+	maxItemStatuses = 1000
+	// maxItemStatusLength caps the item value and message kept per record.
+	maxItemStatusLength = 256
+	// maxCompletionsPerJob is the completion limit of an Indexed Job. Longer
+	// lists are split across several Jobs.
+	maxCompletionsPerJob = 100000
+	// shardOffsetAnnotation is set on every Job of a ListJob to the position
+	// of its first item in the full list.
+	shardOffsetAnnotation = "batchops.io/shard-offset"
+)
+
+// listJobShard is one Job of a ListJob with the items it runs. Offset is the
+// position of its first item in the full list.
+type listJobShard struct {
+	Job    *batchv1.Job
+	Items  []string
+	Offset int32
+}
+
+// computeListJobStatus derives the ListJob status from its Jobs and the pods of
+// those Jobs, shards ordered by offset. Every completion index runs batchSize
+// contiguous items. Records of the previous status are used for items whose
+// pods were already removed.
+func computeListJobStatus(previous batchopsv1alpha1.ListJobStatus, shards []listJobShard, pods []corev1.Pod, container string, batchSize int32) batchopsv1alpha1.ListJobStatus {
+	status := batchopsv1alpha1.ListJobStatus{
+		Retries:    previous.Retries,
+		Conditions: slices.Clone(previous.Conditions),
+	}
+	if batchSize < 1 {
+		batchSize = 1
+	}
+
+	previousItems := map[int32]batchopsv1alpha1.ItemStatus{}
+	for _, item := range previous.Items {
+		previousItems[item.Index] = item
+	}
+
+	var records []batchopsv1alpha1.ItemStatus
+	complete, failed := 0, 0
+	for i, shard := range shards {
+		job := shard.Job
+		if i == 0 {
+			status.JobName = job.Name
+		}
+		if len(shards) > 1 {
+			status.JobNames = append(status.JobNames, job.Name)
+		}
+		if job.Status.StartTime != nil && (status.StartTime == nil || job.Status.StartTime.Before(status.StartTime)) {
+			status.StartTime = job.Status.StartTime
+		}
+
+		var finishedAt *metav1.Time
+		if completeAt := jobConditionTime(job, batchv1.JobComplete); completeAt != nil {
+			complete++
+			finishedAt = job.Status.CompletionTime
+			if finishedAt == nil {
+				finishedAt = completeAt
+			}
+		} else if failedAt := jobConditionTime(job, batchv1.JobFailed); failedAt != nil {
+			failed++
+			finishedAt = failedAt
+		}
+		if finishedAt != nil && (status.CompletionTime == nil || status.CompletionTime.Before(finishedAt)) {
+			status.CompletionTime = finishedAt
+		}
+
+		shardRecords := shardItemStatuses(previousItems, shard, pods, container, batchSize)
+		status.Total += int32(len(shardRecords))
+		records = append(records, shardRecords...)
+	}
+
+	for _, record := range records {
+		switch record.Phase {
+		case batchopsv1alpha1.ItemPending:
+			status.Pending++
+		case batchopsv1alpha1.ItemRunning:
+			status.Running++
+		case batchopsv1alpha1.ItemSucceeded:
+			status.Succeeded++
+		case batchopsv1alpha1.ItemFailed:
+			status.Failed++
+		}
+	}
+
+	status.Progress = fmt.Sprintf("%d/%d", status.Succeeded, status.Total)
+	switch {
+	case len(shards) > 0 && complete == len(shards):
+		status.Phase = batchopsv1alpha1.ListJobSucceeded
+	case len(shards) > 0 && complete+failed == len(shards):
+		status.Phase = batchopsv1alpha1.ListJobFailed
+	case status.Running > 0 || status.Succeeded > 0 || status.Failed > 0:
+		status.Phase = batchopsv1alpha1.ListJobRunning
+		status.CompletionTime = nil
+	default:
+		status.Phase = batchopsv1alpha1.ListJobPending
+		status.CompletionTime = nil
+	}
+
+	if len(records) > maxItemStatuses {
+		// Keep failed items first, they are the ones worth looking at
+		sort.SliceStable(records, func(i, j int) bool {
+			return itemPhaseRank[records[i].Phase] < itemPhaseRank[records[j].Phase]
+		})
+		records = records[:maxItemStatuses]
+		sort.Slice(records, func(i, j int) bool { return records[i].Index < records[j].Index })
+		status.ItemsTruncated = true
+	}
+	status.Items = records
+
+	return status
+}
+
+// shardItemStatuses returns the records of the items run by a single Job.
+func shardItemStatuses(previousItems map[int32]batchopsv1alpha1.ItemStatus, shard listJobShard, pods []corev1.Pod, container string, batchSize int32) []batchopsv1alpha1.ItemStatus {
+	job := shard.Job
+	completions := batchCompletions(len(shard.Items), batchSize)
+	if job.Spec.Completions != nil {
+		completions = *job.Spec.Completions
+	}
+	total := int32(len(shard.Items))
+	if total == 0 {
+		total = completions * batchSize
+	}
+
+	completed := parseIndexes(job.Status.CompletedIndexes)
+	failed := map[int32]bool{}
+	if job.Status.FailedIndexes != nil {
+		failed = parseIndexes(*job.Status.FailedIndexes)
+	}
+	jobFailed := jobConditionTime(job, batchv1.JobFailed)
+	latest := latestPodPerIndex(job, pods)
+
+	records := make([]batchopsv1alpha1.ItemStatus, 0, total)
+	for index := int32(0); index < completions; index++ {
+		// The outcome of the pod, shared by all items of the batch
+		outcome := batchopsv1alpha1.ItemStatus{Phase: batchopsv1alpha1.ItemPending}
+		var reported map[int32]bool
+		pod, hasPod := latest[index]
+		if hasPod {
+			outcome.PodName = pod.Name
+			switch pod.Status.Phase {
+			case corev1.PodRunning:
+				outcome.Phase = batchopsv1alpha1.ItemRunning
+			case corev1.PodSucceeded:
+				outcome.Phase = batchopsv1alpha1.ItemSucceeded
+			}
+			if terminated := containerTermination(pod, container); terminated != nil {
+				exitCode := terminated.ExitCode
+				outcome.ExitCode = &exitCode
+				reported = reportedFailedItems(terminated.Message)
+				if terminated.ExitCode != 0 {
+					if reported != nil {
+						outcome.Message = terminated.Reason
+					} else {
+						outcome.Message = truncate(terminationMessage(terminated), maxItemStatusLength)
+					}
+				}
+			}
+		}
+		finished := completed[index] || failed[index] || jobFailed != nil
+
+		first := index * batchSize
+		for position := first; position < first+batchSize && position < total; position++ {
+			record := outcome
+			record.Index = shard.Offset + position
+			if int(position) < len(shard.Items) {
+				record.Item = truncate(shard.Items[position], maxItemStatusLength)
+			}
+			prev, hasPrev := previousItems[record.Index]
+			if !hasPod && hasPrev {
+				record.PodName = prev.PodName
+				record.ExitCode = prev.ExitCode
+				record.Message = prev.Message
+			}
+
+			switch {
+			case !finished:
+			case reported != nil:
+				record.Phase = batchopsv1alpha1.ItemSucceeded
+				if reported[position-first] {
+					record.Phase = batchopsv1alpha1.ItemFailed
+					record.Message = reportedFailureMessage
+				}
+			case !hasPod && hasPrev && (prev.Phase == batchopsv1alpha1.ItemSucceeded || prev.Phase == batchopsv1alpha1.ItemFailed):
+				record.Phase = prev.Phase
+			case completed[index]:
+				record.Phase = batchopsv1alpha1.ItemSucceeded
+			default:
+				record.Phase = batchopsv1alpha1.ItemFailed
+			}
+			if record.Phase == batchopsv1alpha1.ItemSucceeded {
+				record.Message = ""
+			}
+			records = append(records, record)
+		}
+	}
+	return records
+}
+
+// reportedFailureMessage is set on items the workload reported as failed.
+const reportedFailureMessage = "Reported as failed by the workload"
+
+// itemReport is the termination message a workload may write to report the
+// outcome of single items of its batch, e.g. {"failedItems":[0,3]}. Offsets
+// are relative to the first item of the batch, all other items succeeded.
+type itemReport struct {
+	FailedItems []int32 `json:"failedItems"`
+}
+
+// reportedFailedItems parses an item report out of a termination message. It
+// returns nil when the message is not a report.
+func reportedFailedItems(message string) map[int32]bool {
+	var report itemReport
+	decoder := json.NewDecoder(strings.NewReader(message))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&report); err != nil || report.FailedItems == nil {
+		return nil
+	}
+	reported := make(map[int32]bool, len(report.FailedItems))
+	for _, offset := range report.FailedItems {
+		reported[offset] = true
+	}
+	return reported
+}
+
+// itemPhaseRank orders item records by how much attention they need when the
+// status has to be truncated.
+var itemPhaseRank = map[batchopsv1alpha1.ItemPhase]int{
+	batchopsv1alpha1.ItemFailed:    0,
+	batchopsv1alpha1.ItemRunning:   1,
+	batchopsv1alpha1.ItemPending:   2,
+	batchopsv1alpha1.ItemSucceeded: 3,
+}
+
+// isListJobFinished reports whether the status describes a terminal ListJob.
+func isListJobFinished(status batchopsv1alpha1.ListJobStatus) bool {
+	return status.Phase == batchopsv1alpha1.ListJobSucceeded || status.Phase == batchopsv1alpha1.ListJobFailed
+}
+
+// parseIndexes parses the compressed index format used by Job status,
+// e.g. "1,3-5,7".
+func parseIndexes(value string) map[int32]bool {
+	indexes := map[int32]bool{}
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		first, last, isRange := strings.Cut(part, "-")
+		start, err := strconv.ParseInt(first, 10, 32)
+		if err != nil {
+			continue
+		}
+		end := start
+		if isRange {
+			if end, err = strconv.ParseInt(last, 10, 32); err != nil {
+				continue
+			}
+		}
+		for i := start; i <= end; i++ {
+			indexes[int32(i)] = true
+		}
+	}
+	return indexes
+}
+
+// jobConditionTime returns when the given condition became true, or nil.
+func jobConditionTime(job *batchv1.Job, conditionType batchv1.JobConditionType) *metav1.Time {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == conditionType && condition.Status == corev1.ConditionTrue {
+			transition := condition.LastTransitionTime
+			return &transition
+		}
+	}
+	return nil
+}
+
+// latestPodPerIndex returns the most recently created pod of the Job for every
+// completion index.
+func latestPodPerIndex(job *batchv1.Job, pods []corev1.Pod) map[int32]*corev1.Pod {
+	latest := map[int32]*corev1.Pod{}
+	for i := range pods {
+		pod := &pods[i]
+		if !metav1.IsControlledBy(pod, job) {
+			continue
+		}
+		value, ok := pod.Annotations[batchv1.JobCompletionIndexAnnotation]
+		if !ok {
+			value, ok = pod.Labels[batchv1.JobCompletionIndexAnnotation]
+		}
+		if !ok {
+			continue
+		}
+		index, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			continue
+		}
+		current, ok := latest[int32(index)]
+		if !ok || current.CreationTimestamp.Before(&pod.CreationTimestamp) ||
+			(current.CreationTimestamp.Equal(&pod.CreationTimestamp) && current.Name < pod.Name) {
+			latest[int32(index)] = pod
+		}
+	}
+	return latest
+}
+
+// containerTermination returns the termination state of the named container.
+func containerTermination(pod *corev1.Pod, container string) *corev1.ContainerStateTerminated {
+	for i := range pod.Status.ContainerStatuses {
+		status := &pod.Status.ContainerStatuses[i]
+		if status.Name != container {
+			continue
+		}
+		if status.State.Terminated != nil {
+			return status.State.Terminated
+		}
+		return status.LastTerminationState.Terminated
+	}
+	return nil
+}
+
+func terminationMessage(terminated *corev1.ContainerStateTerminated) string {
+	message := strings.TrimSpace(terminated.Message)
+	switch {
+	case terminated.Reason != "" && message != "":
+		return terminated.Reason + ": " + message
+	case message != "":
+		return message
+	}
+	return terminated.Reason
+}
+
+func truncate(value string, limit int) string {
+	if len(value) <= limit {
+		return value
+	}
+	for limit > 0 && !utf8.RuneStart(value[limit]) {
+		limit--
+	}
+	return value[:limit] + "..."
+}
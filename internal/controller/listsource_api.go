@@ -0,0 +1,659 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/jsonpath"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	batchopsv1alpha1 "github.com/matanryngler/parallax/api/v1alpha1"
+)
+
+func (r *ListSourceReconciler) getItemsFromAPI(ctx context.Context, listSource *batchopsv1alpha1.ListSource) ([]string, error) {
+	config := listSource.Spec.API
+	resourceID := fmt.Sprintf("ListSource/%s.%s", listSource.Name, listSource.Namespace)
+	log := log.FromContext(ctx).WithValues(
+		"resource", resourceID,
+		"type", "api",
+		"url", config.URL,
+		"uid", listSource.UID,
+	)
+	log.Info("Starting API request to fetch items")
+
+	header, err := r.apiRequestHeader(ctx, listSource)
+	if err != nil {
+		return nil, err
+	}
+
+	log.V(1).Info("Extracting items using JSONPath", "expression", config.JSONPath)
+	jp := jsonpath.New("items")
+	if err := jp.Parse(fmt.Sprintf("{%s}", config.JSONPath)); err != nil {
+		log.Error(err, "Invalid JSONPath expression")
+		return nil, fmt.Errorf("failed to parse JSONPath expression: %w", err)
+	}
+
+	body, err := r.apiRequestBody(ctx, listSource)
+	if err != nil {
+		return nil, err
+	}
+
+	pager, err := newAPIPager(config.URL, config.Pagination)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := r.apiHTTPClient(ctx, listSource)
+	if err != nil {
+		log.Error(err, "Failed to configure TLS for API requests")
+		return nil, fmt.Errorf("failed to configure TLS: %w", err)
+	}
+	policy := newFetchPolicy(listSource.Spec.FetchPolicy)
+	// Bounds the requests for OAuth2 tokens as well
+	client.Timeout = policy.timeout
+	if config.TLS != nil && config.TLS.InsecureSkipVerify {
+		log.Info("TLS certificate verification is disabled for API requests")
+		r.Recorder.Event(listSource, corev1.EventTypeWarning, "InsecureSkipVerify",
+			fmt.Sprintf("TLS certificate verification is disabled, the identity of %s is not checked", config.URL))
+	}
+
+	var items []string
+	fetched := map[string]bool{}
+	page := pager.first()
+	for n := 1; page != nil; n++ {
+		if n > pager.maxPages {
+			return nil, fmt.Errorf("API list has more than %d pages, raise pagination.maxPages to fetch it", pager.maxPages)
+		}
+		if fetched[page.key()] {
+			return nil, fmt.Errorf("API pagination returned page %s twice", page.key())
+		}
+		fetched[page.key()] = true
+
+		pageURL, pageBody, err := apiPageRequest(config, page, body)
+		if err != nil {
+			return nil, err
+		}
+		request := apiRequest{method: apiMethod(config), url: pageURL, header: header, body: pageBody}
+		var respBody []byte
+		var respHeader http.Header
+		err = policy.do(ctx, policy.retryableAPIError, func(ctx context.Context) error {
+			var err error
+			respBody, respHeader, err = r.fetchAuthorizedAPIPage(ctx, listSource, client, request)
+			return err
+		})
+		if config.GraphQL != nil {
+			// GraphQL servers report failed queries in the body, often with status 200
+			var statusErr *apiStatusError
+			if errors.As(err, &statusErr) {
+				respBody = statusErr.body
+			}
+			if gqlErr := graphQLErrors(respBody); gqlErr != nil {
+				log.Error(gqlErr, "GraphQL query failed", "page", n)
+				return nil, gqlErr
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var data interface{}
+		if err := json.Unmarshal(respBody, &data); err != nil {
+			log.Error(err, "Failed to parse API response as JSON", "page", n)
+			return nil, fmt.Errorf("failed to parse response as JSON: %w", err)
+		}
+
+		pageItems, err := extractAPIItems(jp, data, listSource.Spec.ItemFormat)
+		if err != nil {
+			log.Error(err, "Failed to extract items from API response", "page", n)
+			return nil, err
+		}
+		items = append(items, pageItems...)
+		log.V(1).Info("Fetched page of API list", "page", n, "page_items", len(pageItems))
+
+		if page, err = pager.next(page, respHeader, data, len(pageItems)); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(items) == 0 {
+		log.Error(nil, "JSONPath expression returned no results")
+		return nil, fmt.Errorf("JSONPath expression returned no results")
+	}
+
+	log.Info("Successfully processed API response", "items_found", len(items), "pages", len(fetched))
+	return items, nil
+}
+
+// apiRequestHeader returns the headers sent with every request of an API
+// list, including the credentials.
+func (r *ListSourceReconciler) apiRequestHeader(ctx context.Context, listSource *batchopsv1alpha1.ListSource) (http.Header, error) {
+	log := log.FromContext(ctx)
+	config := listSource.Spec.API
+
+	header := http.Header{}
+	for k, v := range config.Headers {
+		header.Add(k, v)
+		log.V(1).Info("Added request header", "header", k)
+	}
+
+	if config.Auth == nil {
+		return header, nil
+	}
+
+	log.V(1).Info("Setting up authentication for API request", "auth_type", config.Auth.Type)
+	secret, err := r.getSecret(ctx, listSource.Namespace, config.Auth.SecretRef)
+	if err != nil {
+		log.Error(err, "Failed to retrieve authentication secret")
+		return nil, fmt.Errorf("failed to get auth secret: %w", err)
+	}
+
+	switch config.Auth.Type {
+	case batchopsv1alpha1.BasicAuth:
+		username := secret[config.Auth.UsernameKey]
+		password := secret[config.Auth.PasswordKey]
+		header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(username+":"+password)))
+		log.V(1).Info("Configured basic auth for request", "username", username)
+	case batchopsv1alpha1.BearerAuth:
+		token := secret[config.Auth.SecretRef.Key]
+		header.Set("Authorization", "Bearer "+token)
+		log.V(1).Info("Configured bearer token authentication for request")
+	case batchopsv1alpha1.OAuth2Auth:
+		// Every request carries an access token, see fetchAuthorizedAPIPage
+		return header, nil
+	default:
+		log.Error(nil, "Unsupported authentication type specified", "auth_type", config.Auth.Type)
+		return nil, fmt.Errorf("unsupported auth type: %s", config.Auth.Type)
+	}
+	return header, nil
+}
+
+// apiRequestBody returns the body configured for the requests of an API
+// list, nil when there is none or the body is a GraphQL query.
+func (r *ListSourceReconciler) apiRequestBody(ctx context.Context, listSource *batchopsv1alpha1.ListSource) ([]byte, error) {
+	config := listSource.Spec.API
+	switch {
+	case config.Body != "":
+		return []byte(config.Body), nil
+	case config.BodyFrom != nil:
+		secret, err := r.getSecret(ctx, listSource.Namespace, *config.BodyFrom)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get request body secret: %w", err)
+		}
+		body, ok := secret[config.BodyFrom.Key]
+		if !ok {
+			return nil, fmt.Errorf("request body secret %s has no key %s", config.BodyFrom.Name, config.BodyFrom.Key)
+		}
+		return []byte(body), nil
+	}
+	return nil, nil
+}
+
+// apiMethod returns the HTTP method of the requests of an API list.
+func apiMethod(config *batchopsv1alpha1.APIConfig) string {
+	switch {
+	case config.Method != "":
+		return config.Method
+	case config.Body != "" || config.BodyFrom != nil || config.GraphQL != nil:
+		return http.MethodPost
+	default:
+		return http.MethodGet
+	}
+}
+
+// apiPageRequest returns the URL and body of the request for page. The
+// pagination parameters are GraphQL variables of GraphQL queries and query
+// parameters otherwise.
+func apiPageRequest(config *batchopsv1alpha1.APIConfig, page *apiPage, body []byte) (string, []byte, error) {
+	if config.GraphQL != nil {
+		body, err := graphQLRequestBody(config.GraphQL, page.params)
+		return page.url, body, err
+	}
+	if len(page.params) == 0 {
+		return page.url, body, nil
+	}
+
+	u, err := url.Parse(page.url)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to parse API URL: %w", err)
+	}
+	query := u.Query()
+	for name, value := range page.params {
+		query.Set(name, fmt.Sprintf("%v", value))
+	}
+	u.RawQuery = query.Encode()
+	return u.String(), body, nil
+}
+
+// graphQLRequestBody encodes a GraphQL query with its variables and the
+// pagination parameters.
+func graphQLRequestBody(request *batchopsv1alpha1.GraphQLRequest, params map[string]interface{}) ([]byte, error) {
+	variables := map[string]interface{}{}
+	if request.Variables != nil && len(request.Variables.Raw) > 0 {
+		if err := json.Unmarshal(request.Variables.Raw, &variables); err != nil {
+			return nil, fmt.Errorf("GraphQL variables must be a JSON object: %w", err)
+		}
+	}
+	for name, value := range params {
+		variables[name] = value
+	}
+
+	payload := map[string]interface{}{"query": request.Query}
+	if len(variables) > 0 {
+		payload["variables"] = variables
+	}
+	if request.OperationName != "" {
+		payload["operationName"] = request.OperationName
+	}
+	return json.Marshal(payload)
+}
+
+// graphQLErrors returns the errors array of a GraphQL response as an error,
+// nil when the response has none.
+func graphQLErrors(body []byte) error {
+	var response struct {
+		Errors []struct {
+			Message string        `json:"message"`
+			Path    []interface{} `json:"path,omitempty"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil || len(response.Errors) == 0 {
+		return nil
+	}
+
+	messages := make([]string, 0, len(response.Errors))
+	for _, gqlErr := range response.Errors {
+		message := gqlErr.Message
+		if len(gqlErr.Path) > 0 {
+			path := make([]string, 0, len(gqlErr.Path))
+			for _, element := range gqlErr.Path {
+				path = append(path, fmt.Sprintf("%v", element))
+			}
+			message = fmt.Sprintf("%s (at %s)", message, strings.Join(path, "."))
+		}
+		messages = append(messages, message)
+	}
+	return fmt.Errorf("GraphQL query returned errors: %s", strings.Join(messages, "; "))
+}
+
+// apiStatusError is returned for a response whose status is not 200 OK.
+type apiStatusError struct {
+	statusCode int
+	body       []byte
+	// retryAfter is the delay asked for by the Retry-After header
+	retryAfter time.Duration
+}
+
+func (e *apiStatusError) Error() string {
+	return fmt.Sprintf("API request failed with status %d", e.statusCode)
+}
+
+// apiRequest is one HTTP request of an API list.
+type apiRequest struct {
+	method string
+	url    string
+	header http.Header
+	body   []byte
+}
+
+// fetchAuthorizedAPIPage fetches a page, with an access token when the API
+// source uses oauth2 auth. When the API rejects the token, the cached token is
+// dropped and the page requested once more with a new one.
+func (r *ListSourceReconciler) fetchAuthorizedAPIPage(ctx context.Context, listSource *batchopsv1alpha1.ListSource, client *http.Client, request apiRequest) ([]byte, http.Header, error) {
+	tokens, err := r.apiTokenSource(ctx, listSource, client)
+	if err != nil {
+		return nil, nil, err
+	}
+	if tokens == nil {
+		return fetchAPIPage(ctx, client, request)
+	}
+
+	authorized := request
+	if authorized.header, err = authorizeWithToken(request.header, tokens); err != nil {
+		return nil, nil, err
+	}
+	body, header, err := fetchAPIPage(ctx, client, authorized)
+	var statusErr *apiStatusError
+	if !errors.As(err, &statusErr) || statusErr.statusCode != http.StatusUnauthorized {
+		return body, header, err
+	}
+
+	log.FromContext(ctx).Info("API rejected the OAuth2 token, requesting a new one")
+	r.tokens.forget(types.NamespacedName{Name: listSource.Name, Namespace: listSource.Namespace})
+	if tokens, err = r.apiTokenSource(ctx, listSource, client); err != nil {
+		return nil, nil, err
+	}
+	if authorized.header, err = authorizeWithToken(request.header, tokens); err != nil {
+		return nil, nil, err
+	}
+	return fetchAPIPage(ctx, client, authorized)
+}
+
+// fetchAPIPage performs the request of one page and returns the body and
+// headers of the response.
+func fetchAPIPage(ctx context.Context, client *http.Client, request apiRequest) ([]byte, http.Header, error) {
+	log := log.FromContext(ctx).WithValues("page_url", request.url, "method", request.method)
+
+	var reqBody io.Reader
+	if request.body != nil {
+		reqBody = bytes.NewReader(request.body)
+	}
+	req, err := http.NewRequestWithContext(ctx, request.method, request.url, reqBody)
+	if err != nil {
+		log.Error(err, "Failed to create HTTP request")
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header = request.header.Clone()
+	if request.body != nil && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		log.Error(err, "API request failed")
+		return nil, nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.Error(err, "Failed to read API response body")
+		return nil, nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		log.Error(nil, "API returned non-200 status code",
+			"status_code", resp.StatusCode,
+			"status", resp.Status,
+		)
+		return nil, nil, &apiStatusError{
+			statusCode: resp.StatusCode,
+			body:       respBody,
+			retryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		}
+	}
+
+	// Log the response body for debugging
+	log.V(1).Info("Received API response", "body", string(respBody))
+	return respBody, resp.Header, nil
+}
+
+// extractAPIItems evaluates the JSONPath of an API list against one response.
+func extractAPIItems(jp *jsonpath.JSONPath, data interface{}, format batchopsv1alpha1.ItemFormat) ([]string, error) {
+	values, err := jp.FindResults(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate JSONPath: %w", err)
+	}
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	// Convert results to []string
+	var items []string
+	for _, value := range values[0] {
+		if format == batchopsv1alpha1.JSONItemFormat {
+			encoded, err := encodeJSONItems(value.Interface())
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, encoded...)
+			continue
+		}
+		switch v := value.Interface().(type) {
+		case string:
+			items = append(items, v)
+		case []interface{}:
+			for _, item := range v {
+				if str, ok := item.(string); ok {
+					items = append(items, str)
+				} else {
+					items = append(items, fmt.Sprintf("%v", item))
+				}
+			}
+		default:
+			items = append(items, fmt.Sprintf("%v", v))
+		}
+	}
+	return items, nil
+}
+
+// encodeJSONItems renders a JSONPath result as compact JSON items. Arrays are
+// flattened into one item per element, like in text mode.
+func encodeJSONItems(value interface{}) ([]string, error) {
+	values := []interface{}{value}
+	if arr, ok := value.([]interface{}); ok {
+		values = arr
+	}
+
+	items := make([]string, 0, len(values))
+	for _, v := range values {
+		var buf bytes.Buffer
+		encoder := json.NewEncoder(&buf)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(v); err != nil {
+			return nil, fmt.Errorf("failed to encode item as JSON: %w", err)
+		}
+		items = append(items, strings.TrimSuffix(buf.String(), "\n"))
+	}
+	return items, nil
+}
+
+// apiPage is one request of an API list: its URL and the pagination
+// parameters sent with it, as query parameters or GraphQL variables.
+type apiPage struct {
+	url    string
+	params map[string]interface{}
+}
+
+// key identifies the page, to notice an API that returns the same page twice.
+func (p *apiPage) key() string {
+	return fmt.Sprintf("%s %v", p.url, p.params)
+}
+
+// apiPager computes the requests of every page of an API list.
+type apiPager struct {
+	pagination *batchopsv1alpha1.APIPagination
+	base       string
+	cursor     *jsonpath.JSONPath
+	maxPages   int
+	// offset is the number of items read so far, page the number of the
+	// page last requested.
+	offset int
+	page   int32
+}
+
+func newAPIPager(baseURL string, pagination *batchopsv1alpha1.APIPagination) (*apiPager, error) {
+	pager := &apiPager{pagination: pagination, base: baseURL, maxPages: batchopsv1alpha1.DefaultAPIMaxPages}
+	if pagination == nil {
+		return pager, nil
+	}
+	if pagination.MaxPages > 0 {
+		pager.maxPages = int(pagination.MaxPages)
+	}
+
+	switch pagination.Type {
+	case batchopsv1alpha1.CursorPagination:
+		if pagination.CursorPath == "" {
+			return nil, fmt.Errorf("cursor pagination requires cursorPath")
+		}
+		pager.cursor = jsonpath.New("cursor").AllowMissingKeys(true)
+		if err := pager.cursor.Parse(fmt.Sprintf("{%s}", pagination.CursorPath)); err != nil {
+			return nil, fmt.Errorf("failed to parse cursor JSONPath expression: %w", err)
+		}
+	case batchopsv1alpha1.OffsetPagination:
+		if pagination.Limit < 1 {
+			return nil, fmt.Errorf("offset pagination requires limit")
+		}
+	case batchopsv1alpha1.PagePagination:
+		pager.page = 1
+		if pagination.StartPage != nil {
+			pager.page = *pagination.StartPage
+		}
+	case batchopsv1alpha1.LinkPagination:
+	default:
+		return nil, fmt.Errorf("unsupported pagination type: %s", pagination.Type)
+	}
+	return pager, nil
+}
+
+// first returns the first page.
+func (p *apiPager) first() *apiPage {
+	if p.pagination == nil {
+		return &apiPage{url: p.base}
+	}
+	switch p.pagination.Type {
+	case batchopsv1alpha1.OffsetPagination:
+		return p.offsetPage()
+	case batchopsv1alpha1.PagePagination:
+		return p.numberedPage()
+	default:
+		return &apiPage{url: p.base}
+	}
+}
+
+// next returns the page following page, given its response and the number
+// of items it held, or nil when it was the last page.
+func (p *apiPager) next(page *apiPage, header http.Header, data interface{}, pageItems int) (*apiPage, error) {
+	if p.pagination == nil {
+		return nil, nil
+	}
+
+	switch p.pagination.Type {
+	case batchopsv1alpha1.CursorPagination:
+		values, err := p.cursor.FindResults(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate cursor JSONPath: %w", err)
+		}
+		if len(values) == 0 || len(values[0]) == 0 || values[0][0].Interface() == nil {
+			return nil, nil
+		}
+		cursor := fmt.Sprintf("%v", values[0][0].Interface())
+		if cursor == "" {
+			return nil, nil
+		}
+		if p.pagination.CursorParam != "" {
+			return &apiPage{url: p.base, params: map[string]interface{}{p.pagination.CursorParam: cursor}}, nil
+		}
+		next, err := resolveURL(page.url, cursor)
+		if err != nil {
+			return nil, err
+		}
+		return &apiPage{url: next}, nil
+
+	case batchopsv1alpha1.LinkPagination:
+		link := nextLink(header.Values("Link"))
+		if link == "" {
+			return nil, nil
+		}
+		next, err := resolveURL(page.url, link)
+		if err != nil {
+			return nil, err
+		}
+		return &apiPage{url: next}, nil
+
+	case batchopsv1alpha1.OffsetPagination:
+		if pageItems < int(p.pagination.Limit) {
+			return nil, nil
+		}
+		p.offset += pageItems
+		return p.offsetPage(), nil
+
+	case batchopsv1alpha1.PagePagination:
+		if pageItems == 0 || pageItems < int(p.pagination.Limit) {
+			return nil, nil
+		}
+		p.page++
+		return p.numberedPage(), nil
+	}
+	return nil, nil
+}
+
+func (p *apiPager) offsetPage() *apiPage {
+	return &apiPage{url: p.base, params: map[string]interface{}{
+		paramOrDefault(p.pagination.OffsetParam, "offset"): p.offset,
+		paramOrDefault(p.pagination.LimitParam, "limit"):   p.pagination.Limit,
+	}}
+}
+
+func (p *apiPager) numberedPage() *apiPage {
+	params := map[string]interface{}{paramOrDefault(p.pagination.PageParam, "page"): p.page}
+	if p.pagination.Limit > 0 {
+		params[paramOrDefault(p.pagination.LimitParam, "limit")] = p.pagination.Limit
+	}
+	return &apiPage{url: p.base, params: params}
+}
+
+func paramOrDefault(param, defaultParam string) string {
+	if param == "" {
+		return defaultParam
+	}
+	return param
+}
+
+// resolveURL resolves a next-page reference against the URL of the current page.
+func resolveURL(pageURL, ref string) (string, error) {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse page URL: %w", err)
+	}
+	next, err := base.Parse(ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse next page URL %q: %w", ref, err)
+	}
+	return next.String(), nil
+}
+
+// nextLink returns the rel="next" target of RFC 5988 Link header values.
+func nextLink(links []string) string {
+	for _, value := range links {
+		for _, link := range strings.Split(value, ",") {
+			parts := strings.Split(link, ";")
+			target := strings.TrimSpace(parts[0])
+			if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+			for _, param := range parts[1:] {
+				name, rel, found := strings.Cut(strings.TrimSpace(param), "=")
+				if !found || !strings.EqualFold(strings.TrimSpace(name), "rel") {
+					continue
+				}
+				// rel may hold several space separated relation types
+				if slices.ContainsFunc(strings.Fields(strings.Trim(rel, `"`)), isNextRel) {
+					return strings.Trim(target, "<>")
+				}
+			}
+		}
+	}
+	return ""
+}
+
+func isNextRel(rel string) bool {
+	return strings.EqualFold(rel, "next")
+}
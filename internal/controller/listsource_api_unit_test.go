@@ -0,0 +1,391 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	batchopsv1alpha1 "github.com/matanryngler/parallax/api/v1alpha1"
+)
+
+// pagedItems are the items served by the paginated test APIs.
+var pagedItems = []string{"a", "b", "c", "d", "e"}
+
+func newAPIListSource(url string, pagination *batchopsv1alpha1.APIPagination) *batchopsv1alpha1.ListSource {
+	return &batchopsv1alpha1.ListSource{
+		ObjectMeta: metav1.ObjectMeta{Name: "paged", Namespace: "default"},
+		Spec: batchopsv1alpha1.ListSourceSpec{
+			Type: batchopsv1alpha1.APIList,
+			API: &batchopsv1alpha1.APIConfig{
+				URL:        url,
+				JSONPath:   "$.items[*]",
+				Pagination: pagination,
+			},
+		},
+	}
+}
+
+func newAPIReconciler(t *testing.T) *ListSourceReconciler {
+	scheme := runtime.NewScheme()
+	require.NoError(t, batchopsv1alpha1.AddToScheme(scheme))
+	require.NoError(t, corev1.AddToScheme(scheme))
+	return &ListSourceReconciler{
+		Client:   fake.NewClientBuilder().WithScheme(scheme).Build(),
+		Scheme:   scheme,
+		Recorder: record.NewFakeRecorder(100),
+	}
+}
+
+// writePage writes pagedItems[start:end] as an items document.
+func writePage(w http.ResponseWriter, start, end int, extra string) {
+	start = min(start, len(pagedItems))
+	end = min(end, len(pagedItems))
+	items := ""
+	for i, item := range pagedItems[start:end] {
+		if i > 0 {
+			items += ","
+		}
+		items += strconv.Quote(item)
+	}
+	fmt.Fprintf(w, `{"items":[%s]%s}`, items, extra)
+}
+
+func TestGetItemsFromAPI_Pagination(t *testing.T) {
+	t.Run("cursor as query parameter", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start, _ := strconv.Atoi(r.URL.Query().Get("after"))
+			extra := ""
+			if start+2 < len(pagedItems) {
+				extra = fmt.Sprintf(`,"meta":{"next":"%d"}`, start+2)
+			}
+			writePage(w, start, start+2, extra)
+		}))
+		defer server.Close()
+
+		listSource := newAPIListSource(server.URL, &batchopsv1alpha1.APIPagination{
+			Type:        batchopsv1alpha1.CursorPagination,
+			CursorPath:  "$.meta.next",
+			CursorParam: "after",
+		})
+		items, err := newAPIReconciler(t).getItemsFromAPI(context.Background(), listSource)
+		require.NoError(t, err)
+		assert.Equal(t, pagedItems, items)
+	})
+
+	t.Run("cursor as next page URL", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/items":
+				writePage(w, 0, 3, `,"next":"/items/more"`)
+			case "/items/more":
+				writePage(w, 3, 5, `,"next":null`)
+			}
+		}))
+		defer server.Close()
+
+		listSource := newAPIListSource(server.URL+"/items", &batchopsv1alpha1.APIPagination{
+			Type:       batchopsv1alpha1.CursorPagination,
+			CursorPath: "$.next",
+		})
+		items, err := newAPIReconciler(t).getItemsFromAPI(context.Background(), listSource)
+		require.NoError(t, err)
+		assert.Equal(t, pagedItems, items)
+	})
+
+	t.Run("link header", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+			if (page+1)*2 < len(pagedItems) {
+				w.Header().Set("Link", fmt.Sprintf(`</items?page=%d>; rel="next", </items?page=2>; rel="last"`, page+1))
+			}
+			writePage(w, page*2, page*2+2, "")
+		}))
+		defer server.Close()
+
+		listSource := newAPIListSource(server.URL+"/items", &batchopsv1alpha1.APIPagination{
+			Type: batchopsv1alpha1.LinkPagination,
+		})
+		items, err := newAPIReconciler(t).getItemsFromAPI(context.Background(), listSource)
+		require.NoError(t, err)
+		assert.Equal(t, pagedItems, items)
+	})
+
+	t.Run("offset and limit", func(t *testing.T) {
+		var requests []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests = append(requests, r.URL.RawQuery)
+			offset, _ := strconv.Atoi(r.URL.Query().Get("skip"))
+			limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+			writePage(w, offset, offset+limit, "")
+		}))
+		defer server.Close()
+
+		listSource := newAPIListSource(server.URL+"?sort=id", &batchopsv1alpha1.APIPagination{
+			Type:        batchopsv1alpha1.OffsetPagination,
+			OffsetParam: "skip",
+			Limit:       2,
+		})
+		items, err := newAPIReconciler(t).getItemsFromAPI(context.Background(), listSource)
+		require.NoError(t, err)
+		assert.Equal(t, pagedItems, items)
+		assert.Equal(t, []string{
+			"limit=2&skip=0&sort=id",
+			"limit=2&skip=2&sort=id",
+			"limit=2&skip=4&sort=id",
+		}, requests)
+	})
+
+	t.Run("page number until empty page", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			page, _ := strconv.Atoi(r.URL.Query().Get("p"))
+			writePage(w, page*2, page*2+2, "")
+		}))
+		defer server.Close()
+
+		listSource := newAPIListSource(server.URL, &batchopsv1alpha1.APIPagination{
+			Type:      batchopsv1alpha1.PagePagination,
+			PageParam: "p",
+			StartPage: ptr.To[int32](0),
+		})
+		items, err := newAPIReconciler(t).getItemsFromAPI(context.Background(), listSource)
+		require.NoError(t, err)
+		assert.Equal(t, pagedItems, items)
+	})
+
+	t.Run("max pages", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+			writePage(w, page-1, page, "")
+		}))
+		defer server.Close()
+
+		listSource := newAPIListSource(server.URL, &batchopsv1alpha1.APIPagination{
+			Type:     batchopsv1alpha1.PagePagination,
+			MaxPages: 3,
+		})
+		_, err := newAPIReconciler(t).getItemsFromAPI(context.Background(), listSource)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "more than 3 pages")
+	})
+
+	t.Run("repeated cursor", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			writePage(w, 0, 2, `,"next":"abc"`)
+		}))
+		defer server.Close()
+
+		listSource := newAPIListSource(server.URL, &batchopsv1alpha1.APIPagination{
+			Type:        batchopsv1alpha1.CursorPagination,
+			CursorPath:  "$.next",
+			CursorParam: "cursor",
+		})
+		_, err := newAPIReconciler(t).getItemsFromAPI(context.Background(), listSource)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "twice")
+	})
+
+	t.Run("failed page fails the fetch", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("page") == "2" {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			writePage(w, 0, 2, "")
+		}))
+		defer server.Close()
+
+		listSource := newAPIListSource(server.URL, &batchopsv1alpha1.APIPagination{
+			Type:  batchopsv1alpha1.PagePagination,
+			Limit: 2,
+		})
+		items, err := newAPIReconciler(t).getItemsFromAPI(context.Background(), listSource)
+		require.Error(t, err)
+		assert.Nil(t, items)
+		assert.Contains(t, err.Error(), "status 502")
+	})
+}
+
+func TestNextLink(t *testing.T) {
+	tests := []struct {
+		name  string
+		links []string
+		want  string
+	}{
+		{name: "no header"},
+		{
+			name:  "next among others",
+			links: []string{`<https://api.example.com/items?page=1>; rel="prev", <https://api.example.com/items?page=3>; rel="next"`},
+			want:  "https://api.example.com/items?page=3",
+		},
+		{
+			name:  "several header values",
+			links: []string{`</items?page=9>; rel="last"`, `</items?page=2>; rel=next`},
+			want:  "/items?page=2",
+		},
+		{
+			name:  "several relation types",
+			links: []string{`</items?page=2>; title="more"; rel="next last"`},
+			want:  "/items?page=2",
+		},
+		{
+			name:  "last page",
+			links: []string{`</items?page=1>; rel="first"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, nextLink(tt.links))
+		})
+	}
+}
+
+func TestGetItemsFromAPI_RequestBody(t *testing.T) {
+	t.Run("literal body defaults to POST", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			if r.Method != http.MethodPost || string(body) != `{"status":"active"}` ||
+				r.Header.Get("Content-Type") != "application/json" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			writePage(w, 0, 2, "")
+		}))
+		defer server.Close()
+
+		listSource := newAPIListSource(server.URL, nil)
+		listSource.Spec.API.Body = `{"status":"active"}`
+		items, err := newAPIReconciler(t).getItemsFromAPI(context.Background(), listSource)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "b"}, items)
+	})
+
+	t.Run("body from secret with explicit method", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			if r.Method != http.MethodPut || string(body) != `{"token":"s3cret"}` {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			writePage(w, 0, 1, "")
+		}))
+		defer server.Close()
+
+		r := newAPIReconciler(t)
+		require.NoError(t, r.Create(context.Background(), &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "search", Namespace: "default"},
+			Data:       map[string][]byte{"body": []byte(`{"token":"s3cret"}`)},
+		}))
+		listSource := newAPIListSource(server.URL, nil)
+		listSource.Spec.API.Method = http.MethodPut
+		listSource.Spec.API.BodyFrom = &batchopsv1alpha1.SecretRef{Name: "search", Key: "body"}
+		items, err := r.getItemsFromAPI(context.Background(), listSource)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a"}, items)
+	})
+
+	t.Run("graphql with cursor variable", func(t *testing.T) {
+		var requests []map[string]interface{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var request map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+			requests = append(requests, request)
+
+			variables := request["variables"].(map[string]interface{})
+			if variables["after"] == nil {
+				fmt.Fprint(w, `{"data":{"assets":{"nodes":[{"id":"a"},{"id":"b"}],"pageInfo":{"endCursor":"c1"}}}}`)
+				return
+			}
+			fmt.Fprint(w, `{"data":{"assets":{"nodes":[{"id":"c"}],"pageInfo":{"endCursor":null}}}}`)
+		}))
+		defer server.Close()
+
+		listSource := newAPIListSource(server.URL, &batchopsv1alpha1.APIPagination{
+			Type:        batchopsv1alpha1.CursorPagination,
+			CursorPath:  "$.data.assets.pageInfo.endCursor",
+			CursorParam: "after",
+		})
+		listSource.Spec.API.JSONPath = "$.data.assets.nodes[*].id"
+		listSource.Spec.API.GraphQL = &batchopsv1alpha1.GraphQLRequest{
+			Query:     "query Assets($kind: String, $after: String) { assets(kind: $kind, after: $after) { nodes { id } pageInfo { endCursor } } }",
+			Variables: &runtime.RawExtension{Raw: []byte(`{"kind":"server"}`)},
+		}
+		items, err := newAPIReconciler(t).getItemsFromAPI(context.Background(), listSource)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "b", "c"}, items)
+		require.Len(t, requests, 2)
+		assert.Equal(t, map[string]interface{}{"kind": "server"}, requests[0]["variables"])
+		assert.Equal(t, map[string]interface{}{"kind": "server", "after": "c1"}, requests[1]["variables"])
+	})
+
+	t.Run("graphql errors fail the fetch", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"data":null,"errors":[{"message":"field \"asets\" not found","path":["query","asets"]},{"message":"unauthorized"}]}`)
+		}))
+		defer server.Close()
+
+		listSource := newAPIListSource(server.URL, nil)
+		listSource.Spec.API.GraphQL = &batchopsv1alpha1.GraphQLRequest{Query: "{ asets { id } }"}
+		_, err := newAPIReconciler(t).getItemsFromAPI(context.Background(), listSource)
+		require.Error(t, err)
+		assert.Equal(t, `GraphQL query returned errors: field "asets" not found (at query.asets); unauthorized`, err.Error())
+	})
+}
+
+func TestListSourceController_GraphQLErrorInStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"errors":[{"message":"syntax error"}]}`)
+	}))
+	defer server.Close()
+
+	listSource := newAPIListSource(server.URL, nil)
+	listSource.Finalizers = []string{listSourceFinalizer}
+	listSource.Spec.API.GraphQL = &batchopsv1alpha1.GraphQLRequest{Query: "{"}
+
+	r := newAPIReconciler(t)
+	r.Client = fake.NewClientBuilder().
+		WithScheme(r.Scheme).
+		WithObjects(listSource).
+		WithStatusSubresource(&batchopsv1alpha1.ListSource{}).
+		Build()
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(listSource)}
+
+	_, err := r.Reconcile(context.Background(), req)
+	require.NoError(t, err)
+	require.NoError(t, r.Get(context.Background(), req.NamespacedName, listSource))
+	assert.Equal(t, "Error", listSource.Status.State)
+	assert.Equal(t, "GraphQL query returned errors: syntax error", listSource.Status.Error)
+}
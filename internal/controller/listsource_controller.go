@@ -17,28 +17,36 @@ limitations under the License.
 package controller
 
 import (
+	"bytes"
 	"context"
-	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"net/http"
-	"strings"
+	"slices"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	toolscache "k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
-	"k8s.io/client-go/util/jsonpath"
+	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
-	_ "github.com/lib/pq" // PostgreSQL driver
 	batchopsv1alpha1 "github.com/matanryngler/parallax/api/v1alpha1"
 )
 
@@ -49,12 +57,29 @@ type ListSourceReconciler struct {
 	client.Client
 	Scheme   *runtime.Scheme
 	Recorder record.EventRecorder
+	// MaxConcurrentReconciles is the number of ListSources fetched at the
+	// same time. Defaults to 1.
+	MaxConcurrentReconciles int
+	// KubernetesKinds are the kinds kubernetes sources may list. Secrets are
+	// never listed.
+	KubernetesKinds []schema.GroupKind
+	// KubernetesCrossNamespace lets kubernetes sources list namespaced kinds
+	// in other namespaces than their own, or in all namespaces.
+	KubernetesCrossNamespace bool
+
+	// transports holds the HTTP transports of API sources with TLS settings
+	transports apiTransportCache
+	// tokens holds the OAuth2 token sources of API sources
+	tokens oauth2TokenCache
+	// watches holds the watches of the kinds of kubernetes sources
+	watches kindWatches
 }
 
 // +kubebuilder:rbac:groups=batchops.io,resources=listsources,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=batchops.io,resources=listsources/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=batchops.io,resources=listsources/finalizers,verbs=update
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete;deletecollection
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
@@ -77,6 +102,7 @@ func (r *ListSourceReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	if err := r.Get(ctx, req.NamespacedName, &listSource); err != nil {
 		if apierrors.IsNotFound(err) {
 			log.V(1).Info("ListSource was not found - it may have been deleted")
+			r.watches.release(req.NamespacedName)
 			return ctrl.Result{}, nil
 		}
 		log.Error(err, "Unable to fetch ListSource from the API server")
@@ -119,6 +145,17 @@ func (r *ListSourceReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 				log.Info("Successfully removed associated ConfigMap", "target", cmID)
 			}
 
+			if err := r.DeleteAllOf(ctx, &corev1.ConfigMap{},
+				client.InNamespace(listSource.Namespace),
+				client.MatchingLabels{shardOfLabel: listSource.Name},
+			); err != nil {
+				log.Error(err, "Failed to remove ConfigMap shards", "target", cmID)
+				return result, err
+			}
+
+			r.transports.forget(req.NamespacedName)
+			r.tokens.forget(req.NamespacedName)
+			r.watches.release(req.NamespacedName)
 			controllerutil.RemoveFinalizer(&listSource, listSourceFinalizer)
 			if err := r.Update(ctx, &listSource); err != nil {
 				log.Error(err, "Unable to remove finalizer from ListSource")
@@ -140,98 +177,124 @@ func (r *ListSourceReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return ctrl.Result{Requeue: true}, nil
 	}
 
+	// Report the fetch in progress, unless it already is
+	if !meta.IsStatusConditionTrue(listSource.Status.Conditions, batchopsv1alpha1.ConditionFetching) {
+		listSource.Status.ObservedGeneration = listSource.Generation
+		setCondition(&listSource.Status.Conditions, batchopsv1alpha1.ConditionFetching, true, "FetchInProgress",
+			fmt.Sprintf("Fetching items from %s source", listSource.Spec.Type), listSource.Generation)
+		if err := r.Status().Update(ctx, &listSource); err != nil {
+			log.Error(err, "Unable to update ListSource status before fetching")
+			return result, err
+		}
+	}
+
+	if listSource.Spec.Type != batchopsv1alpha1.KubernetesList {
+		r.watches.release(req.NamespacedName)
+	}
+
 	// Get items based on source type
 	log.Info("Fetching items from source", "source_type", listSource.Spec.Type)
-	items, err := r.getItems(ctx, &listSource)
+	fetchCtx, stats := withFetchStats(ctx)
+	items, err := r.getItems(fetchCtx, &listSource)
+	if err == nil {
+		err = r.checkUpdate(ctx, &listSource, items)
+	}
 	if err != nil {
-		log.Error(err, "Failed to fetch items from source")
+		reason := "FetchFailed"
+		var rejected *rejectedUpdateError
+		if errors.As(err, &rejected) {
+			reason = "UpdateRejected"
+		}
+
+		// Failed fetches are started over on the failure backoff rather than
+		// the backoff of controller-runtime, so the error is not returned
+		failures := listSource.Status.ConsecutiveFailures + 1
+		delay := newFetchPolicy(listSource.Spec.FetchPolicy).failureDelay(failures, err)
+		log.Error(err, "Failed to fetch items from source",
+			"attempts", stats.attempts,
+			"consecutive_failures", failures,
+			"retry_in", delay,
+		)
+		now := time.Now()
+		if at := staleAt(&listSource); at.After(now) {
+			// Report the list stale as soon as it is
+			delay = min(delay, at.Sub(now))
+		}
 		listSource.Status.Error = err.Error()
 		listSource.Status.State = "Error"
-		listSource.Status.LastUpdateTime = &metav1.Time{Time: time.Now()}
+		listSource.Status.LastUpdateTime = &metav1.Time{Time: now}
+		listSource.Status.FetchAttempts = stats.attempts
+		listSource.Status.ConsecutiveFailures = failures
+		listSource.Status.NextRetryTime = &metav1.Time{Time: now.Add(delay)}
+		setFetchFailedConditions(&listSource, reason, err, now)
 		if err := r.Status().Update(ctx, &listSource); err != nil {
 			log.Error(err, "Unable to update ListSource status with error information")
 			return result, err
 		}
-		r.Recorder.Event(&listSource, corev1.EventTypeWarning, "FetchFailed", fmt.Sprintf("Failed to fetch items: %v", err))
-		return result, err
+		if rejected != nil {
+			r.Recorder.Event(&listSource, corev1.EventTypeWarning, reason,
+				fmt.Sprintf("Kept the served list, %v, retrying in %s", err, delay.Round(time.Second)))
+		} else {
+			r.Recorder.Event(&listSource, corev1.EventTypeWarning, reason,
+				fmt.Sprintf("Failed to fetch items: %v, retrying in %s", err, delay.Round(time.Second)))
+		}
+		return ctrl.Result{RequeueAfter: delay}, nil
 	}
 	log.Info("Successfully fetched items from source", "items_found", len(items))
 
-	// Create or update ConfigMap
+	// Create or update the ConfigMaps holding the items
 	cmID := fmt.Sprintf("ConfigMap/%s.%s", listSource.Name, listSource.Namespace)
-	cm := &corev1.ConfigMap{}
-	err = r.Get(ctx, types.NamespacedName{Name: listSource.Name, Namespace: listSource.Namespace}, cm)
+	shards, err := storeItems(ctx, r.Client, r.Scheme, &listSource, items, listSource.Spec.Compression)
 	if err != nil {
-		if apierrors.IsNotFound(err) {
-			log.Info("Creating new ConfigMap to store items", "target", cmID)
-			// Join items with newlines
-			itemsStr := strings.Join(items, "\n")
-
-			cm = &corev1.ConfigMap{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      listSource.Name,
-					Namespace: listSource.Namespace,
-				},
-				Data: map[string]string{
-					"items": itemsStr,
-				},
-			}
-			if err := ctrl.SetControllerReference(&listSource, cm, r.Scheme); err != nil {
-				log.Error(err, "Unable to set owner reference on ConfigMap", "target", cmID)
-				return ctrl.Result{}, err
-			}
-			if err := r.Create(ctx, cm); err != nil {
-				log.Error(err, "Failed to create ConfigMap", "target", cmID)
-				return ctrl.Result{}, err
-			}
-			log.Info("Successfully created ConfigMap with items", "target", cmID)
-		} else {
-			log.Error(err, "Unable to check if ConfigMap exists", "target", cmID)
-			return ctrl.Result{}, err
-		}
-	} else {
-		// Check if ConfigMap data needs to be updated
-		// Join new items with newlines
-		newItemsStr := strings.Join(items, "\n")
-
-		if currentItemsStr := cm.Data["items"]; currentItemsStr != newItemsStr {
-			log.Info("Updating existing ConfigMap with new items",
-				"target", cmID,
-				"current_version", cm.ResourceVersion,
-				"old_items_count", len(strings.Split(currentItemsStr, "\n")),
-				"new_items_count", len(items),
-			)
-			cm.Data = map[string]string{
-				"items": newItemsStr,
-			}
-			if err := r.Update(ctx, cm); err != nil {
-				log.Error(err, "Failed to update ConfigMap with new items", "target", cmID)
-				return ctrl.Result{}, err
-			}
-			log.Info("Successfully updated ConfigMap with new items",
-				"target", cmID,
-				"new_version", cm.ResourceVersion,
-			)
-		} else {
-			log.V(1).Info("ConfigMap data unchanged, skipping update",
-				"target", cmID,
-				"items_count", len(items),
-			)
-		}
+		log.Error(err, "Failed to store items in ConfigMap", "target", cmID)
+		return ctrl.Result{}, err
+	}
+	log.V(1).Info("Stored items in ConfigMap",
+		"target", cmID,
+		"items_count", len(items),
+		"shards", shards,
+	)
+	if shards < 2 {
+		shards = 0
 	}
 
+	conditions := slices.Clone(listSource.Status.Conditions)
+	setCondition(&conditions, batchopsv1alpha1.ConditionReady, true, "Fetched",
+		fmt.Sprintf("Fetched %d items", len(items)), listSource.Generation)
+	setCondition(&conditions, batchopsv1alpha1.ConditionFetching, false, "FetchSucceeded", "", listSource.Generation)
+	setCondition(&conditions, batchopsv1alpha1.ConditionDegraded, false, "FetchSucceeded", "", listSource.Generation)
+
 	// Update status if needed
 	statusChanged := false
+	now := metav1.Now()
+	// Incremental queries select the rows changed since this time, so it is
+	// taken before the query ran rather than after
+	fetchStarted := metav1.NewTime(stats.started)
 	newStatus := batchopsv1alpha1.ListSourceStatus{
-		LastUpdateTime: &metav1.Time{Time: time.Now()},
-		ItemCount:      len(items),
-		Error:          "",
-		State:          "Ready",
+		LastUpdateTime:          &now,
+		LastSuccessfulFetchTime: &fetchStarted,
+		ItemCount:               len(items),
+		Error:                   "",
+		State:                   "Ready",
+		Shards:                  shards,
+		FetchAttempts:           stats.attempts,
+		// Runs waiting for a fresh list start once the request is echoed
+		LastRefreshRequest: listSource.Annotations[batchopsv1alpha1.RefreshRequestedAnnotation],
+		ObservedGeneration: listSource.Generation,
+		Revision:           stats.revision,
+		Conditions:         conditions,
 	}
 
 	if listSource.Status.ItemCount != newStatus.ItemCount ||
+		listSource.Status.Shards != newStatus.Shards ||
+		listSource.Status.LastRefreshRequest != newStatus.LastRefreshRequest ||
+		listSource.Status.ObservedGeneration != newStatus.ObservedGeneration ||
+		listSource.Status.Revision != newStatus.Revision ||
+		!equality.Semantic.DeepEqual(listSource.Status.Conditions, newStatus.Conditions) ||
 		listSource.Status.Error != newStatus.Error ||
 		listSource.Status.State != newStatus.State ||
+		listSource.Status.FetchAttempts != newStatus.FetchAttempts ||
+		listSource.Status.ConsecutiveFailures != 0 ||
 		listSource.Status.LastUpdateTime == nil ||
 		time.Since(listSource.Status.LastUpdateTime.Time) > time.Second {
 		statusChanged = true
@@ -263,232 +326,112 @@ func (r *ListSourceReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	return result, nil
 }
 
+// setFetchFailedConditions records a failed or rejected fetch. A ListSource
+// that already holds a list stays ready and keeps serving it, but is degraded,
+// until the list is older than its maxStaleness.
+func setFetchFailedConditions(listSource *batchopsv1alpha1.ListSource, reason string, err error, now time.Time) {
+	generation := listSource.Generation
+	listSource.Status.ObservedGeneration = generation
+	setCondition(&listSource.Status.Conditions, batchopsv1alpha1.ConditionFetching, false, reason, err.Error(), generation)
+	ready := meta.IsStatusConditionTrue(listSource.Status.Conditions, batchopsv1alpha1.ConditionReady)
+	if ready || listSource.Status.LastSuccessfulFetchTime != nil {
+		if isListStale(listSource, now) {
+			setCondition(&listSource.Status.Conditions, batchopsv1alpha1.ConditionReady, false, "Stale",
+				fmt.Sprintf("The list is older than maxStaleness, the last fetch failed: %v", err), generation)
+		} else if !ready {
+			// No longer stale, e.g. after maxStaleness was raised
+			setCondition(&listSource.Status.Conditions, batchopsv1alpha1.ConditionReady, true, "Fetched", "Serving the previous list", generation)
+		}
+		setCondition(&listSource.Status.Conditions, batchopsv1alpha1.ConditionDegraded, true, reason,
+			fmt.Sprintf("Serving the previous list, the last fetch failed: %v", err), generation)
+		return
+	}
+	setCondition(&listSource.Status.Conditions, batchopsv1alpha1.ConditionReady, false, reason, err.Error(), generation)
+	setCondition(&listSource.Status.Conditions, batchopsv1alpha1.ConditionDegraded, true, reason, err.Error(), generation)
+}
+
 func (r *ListSourceReconciler) getItems(ctx context.Context, listSource *batchopsv1alpha1.ListSource) ([]string, error) {
 	switch listSource.Spec.Type {
 	case batchopsv1alpha1.StaticList:
-		return listSource.Spec.StaticList, nil
+		return formatStringItems(listSource.Spec.StaticList, listSource.Spec.ItemFormat)
 	case batchopsv1alpha1.APIList:
 		return r.getItemsFromAPI(ctx, listSource)
 	case batchopsv1alpha1.PostgresList:
-		return r.getItemsFromPostgres(ctx, listSource.Spec.Postgres, listSource.Namespace)
+		policy := newFetchPolicy(listSource.Spec.FetchPolicy)
+		var items []string
+		err := policy.do(ctx, retryableDBError, func(ctx context.Context) error {
+			var err error
+			items, err = r.getItemsFromPostgres(ctx, listSource)
+			return err
+		})
+		return items, err
+	case batchopsv1alpha1.MySQLList:
+		policy := newFetchPolicy(listSource.Spec.FetchPolicy)
+		var items []string
+		err := policy.do(ctx, retryableDBError, func(ctx context.Context) error {
+			var err error
+			items, err = r.getItemsFromMySQL(ctx, listSource)
+			return err
+		})
+		return items, err
+	case batchopsv1alpha1.SQLList:
+		policy := newFetchPolicy(listSource.Spec.FetchPolicy)
+		var items []string
+		err := policy.do(ctx, retryableDBError, func(ctx context.Context) error {
+			var err error
+			items, err = r.getItemsFromSQL(ctx, listSource)
+			return err
+		})
+		return items, err
+	case batchopsv1alpha1.KubernetesList:
+		policy := newFetchPolicy(listSource.Spec.FetchPolicy)
+		var items []string
+		err := policy.do(ctx, retryableKubernetesError, func(ctx context.Context) error {
+			var err error
+			items, err = r.getItemsFromKubernetes(ctx, listSource)
+			return err
+		})
+		return items, err
+	case batchopsv1alpha1.S3List:
+		policy := newFetchPolicy(listSource.Spec.FetchPolicy)
+		var items []string
+		err := policy.do(ctx, retryableS3Error, func(ctx context.Context) error {
+			var err error
+			items, err = r.getItemsFromS3(ctx, listSource)
+			return err
+		})
+		return items, err
+	case batchopsv1alpha1.GitList:
+		policy := newFetchPolicy(listSource.Spec.FetchPolicy)
+		var items []string
+		err := policy.do(ctx, retryableGitError, func(ctx context.Context) error {
+			var err error
+			items, err = r.getItemsFromGit(ctx, listSource)
+			return err
+		})
+		return items, err
 	default:
 		return nil, fmt.Errorf("unsupported list source type: %s", listSource.Spec.Type)
 	}
 }
 
-func (r *ListSourceReconciler) getItemsFromAPI(ctx context.Context, listSource *batchopsv1alpha1.ListSource) ([]string, error) {
-	resourceID := fmt.Sprintf("ListSource/%s.%s", listSource.Name, listSource.Namespace)
-	log := log.FromContext(ctx).WithValues(
-		"resource", resourceID,
-		"type", "api",
-		"url", listSource.Spec.API.URL,
-		"uid", listSource.UID,
-	)
-	log.Info("Starting API request to fetch items")
-
-	client := &http.Client{}
-	req, err := http.NewRequestWithContext(ctx, "GET", listSource.Spec.API.URL, nil)
-	if err != nil {
-		log.Error(err, "Failed to create HTTP request")
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Add headers
-	for k, v := range listSource.Spec.API.Headers {
-		req.Header.Add(k, v)
-		log.V(1).Info("Added request header", "header", k)
+// formatStringItems applies the ItemFormat to items that were fetched as
+// strings. In json mode every item must be a valid JSON document and is
+// compacted onto a single line.
+func formatStringItems(items []string, format batchopsv1alpha1.ItemFormat) ([]string, error) {
+	if format != batchopsv1alpha1.JSONItemFormat {
+		return items, nil
 	}
 
-	// Handle authentication if configured
-	if listSource.Spec.API.Auth != nil {
-		log.V(1).Info("Setting up authentication for API request", "auth_type", listSource.Spec.API.Auth.Type)
-		secret, err := r.getSecret(ctx, listSource.Namespace, listSource.Spec.API.Auth.SecretRef)
-		if err != nil {
-			log.Error(err, "Failed to retrieve authentication secret")
-			return nil, fmt.Errorf("failed to get auth secret: %w", err)
-		}
-
-		switch listSource.Spec.API.Auth.Type {
-		case batchopsv1alpha1.BasicAuth:
-			username := secret[listSource.Spec.API.Auth.UsernameKey]
-			password := secret[listSource.Spec.API.Auth.PasswordKey]
-			req.SetBasicAuth(username, password)
-			log.V(1).Info("Configured basic auth for request", "username", username)
-		case batchopsv1alpha1.BearerAuth:
-			token := secret[listSource.Spec.API.Auth.SecretRef.Key]
-			req.Header.Set("Authorization", "Bearer "+token)
-			log.V(1).Info("Configured bearer token authentication for request")
-		default:
-			log.Error(nil, "Unsupported authentication type specified", "auth_type", listSource.Spec.API.Auth.Type)
-			return nil, fmt.Errorf("unsupported auth type: %s", listSource.Spec.API.Auth.Type)
+	formatted := make([]string, 0, len(items))
+	for _, item := range items {
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, []byte(item)); err != nil {
+			return nil, fmt.Errorf("item %q is not valid JSON: %w", item, err)
 		}
+		formatted = append(formatted, buf.String())
 	}
-
-	resp, err := client.Do(req)
-	if err != nil {
-		log.Error(err, "API request failed")
-		return nil, fmt.Errorf("failed to make request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		log.Error(nil, "API returned non-200 status code",
-			"status_code", resp.StatusCode,
-			"status", resp.Status,
-		)
-		return nil, fmt.Errorf("API request failed with status %d", resp.StatusCode)
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		log.Error(err, "Failed to read API response body")
-		return nil, fmt.Errorf("failed to read response: %w", err)
-	}
-
-	// Log the response body for debugging
-	log.V(1).Info("Received API response", "body", string(body))
-
-	// Parse JSON response
-	var data interface{}
-	if err := json.Unmarshal(body, &data); err != nil {
-		log.Error(err, "Failed to parse API response as JSON")
-		return nil, fmt.Errorf("failed to parse response as JSON: %w", err)
-	}
-
-	// Evaluate JSONPath expression
-	log.V(1).Info("Extracting items using JSONPath", "expression", listSource.Spec.API.JSONPath)
-	jp := jsonpath.New("items")
-	if err := jp.Parse(fmt.Sprintf("{%s}", listSource.Spec.API.JSONPath)); err != nil {
-		log.Error(err, "Invalid JSONPath expression")
-		return nil, fmt.Errorf("failed to parse JSONPath expression: %w", err)
-	}
-
-	values, err := jp.FindResults(data)
-	if err != nil {
-		log.Error(err, "Failed to evaluate JSONPath against response")
-		return nil, fmt.Errorf("failed to evaluate JSONPath: %w", err)
-	}
-
-	if len(values) == 0 || len(values[0]) == 0 {
-		log.Error(nil, "JSONPath expression returned no results")
-		return nil, fmt.Errorf("JSONPath expression returned no results")
-	}
-
-	// Convert results to []string
-	var items []string
-	for _, value := range values[0] {
-		switch v := value.Interface().(type) {
-		case string:
-			items = append(items, v)
-		case []interface{}:
-			for _, item := range v {
-				if str, ok := item.(string); ok {
-					items = append(items, str)
-				} else {
-					items = append(items, fmt.Sprintf("%v", item))
-				}
-			}
-		default:
-			items = append(items, fmt.Sprintf("%v", v))
-		}
-	}
-
-	log.Info("Successfully processed API response", "items_found", len(items))
-	return items, nil
-}
-
-func (r *ListSourceReconciler) getItemsFromPostgres(ctx context.Context, config *batchopsv1alpha1.PostgresConfig, namespace string) ([]string, error) {
-	log := log.FromContext(ctx).WithValues(
-		"type", "postgresql",
-		"namespace", namespace,
-		"query", config.Query,
-	)
-	if config.Auth != nil {
-		secretID := fmt.Sprintf("Secret/%s.%s", config.Auth.SecretRef.Name, config.Auth.SecretRef.Namespace)
-		log = log.WithValues("auth_secret", secretID)
-	}
-	log.Info("Starting PostgreSQL query to fetch items")
-
-	// Get secret if specified
-	var password string
-	if config.Auth != nil {
-		log.V(1).Info("Retrieving database credentials")
-		secretData, err := r.getSecret(ctx, namespace, config.Auth.SecretRef)
-		if err != nil {
-			log.Error(err, "Failed to retrieve database credentials")
-			return nil, fmt.Errorf("failed to get secret: %w", err)
-		}
-		password = secretData[config.Auth.PasswordKey]
-	}
-
-	// Check if we have a mock DB in the context (for testing)
-	type dbKeyType string
-	const dbKey dbKeyType = "db"
-	var db *sql.DB
-	if mockDB, ok := ctx.Value(dbKey).(*sql.DB); ok {
-		log.V(1).Info("Using mock database from context")
-		db = mockDB
-	} else {
-		// Build connection string
-		connStr := config.ConnectionString
-		if !strings.Contains(connStr, "password=") && password != "" {
-			connStr = fmt.Sprintf("%s password=REDACTED", connStr)
-			log.V(1).Info("Added password to connection string")
-		}
-		if !strings.Contains(connStr, "sslmode=") {
-			connStr = fmt.Sprintf("%s sslmode=disable", connStr)
-			log.V(1).Info("Set SSL mode to disabled")
-		}
-		if !strings.Contains(connStr, "connect_timeout=") {
-			connStr = fmt.Sprintf("%s connect_timeout=10", connStr)
-			log.V(1).Info("Set connection timeout to 10 seconds")
-		}
-
-		// Open database connection
-		log.V(1).Info("Establishing database connection")
-		var err error
-		db, err = sql.Open("postgres", connStr)
-		if err != nil {
-			log.Error(err, "Failed to establish database connection")
-			return nil, fmt.Errorf("failed to open database connection: %w", err)
-		}
-		defer db.Close()
-	}
-
-	// Verify connection
-	log.V(1).Info("Verifying database connection")
-	if err := db.PingContext(ctx); err != nil {
-		log.Error(err, "Database connection test failed")
-		return nil, fmt.Errorf("failed to ping database: %w", err)
-	}
-
-	// Execute query
-	log.V(1).Info("Executing database query", "query", config.Query)
-	rows, err := db.QueryContext(ctx, config.Query)
-	if err != nil {
-		log.Error(err, "Database query failed")
-		return nil, fmt.Errorf("failed to execute query: %w", err)
-	}
-	defer rows.Close()
-
-	// Process results
-	var items []string
-	for rows.Next() {
-		var item string
-		if err := rows.Scan(&item); err != nil {
-			log.Error(err, "Failed to read row from query result")
-			return nil, fmt.Errorf("failed to scan row: %w", err)
-		}
-		items = append(items, item)
-	}
-
-	if err := rows.Err(); err != nil {
-		log.Error(err, "Error occurred while reading query results")
-		return nil, fmt.Errorf("error iterating rows: %w", err)
-	}
-
-	log.Info("Successfully executed database query", "items_found", len(items))
-	return items, nil
+	return formatted, nil
 }
 
 func (r *ListSourceReconciler) getSecret(ctx context.Context, namespace string, ref batchopsv1alpha1.SecretRef) (map[string]string, error) {
@@ -516,11 +459,107 @@ func (r *ListSourceReconciler) getSecret(ctx context.Context, namespace string,
 	return secretData, nil
 }
 
+func (r *ListSourceReconciler) getConfigMap(ctx context.Context, namespace string, ref batchopsv1alpha1.ConfigMapRef) (map[string]string, error) {
+	cmNamespace := namespace
+	if ref.Namespace != "" {
+		cmNamespace = ref.Namespace
+	}
+
+	var cm corev1.ConfigMap
+	if err := r.Get(ctx, client.ObjectKey{Name: ref.Name, Namespace: cmNamespace}, &cm); err != nil {
+		return nil, fmt.Errorf("failed to get ConfigMap %s.%s: %w", ref.Name, cmNamespace, err)
+	}
+	return cm.Data, nil
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *ListSourceReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	r.Recorder = mgr.GetEventRecorderFor("listsource-controller")
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&batchopsv1alpha1.ListSource{}).
+	c, err := ctrl.NewControllerManagedBy(mgr).
+		// Status updates must not trigger another fetch
+		For(&batchopsv1alpha1.ListSource{}, builder.WithPredicates(predicate.Or(
+			predicate.GenerationChangedPredicate{},
+			predicate.AnnotationChangedPredicate{},
+		))).
 		Named("listsource").
-		Complete(r)
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.MaxConcurrentReconciles}).
+		Build(r)
+	if err != nil {
+		return err
+	}
+
+	// Kinds are only known once kubernetes sources are reconciled, so their
+	// events reach the queue through a source of their own
+	var (
+		watchCtx context.Context
+		queue    workqueue.TypedRateLimitingInterface[reconcile.Request]
+	)
+	if err := c.Watch(source.Func(func(ctx context.Context, q workqueue.TypedRateLimitingInterface[reconcile.Request]) error {
+		watchCtx, queue = ctx, q
+		return nil
+	})); err != nil {
+		return err
+	}
+
+	// Each namespace gets a cache of its own, as the manager's only holds the
+	// Pods of ListJobs. The caches run as long as the manager, the informer of
+	// a kind is removed from them once no source watches it
+	caches := map[string]cache.Cache{}
+	r.watches.start = func(key watchKey) (func(), error) {
+		namespaceCache, ok := caches[key.namespace]
+		if !ok {
+			opts := cache.Options{
+				Scheme: mgr.GetScheme(),
+				Mapper: mgr.GetRESTMapper(),
+			}
+			if key.namespace != "" {
+				opts.DefaultNamespaces = map[string]cache.Config{key.namespace: {}}
+			}
+			var err error
+			if namespaceCache, err = cache.New(mgr.GetConfig(), opts); err != nil {
+				return nil, fmt.Errorf("failed to create cache for namespace %q: %w", key.namespace, err)
+			}
+			if err := mgr.Add(namespaceCache); err != nil {
+				return nil, fmt.Errorf("failed to start cache for namespace %q: %w", key.namespace, err)
+			}
+			caches[key.namespace] = namespaceCache
+		}
+
+		object := &unstructured.Unstructured{}
+		object.SetGroupVersionKind(key.gvk)
+		informer, err := namespaceCache.GetInformer(watchCtx, object, cache.BlockUntilSynced(false))
+		if err != nil {
+			return nil, fmt.Errorf("failed to watch %s: %w", key.gvk, err)
+		}
+		enqueue := func(objects ...interface{}) {
+			var changed []client.Object
+			for _, object := range objects {
+				if tombstone, ok := object.(toolscache.DeletedFinalStateUnknown); ok {
+					object = tombstone.Obj
+				}
+				if object, ok := object.(client.Object); ok {
+					changed = append(changed, object)
+				}
+			}
+			if len(changed) == 0 {
+				return
+			}
+			for _, request := range r.listSourcesWatching(watchCtx, changed...) {
+				queue.Add(request)
+			}
+		}
+		registration, err := informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+			AddFunc:    func(obj interface{}) { enqueue(obj) },
+			UpdateFunc: func(oldObj, newObj interface{}) { enqueue(oldObj, newObj) },
+			DeleteFunc: func(obj interface{}) { enqueue(obj) },
+		})
+		if err != nil {
+			_ = namespaceCache.RemoveInformer(watchCtx, object)
+			return nil, fmt.Errorf("failed to watch %s: %w", key.gvk, err)
+		}
+		return func() {
+			_ = informer.RemoveEventHandler(registration)
+			_ = namespaceCache.RemoveInformer(watchCtx, object)
+		}, nil
+	}
+	return nil
 }
@@ -70,6 +70,44 @@ func TestGetItems_RoutingLogic(t *testing.T) {
 		assert.Equal(t, []string{"item1", "item2", "item3"}, items)
 	})
 
+	t.Run("Static List Source with JSON Items", func(t *testing.T) {
+		listSource := &batchopsv1alpha1.ListSource{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-static-json",
+				Namespace: "default",
+			},
+			Spec: batchopsv1alpha1.ListSourceSpec{
+				Type:       batchopsv1alpha1.StaticList,
+				ItemFormat: batchopsv1alpha1.JSONItemFormat,
+				StaticList: []string{`{"id": 1, "name": "alice"}`, `{"id": 2,
+					"name": "bob"}`},
+			},
+		}
+
+		items, err := reconciler.getItems(ctx, listSource)
+		require.NoError(t, err)
+		assert.Equal(t, []string{`{"id":1,"name":"alice"}`, `{"id":2,"name":"bob"}`}, items)
+	})
+
+	t.Run("Static List Source with Invalid JSON Items", func(t *testing.T) {
+		listSource := &batchopsv1alpha1.ListSource{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-static-invalid-json",
+				Namespace: "default",
+			},
+			Spec: batchopsv1alpha1.ListSourceSpec{
+				Type:       batchopsv1alpha1.StaticList,
+				ItemFormat: batchopsv1alpha1.JSONItemFormat,
+				StaticList: []string{"not-json"},
+			},
+		}
+
+		items, err := reconciler.getItems(ctx, listSource)
+		assert.Error(t, err)
+		assert.Nil(t, items)
+		assert.Contains(t, err.Error(), "is not valid JSON")
+	})
+
 	t.Run("Unsupported Source Type", func(t *testing.T) {
 		listSource := &batchopsv1alpha1.ListSource{
 			ObjectMeta: metav1.ObjectMeta{
@@ -138,6 +176,41 @@ func TestGetItemsFromAPI(t *testing.T) {
 		assert.Equal(t, []string{"apple", "banana", "orange"}, items)
 	})
 
+	t.Run("API Call with JSON Items", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"users":[{"id":1,"name":"alice"},{"id":2,"name":"bob & co"}]}`))
+		}))
+		defer server.Close()
+
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+		recorder := record.NewFakeRecorder(100)
+		reconciler := &ListSourceReconciler{
+			Client:   fakeClient,
+			Scheme:   scheme,
+			Recorder: recorder,
+		}
+
+		listSource := &batchopsv1alpha1.ListSource{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-api-json",
+				Namespace: "default",
+			},
+			Spec: batchopsv1alpha1.ListSourceSpec{
+				Type:       batchopsv1alpha1.APIList,
+				ItemFormat: batchopsv1alpha1.JSONItemFormat,
+				API: &batchopsv1alpha1.APIConfig{
+					URL:      server.URL,
+					JSONPath: "$.users[*]",
+				},
+			},
+		}
+
+		items, err := reconciler.getItemsFromAPI(context.Background(), listSource)
+		require.NoError(t, err)
+		assert.Equal(t, []string{`{"id":1,"name":"alice"}`, `{"id":2,"name":"bob & co"}`}, items)
+	})
+
 	t.Run("API Call with Custom Headers", func(t *testing.T) {
 		// Setup mock server that checks headers
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,227 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	batchopsv1alpha1 "github.com/matanryngler/parallax/api/v1alpha1"
+)
+
+// maxRetryDuration bounds how long a fetch keeps retrying. Later failures end
+// the retries and the fetch is started over by the failure backoff, so that a
+// failing source does not hold up a reconciler for long.
+const maxRetryDuration = 2 * time.Minute
+
+var defaultRetryOnStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// backoff is an exponential delay with its defaults applied.
+type backoff struct {
+	initial time.Duration
+	max     time.Duration
+}
+
+func newBackoff(spec *batchopsv1alpha1.Backoff, initial, max time.Duration) backoff {
+	b := backoff{initial: initial, max: max}
+	if spec != nil && spec.Initial != nil {
+		b.initial = spec.Initial.Duration
+	}
+	if spec != nil && spec.Max != nil {
+		b.max = spec.Max.Duration
+	}
+	return b
+}
+
+// delay returns the delay after n previous delays, initial doubled n times and
+// capped at max. A random part of up to half of it is taken off, so that
+// sources failing together do not retry in lockstep.
+func (b backoff) delay(n int) time.Duration {
+	d := b.initial
+	for range n {
+		if d >= b.max {
+			break
+		}
+		d *= 2
+	}
+	d = min(d, b.max)
+	if half := int64(d / 2); half > 0 {
+		d -= time.Duration(rand.Int64N(half + 1)) // #nosec G404 -- jitter only
+	}
+	return d
+}
+
+// fetchPolicy is the FetchPolicy of a ListSource with its defaults applied.
+type fetchPolicy struct {
+	timeout            time.Duration
+	retries            int
+	retryBackoff       backoff
+	retryOnStatusCodes []int
+	failureBackoff     backoff
+}
+
+func newFetchPolicy(spec *batchopsv1alpha1.FetchPolicy) fetchPolicy {
+	if spec == nil {
+		spec = &batchopsv1alpha1.FetchPolicy{}
+	}
+	policy := fetchPolicy{
+		timeout:            batchopsv1alpha1.DefaultFetchTimeout,
+		retries:            int(spec.Retries),
+		retryBackoff:       newBackoff(spec.RetryBackoff, batchopsv1alpha1.DefaultRetryBackoff, batchopsv1alpha1.DefaultMaxRetryBackoff),
+		retryOnStatusCodes: defaultRetryOnStatusCodes,
+		failureBackoff:     newBackoff(spec.FailureBackoff, batchopsv1alpha1.DefaultFailureBackoff, batchopsv1alpha1.DefaultMaxFailureBackoff),
+	}
+	if spec.Timeout != nil {
+		policy.timeout = spec.Timeout.Duration
+	}
+	if len(spec.RetryOnStatusCodes) > 0 {
+		policy.retryOnStatusCodes = make([]int, 0, len(spec.RetryOnStatusCodes))
+		for _, code := range spec.RetryOnStatusCodes {
+			policy.retryOnStatusCodes = append(policy.retryOnStatusCodes, int(code))
+		}
+	}
+	return policy
+}
+
+// failureDelay returns the delay before a fetch is started over after the
+// given number of fetches failed in a row. A Retry-After sent with the last
+// failure is honored when it asks for more.
+func (p fetchPolicy) failureDelay(failures int32, err error) time.Duration {
+	return max(p.failureBackoff.delay(int(failures)-1), retryAfter(err))
+}
+
+// retryableAPIError reports whether a failed API request is worth repeating:
+// it timed out, could not connect, or was answered with one of the retried
+// statuses.
+func (p fetchPolicy) retryableAPIError(err error) bool {
+	var statusErr *apiStatusError
+	if errors.As(err, &statusErr) {
+		return slices.Contains(p.retryOnStatusCodes, statusErr.statusCode)
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+// do calls attempt until it succeeds, fails with an error retryable rejects,
+// or the retries run out, and returns the error of the last attempt. Every
+// attempt is bounded by the timeout. The delay between attempts follows the
+// retry backoff, or the Retry-After of the failure. A Retry-After beyond the
+// maximum backoff, or a fetch retrying for longer than maxRetryDuration across
+// all its requests, ends the retries: the fetch is then started over by the
+// failure backoff instead of holding up the reconciler.
+func (p fetchPolicy) do(ctx context.Context, retryable func(error) bool, attempt func(ctx context.Context) error) error {
+	log := log.FromContext(ctx)
+	stats := fetchStatsFrom(ctx)
+
+	for n := 0; ; n++ {
+		attemptCtx, cancel := context.WithTimeout(ctx, p.timeout)
+		err := attempt(attemptCtx)
+		cancel()
+		if stats != nil {
+			stats.attempts++
+		}
+		if err == nil || n >= p.retries || !retryable(err) || ctx.Err() != nil {
+			return err
+		}
+
+		delay := p.retryBackoff.delay(n)
+		if after := retryAfter(err); after > 0 {
+			if after > p.retryBackoff.max {
+				log.Info("Retry-After exceeds the retry backoff, giving up on retries", "retry_after", after)
+				return err
+			}
+			delay = after
+		}
+		if stats != nil && time.Since(stats.started)+delay > maxRetryDuration {
+			log.Info("Fetch retried for too long, giving up on retries", "max_retry_duration", maxRetryDuration)
+			return err
+		}
+		log.Info("Fetch attempt failed, retrying", "attempt", n+1, "retries", p.retries, "delay", delay, "error", err.Error())
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// fetchStats records when one fetch started and counts the attempts it made
+// across all its requests.
+type fetchStats struct {
+	// started is truncated to the precision of status timestamps, so that it
+	// reads back the same from status.lastSuccessfulFetchTime
+	started  time.Time
+	attempts int32
+	// revision is the version of the source the items were read from, for
+	// sources that have one
+	revision string
+}
+
+type fetchStatsKey struct{}
+
+// withFetchStats returns a context in which the attempts of a fetch are counted.
+func withFetchStats(ctx context.Context) (context.Context, *fetchStats) {
+	stats := &fetchStats{started: time.Now().Truncate(time.Second)}
+	return context.WithValue(ctx, fetchStatsKey{}, stats), stats
+}
+
+func fetchStatsFrom(ctx context.Context) *fetchStats {
+	stats, _ := ctx.Value(fetchStatsKey{}).(*fetchStats)
+	return stats
+}
+
+// retryAfter returns the delay a failed API response asked for in its
+// Retry-After header, 0 when there is none.
+func retryAfter(err error) time.Duration {
+	var statusErr *apiStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.retryAfter
+	}
+	return 0
+}
+
+// parseRetryAfter parses a Retry-After header, given in seconds or as an HTTP
+// date. It returns 0 for a missing or invalid header.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0)
+	}
+	return 0
+}
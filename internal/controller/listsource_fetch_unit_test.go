@@ -0,0 +1,242 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	batchopsv1alpha1 "github.com/matanryngler/parallax/api/v1alpha1"
+)
+
+// flakyServer answers the first failures requests with status and serves two
+// items afterwards. It counts the requests it receives.
+func flakyServer(t *testing.T, failures int32, status int, retryAfter string) (*httptest.Server, *atomic.Int32) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) <= failures {
+			if retryAfter != "" {
+				w.Header().Set("Retry-After", retryAfter)
+			}
+			w.WriteHeader(status)
+			return
+		}
+		writePage(w, 0, 2, "")
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func fastRetries(retries int32) *batchopsv1alpha1.FetchPolicy {
+	return &batchopsv1alpha1.FetchPolicy{
+		Retries: retries,
+		RetryBackoff: &batchopsv1alpha1.Backoff{
+			Initial: &metav1.Duration{Duration: time.Millisecond},
+			Max:     &metav1.Duration{Duration: 10 * time.Millisecond},
+		},
+	}
+}
+
+func TestGetItemsFromAPI_Retries(t *testing.T) {
+	t.Run("retried status succeeds", func(t *testing.T) {
+		server, requests := flakyServer(t, 2, http.StatusServiceUnavailable, "")
+		listSource := newAPIListSource(server.URL, nil)
+		listSource.Spec.FetchPolicy = fastRetries(2)
+
+		ctx, stats := withFetchStats(context.Background())
+		items, err := newAPIReconciler(t).getItemsFromAPI(ctx, listSource)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "b"}, items)
+		assert.Equal(t, int32(3), requests.Load())
+		assert.Equal(t, int32(3), stats.attempts)
+	})
+
+	t.Run("retries run out", func(t *testing.T) {
+		server, requests := flakyServer(t, 5, http.StatusTooManyRequests, "")
+		listSource := newAPIListSource(server.URL, nil)
+		listSource.Spec.FetchPolicy = fastRetries(2)
+
+		_, err := newAPIReconciler(t).getItemsFromAPI(context.Background(), listSource)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "status 429")
+		assert.Equal(t, int32(3), requests.Load())
+	})
+
+	t.Run("other statuses are not retried", func(t *testing.T) {
+		server, requests := flakyServer(t, 1, http.StatusNotFound, "")
+		listSource := newAPIListSource(server.URL, nil)
+		listSource.Spec.FetchPolicy = fastRetries(2)
+
+		_, err := newAPIReconciler(t).getItemsFromAPI(context.Background(), listSource)
+		require.Error(t, err)
+		assert.Equal(t, int32(1), requests.Load())
+	})
+
+	t.Run("custom retry statuses", func(t *testing.T) {
+		server, requests := flakyServer(t, 1, http.StatusNotFound, "")
+		listSource := newAPIListSource(server.URL, nil)
+		listSource.Spec.FetchPolicy = fastRetries(1)
+		listSource.Spec.FetchPolicy.RetryOnStatusCodes = []int32{http.StatusNotFound}
+
+		_, err := newAPIReconciler(t).getItemsFromAPI(context.Background(), listSource)
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), requests.Load())
+	})
+
+	t.Run("long Retry-After ends retries", func(t *testing.T) {
+		server, requests := flakyServer(t, 1, http.StatusTooManyRequests, "120")
+		listSource := newAPIListSource(server.URL, nil)
+		listSource.Spec.FetchPolicy = fastRetries(2)
+
+		_, err := newAPIReconciler(t).getItemsFromAPI(context.Background(), listSource)
+		require.Error(t, err)
+		assert.Equal(t, int32(1), requests.Load())
+		assert.Equal(t, 120*time.Second, retryAfter(err))
+	})
+
+	t.Run("fetches retrying for too long end retries", func(t *testing.T) {
+		server, requests := flakyServer(t, 1, http.StatusServiceUnavailable, "")
+		listSource := newAPIListSource(server.URL, nil)
+		listSource.Spec.FetchPolicy = fastRetries(2)
+
+		ctx, stats := withFetchStats(context.Background())
+		stats.started = time.Now().Add(-maxRetryDuration)
+		_, err := newAPIReconciler(t).getItemsFromAPI(ctx, listSource)
+		require.Error(t, err)
+		assert.Equal(t, int32(1), requests.Load())
+	})
+
+	t.Run("timed out requests are retried", func(t *testing.T) {
+		var requests atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if requests.Add(1) == 1 {
+				select {
+				case <-r.Context().Done():
+				case <-time.After(time.Second):
+				}
+				return
+			}
+			writePage(w, 0, 2, "")
+		}))
+		defer server.Close()
+
+		listSource := newAPIListSource(server.URL, nil)
+		listSource.Spec.FetchPolicy = fastRetries(1)
+		listSource.Spec.FetchPolicy.Timeout = &metav1.Duration{Duration: 50 * time.Millisecond}
+
+		items, err := newAPIReconciler(t).getItemsFromAPI(context.Background(), listSource)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "b"}, items)
+		assert.Equal(t, int32(2), requests.Load())
+	})
+}
+
+func TestListSourceController_FailureBackoff(t *testing.T) {
+	server, _ := flakyServer(t, 2, http.StatusBadGateway, "")
+	listSource := newAPIListSource(server.URL, nil)
+	listSource.Finalizers = []string{listSourceFinalizer}
+	listSource.Spec.IntervalSeconds = 3600
+	listSource.Spec.FetchPolicy = &batchopsv1alpha1.FetchPolicy{
+		FailureBackoff: &batchopsv1alpha1.Backoff{
+			Initial: &metav1.Duration{Duration: 20 * time.Second},
+			Max:     &metav1.Duration{Duration: 30 * time.Second},
+		},
+	}
+
+	r := newAPIReconciler(t)
+	r.Client = fake.NewClientBuilder().
+		WithScheme(r.Scheme).
+		WithObjects(listSource).
+		WithStatusSubresource(&batchopsv1alpha1.ListSource{}).
+		Build()
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(listSource)}
+
+	// The failure backoff doubles from 20s, capped at 30s, less up to half
+	for failures, maxDelay := range []time.Duration{20 * time.Second, 30 * time.Second} {
+		result, err := r.Reconcile(ctx, req)
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, result.RequeueAfter, maxDelay/2)
+		assert.LessOrEqual(t, result.RequeueAfter, maxDelay)
+
+		require.NoError(t, r.Get(ctx, req.NamespacedName, listSource))
+		assert.Equal(t, int32(failures+1), listSource.Status.ConsecutiveFailures)
+		assert.Equal(t, int32(1), listSource.Status.FetchAttempts)
+		require.NotNil(t, listSource.Status.NextRetryTime)
+		assert.WithinDuration(t, time.Now().Add(result.RequeueAfter), listSource.Status.NextRetryTime.Time, time.Second)
+	}
+
+	// A successful fetch returns to the interval
+	result, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, time.Hour, result.RequeueAfter)
+	require.NoError(t, r.Get(ctx, req.NamespacedName, listSource))
+	assert.Equal(t, "Ready", listSource.Status.State)
+	assert.Zero(t, listSource.Status.ConsecutiveFailures)
+	assert.Nil(t, listSource.Status.NextRetryTime)
+}
+
+func TestBackoffDelay(t *testing.T) {
+	b := backoff{initial: time.Second, max: 10 * time.Second}
+	for n, maxDelay := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second} {
+		for range 20 {
+			delay := b.delay(n)
+			assert.GreaterOrEqual(t, delay, maxDelay/2)
+			assert.LessOrEqual(t, delay, maxDelay)
+		}
+	}
+}
+
+func TestFetchPolicy_FailureDelay(t *testing.T) {
+	policy := newFetchPolicy(nil)
+	delay := policy.failureDelay(1, nil)
+	assert.GreaterOrEqual(t, delay, batchopsv1alpha1.DefaultFailureBackoff/2)
+	assert.LessOrEqual(t, delay, batchopsv1alpha1.DefaultFailureBackoff)
+
+	// A longer Retry-After wins
+	throttled := &apiStatusError{statusCode: http.StatusTooManyRequests, retryAfter: time.Hour}
+	assert.Equal(t, time.Hour, policy.failureDelay(1, throttled))
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"30", 30 * time.Second},
+		{"-5", 0},
+		{now.Add(90 * time.Second).Format(http.TimeFormat), 90 * time.Second},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0},
+		{"soon", 0},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, parseRetryAfter(tt.value, now), tt.value)
+	}
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// pathFilter selects slash-separated paths, such as object keys, by include
+// and exclude globs and a regular expression. The zero value selects every
+// path.
+type pathFilter struct {
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+	regex   *regexp.Regexp
+}
+
+func newPathFilter(include, exclude []string, regex string) (*pathFilter, error) {
+	filter := &pathFilter{}
+	for _, pattern := range include {
+		re, err := compileGlob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		filter.include = append(filter.include, re)
+	}
+	for _, pattern := range exclude {
+		re, err := compileGlob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		filter.exclude = append(filter.exclude, re)
+	}
+	if regex != "" {
+		re, err := regexp.Compile(regex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression %q: %w", regex, err)
+		}
+		filter.regex = re
+	}
+	return filter, nil
+}
+
+// matches reports whether a path matches one of the include globs, if any,
+// none of the exclude globs, and the regular expression, if set.
+func (f *pathFilter) matches(path string) bool {
+	if len(f.include) > 0 && !matchesAny(f.include, path) {
+		return false
+	}
+	if matchesAny(f.exclude, path) {
+		return false
+	}
+	return f.regex == nil || f.regex.MatchString(path)
+}
+
+func matchesAny(patterns []*regexp.Regexp, path string) bool {
+	for _, re := range patterns {
+		if re.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// compileGlob translates a glob into an anchored regular expression. "*" and
+// "?" match within a path segment, "**" across segments, and "**/" any number
+// of leading directories, none included. Character classes and backslash
+// escapes follow path.Match.
+func compileGlob(pattern string) (*regexp.Regexp, error) {
+	var expr strings.Builder
+	expr.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			if !strings.HasPrefix(pattern[i:], "**") {
+				expr.WriteString("[^/]*")
+				continue
+			}
+			i++
+			if strings.HasPrefix(pattern[i+1:], "/") {
+				i++
+				expr.WriteString("(?:.*/)?")
+				continue
+			}
+			expr.WriteString(".*")
+		case '?':
+			expr.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid glob %q: unterminated character class", pattern)
+			}
+			class := pattern[i+1 : i+1+end]
+			negate := strings.HasPrefix(class, "^")
+			class = strings.TrimPrefix(class, "^")
+			if class == "" {
+				return nil, fmt.Errorf("invalid glob %q: empty character class", pattern)
+			}
+			expr.WriteString("[")
+			if negate {
+				expr.WriteString("^/")
+			}
+			expr.WriteString(strings.ReplaceAll(class, `[`, `\[`))
+			expr.WriteString("]")
+			i += end + 1
+		case '\\':
+			if i+1 == len(pattern) {
+				return nil, fmt.Errorf("invalid glob %q: trailing backslash", pattern)
+			}
+			i++
+			expr.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	expr.WriteString("$")
+
+	re, err := regexp.Compile(expr.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid glob %q: %w", pattern, err)
+	}
+	return re, nil
+}
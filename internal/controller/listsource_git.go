@@ -0,0 +1,427 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/hmac"
+	"crypto/sha1" // #nosec G505 -- hashed known_hosts entries are HMAC-SHA1
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"k8s.io/client-go/util/jsonpath"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	batchopsv1alpha1 "github.com/matanryngler/parallax/api/v1alpha1"
+)
+
+// defaultGitJSONPath yields the elements of a top-level array.
+const defaultGitJSONPath = "$[*]"
+
+// maxGitRepositorySize bounds the size of the objects of a clone, which is
+// kept in the memory of the operator.
+const maxGitRepositorySize = 32 << 20
+
+// gitProtocols are the transports repositories are cloned over. Local paths
+// and file URLs are not, they would read repositories of the operator's
+// filesystem.
+var gitProtocols = []string{"https", "http", "ssh", "git"}
+
+// gitCommitSHA matches full commit ids.
+var gitCommitSHA = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+var (
+	errGitTransport          = errors.New("repository URL must be https, http, ssh or git")
+	errGitRepositoryTooLarge = fmt.Errorf("repository exceeds %d MiB", maxGitRepositorySize>>20)
+)
+
+func (r *ListSourceReconciler) getItemsFromGit(ctx context.Context, listSource *batchopsv1alpha1.ListSource) ([]string, error) {
+	config := listSource.Spec.Git
+	log := log.FromContext(ctx).WithValues(
+		"type", "git",
+		"url", config.URL,
+		"ref", config.Ref,
+	)
+	log.Info("Cloning git repository to fetch items")
+
+	commit, err := r.gitCommit(ctx, listSource)
+	if err != nil {
+		return nil, err
+	}
+	log.V(1).Info("Resolved git ref", "commit", commit.Hash.String())
+
+	var items []string
+	if config.Paths != nil {
+		items, err = gitPaths(commit, config.Paths, listSource.Spec.ItemFormat)
+	} else {
+		items, err = gitFileItems(commit, config.File, listSource.Spec.ItemFormat)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if stats := fetchStatsFrom(ctx); stats != nil {
+		stats.revision = commit.Hash.String()
+	}
+	return items, nil
+}
+
+// gitCommit clones the repository of a git source in memory, without a
+// worktree, and returns the commit of its ref. Only that commit is fetched,
+// without history.
+func (r *ListSourceReconciler) gitCommit(ctx context.Context, listSource *batchopsv1alpha1.ListSource) (*object.Commit, error) {
+	config := listSource.Spec.Git
+	endpoint, err := transport.NewEndpoint(config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid repository URL: %w", err)
+	}
+	if !slices.Contains(gitProtocols, endpoint.Protocol) {
+		return nil, fmt.Errorf("%w, not %s", errGitTransport, endpoint.Protocol)
+	}
+	auth, err := r.gitAuth(ctx, listSource.Namespace, config, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	var caBundle []byte
+	if config.CA != nil {
+		if caBundle, err = r.caBundle(ctx, listSource.Namespace, config.CA); err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+	}
+
+	if gitCommitSHA.MatchString(config.Ref) {
+		return fetchGitCommit(ctx, config.URL, plumbing.NewHash(config.Ref), auth, caBundle)
+	}
+	clone := func(refName plumbing.ReferenceName) (*git.Repository, error) {
+		return git.CloneContext(ctx, &gitStorage{Storage: memory.NewStorage()}, nil, &git.CloneOptions{
+			URL:           config.URL,
+			Auth:          auth,
+			ReferenceName: refName,
+			SingleBranch:  true,
+			Depth:         1,
+			Tags:          git.NoTags,
+			CABundle:      caBundle,
+		})
+	}
+
+	var repo *git.Repository
+	switch {
+	case config.Ref == "":
+		repo, err = clone("")
+	case strings.HasPrefix(config.Ref, "refs/"):
+		repo, err = clone(plumbing.ReferenceName(config.Ref))
+	default:
+		repo, err = clone(plumbing.NewBranchReferenceName(config.Ref))
+		var noMatch git.NoMatchingRefSpecError
+		if errors.As(err, &noMatch) {
+			repo, err = clone(plumbing.NewTagReferenceName(config.Ref))
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone %s: %w", config.URL, err)
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve ref of %s: %w", config.URL, err)
+	}
+	if tag, err := repo.TagObject(head.Hash()); err == nil {
+		// Annotated tags point to their commit
+		commit, err := tag.Commit()
+		if err != nil {
+			return nil, fmt.Errorf("tag %s does not point to a commit: %w", config.Ref, err)
+		}
+		return commit, nil
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("commit %s not found in %s: %w", head.Hash(), config.URL, err)
+	}
+	return commit, nil
+}
+
+// fetchGitCommit fetches a single commit by its SHA, which the server must
+// allow, as GitHub and GitLab do.
+func fetchGitCommit(ctx context.Context, url string, hash plumbing.Hash, auth transport.AuthMethod, caBundle []byte) (*object.Commit, error) {
+	repo, err := git.Init(&gitStorage{Storage: memory.NewStorage()}, nil)
+	if err != nil {
+		return nil, err
+	}
+	remote, err := repo.CreateRemote(&gitconfig.RemoteConfig{Name: git.DefaultRemoteName, URLs: []string{url}})
+	if err != nil {
+		return nil, err
+	}
+	if err := remote.FetchContext(ctx, &git.FetchOptions{
+		RefSpecs: []gitconfig.RefSpec{gitconfig.RefSpec(hash.String() + ":refs/heads/commit")},
+		Depth:    1,
+		Auth:     auth,
+		CABundle: caBundle,
+		Tags:     git.NoTags,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to fetch commit %s of %s: %w", hash, url, err)
+	}
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		return nil, fmt.Errorf("commit %s not found in %s: %w", hash, url, err)
+	}
+	return commit, nil
+}
+
+// gitStorage keeps a clone in memory and fails it once its objects exceed
+// maxGitRepositorySize.
+type gitStorage struct {
+	*memory.Storage
+	size int64
+}
+
+func (s *gitStorage) SetEncodedObject(obj plumbing.EncodedObject) (plumbing.Hash, error) {
+	if s.size += obj.Size(); s.size > maxGitRepositorySize {
+		return plumbing.ZeroHash, errGitRepositoryTooLarge
+	}
+	return s.Storage.SetEncodedObject(obj)
+}
+
+// gitPaths returns the paths of a commit selected by a git source.
+func gitPaths(commit *object.Commit, config *batchopsv1alpha1.GitPaths, format batchopsv1alpha1.ItemFormat) ([]string, error) {
+	filter, err := newPathFilter(config.Include, config.Exclude, "")
+	if err != nil {
+		return nil, err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tree of commit %s: %w", commit.Hash, err)
+	}
+
+	walker := object.NewTreeWalker(tree, true, nil)
+	defer walker.Close()
+	var paths []string
+	for {
+		name, entry, err := walker.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to walk tree of commit %s: %w", commit.Hash, err)
+		}
+		if entry.Mode == filemode.Submodule {
+			continue
+		}
+		if config.Type == batchopsv1alpha1.GitFilePath && entry.Mode == filemode.Dir ||
+			config.Type == batchopsv1alpha1.GitDirectoryPath && entry.Mode != filemode.Dir {
+			continue
+		}
+		if filter.matches(name) {
+			paths = append(paths, name)
+		}
+	}
+	return formatStringItems(paths, format)
+}
+
+// gitFileItems returns the items of a file of a commit, its lines or the
+// results of a JSONPath.
+func gitFileItems(commit *object.Commit, config *batchopsv1alpha1.GitFile, format batchopsv1alpha1.ItemFormat) ([]string, error) {
+	file, err := commit.File(path.Clean(strings.TrimPrefix(config.Path, "/")))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s at commit %s: %w", config.Path, commit.Hash, err)
+	}
+	contents, err := file.Contents()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", config.Path, err)
+	}
+
+	if config.Format == batchopsv1alpha1.GitJSONFormat {
+		jp := jsonpath.New("items")
+		if err := jp.Parse(fmt.Sprintf("{%s}", cmp.Or(config.JSONPath, defaultGitJSONPath))); err != nil {
+			return nil, fmt.Errorf("failed to parse JSONPath expression: %w", err)
+		}
+		var data interface{}
+		if err := json.Unmarshal([]byte(contents), &data); err != nil {
+			return nil, fmt.Errorf("failed to parse file %s as JSON: %w", config.Path, err)
+		}
+		return extractAPIItems(jp, data, format)
+	}
+
+	var lines []string
+	for _, line := range strings.Split(contents, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return formatStringItems(lines, format)
+}
+
+// gitAuth reads the credentials of a git source, for HTTPS or SSH depending on
+// its URL. Neither is ever logged.
+func (r *ListSourceReconciler) gitAuth(ctx context.Context, namespace string, config *batchopsv1alpha1.GitConfig, endpoint *transport.Endpoint) (transport.AuthMethod, error) {
+	auth := config.Auth
+	if auth == nil {
+		return nil, nil
+	}
+
+	log := log.FromContext(ctx)
+	log.V(1).Info("Retrieving git credentials", "protocol", endpoint.Protocol)
+	secretData, err := r.getSecret(ctx, namespace, auth.SecretRef)
+	if err != nil {
+		log.Error(err, "Failed to retrieve git credentials")
+		return nil, fmt.Errorf("failed to get secret: %w", err)
+	}
+	secret, ok := secretData[auth.SecretRef.Key]
+	if !ok {
+		return nil, fmt.Errorf("git credentials secret %s has no key %s", auth.SecretRef.Name, auth.SecretRef.Key)
+	}
+
+	if endpoint.Protocol != "ssh" {
+		username := "git"
+		if auth.UsernameKey != "" {
+			if username, ok = secretData[auth.UsernameKey]; !ok {
+				return nil, fmt.Errorf("git credentials secret %s has no key %s", auth.SecretRef.Name, auth.UsernameKey)
+			}
+		}
+		return &githttp.BasicAuth{Username: username, Password: secret}, nil
+	}
+
+	var passphrase string
+	if auth.PassphraseKey != "" {
+		if passphrase, ok = secretData[auth.PassphraseKey]; !ok {
+			return nil, fmt.Errorf("git credentials secret %s has no key %s", auth.SecretRef.Name, auth.PassphraseKey)
+		}
+	}
+	keys, err := gitssh.NewPublicKeys(cmp.Or(endpoint.User, "git"), []byte(secret), passphrase)
+	if err != nil {
+		// The error of the SSH package does not quote the key
+		return nil, fmt.Errorf("invalid SSH private key in secret %s: %w", auth.SecretRef.Name, err)
+	}
+	switch {
+	case auth.InsecureIgnoreHostKey:
+		keys.HostKeyCallback = ssh.InsecureIgnoreHostKey() // #nosec G106 -- opt-in
+	case auth.KnownHostsKey != "":
+		knownHosts, ok := secretData[auth.KnownHostsKey]
+		if !ok {
+			return nil, fmt.Errorf("git credentials secret %s has no key %s", auth.SecretRef.Name, auth.KnownHostsKey)
+		}
+		if keys.HostKeyCallback, err = knownHostsCallback([]byte(knownHosts)); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New("SSH repositories need auth.knownHostsKey, or auth.insecureIgnoreHostKey")
+	}
+	return keys, nil
+}
+
+type knownHost struct {
+	patterns []string
+	key      ssh.PublicKey
+}
+
+// knownHostsCallback verifies host keys against known_hosts entries, read
+// from memory as the root filesystem of the operator is read-only. Plain,
+// wildcard and hashed host names are supported, markers are not.
+func knownHostsCallback(data []byte) (ssh.HostKeyCallback, error) {
+	var hosts []knownHost
+	for rest := data; len(bytes.TrimSpace(rest)) > 0; {
+		marker, patterns, key, _, next, err := ssh.ParseKnownHosts(rest)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid known_hosts: %w", err)
+		}
+		rest = next
+		if marker == "" {
+			hosts = append(hosts, knownHost{patterns: patterns, key: key})
+		}
+	}
+	if len(hosts) == 0 {
+		return nil, errors.New("known_hosts has no entries")
+	}
+
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		address := knownhosts.Normalize(hostname)
+		for _, host := range hosts {
+			if matchesKnownHost(host.patterns, address) && bytes.Equal(host.key.Marshal(), key.Marshal()) {
+				return nil
+			}
+		}
+		return fmt.Errorf("host key of %s is not in known_hosts", hostname)
+	}, nil
+}
+
+func matchesKnownHost(patterns []string, address string) bool {
+	for _, pattern := range patterns {
+		if strings.HasPrefix(pattern, "|1|") {
+			salt, hash, ok := strings.Cut(strings.TrimPrefix(pattern, "|1|"), "|")
+			if !ok {
+				continue
+			}
+			saltBytes, err := base64.StdEncoding.DecodeString(salt)
+			if err != nil {
+				continue
+			}
+			mac := hmac.New(sha1.New, saltBytes)
+			mac.Write([]byte(address))
+			if base64.StdEncoding.EncodeToString(mac.Sum(nil)) == hash {
+				return true
+			}
+			continue
+		}
+		if matched, err := path.Match(knownhosts.Normalize(pattern), address); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// retryableGitError reports whether a failed clone is worth repeating:
+// rejected URLs and credentials, missing repositories and refs, and
+// repositories too large to clone are not.
+func retryableGitError(err error) bool {
+	var noMatch git.NoMatchingRefSpecError
+	switch {
+	case errors.As(err, &noMatch),
+		errors.Is(err, errGitTransport),
+		errors.Is(err, errGitRepositoryTooLarge),
+		errors.Is(err, git.ErrExactSHA1NotSupported),
+		errors.Is(err, transport.ErrAuthenticationRequired),
+		errors.Is(err, transport.ErrAuthorizationFailed),
+		errors.Is(err, transport.ErrRepositoryNotFound),
+		errors.Is(err, transport.ErrInvalidAuthMethod),
+		errors.Is(err, plumbing.ErrObjectNotFound),
+		errors.Is(err, object.ErrFileNotFound):
+		return false
+	}
+	return true
+}
@@ -0,0 +1,309 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"net"
+	"net/http/cgi"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	batchopsv1alpha1 "github.com/matanryngler/parallax/api/v1alpha1"
+)
+
+// gitRepo is a repository served over HTTP by the git http-backend of the
+// system, which allows fetching commits by their SHA.
+type gitRepo struct {
+	t      *testing.T
+	dir    string
+	repo   *git.Repository
+	server *httptest.Server
+}
+
+func newGitRepo(t *testing.T) *gitRepo {
+	gitPath, err := exec.LookPath("git")
+	if err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	require.NoError(t, err)
+	config, err := repo.Config()
+	require.NoError(t, err)
+	config.Raw.Section("uploadpack").SetOption("allowReachableSHA1InWant", "true")
+	require.NoError(t, repo.SetConfig(config))
+
+	server := httptest.NewServer(&cgi.Handler{
+		Path: gitPath,
+		Args: []string{"http-backend"},
+		Env:  []string{"GIT_PROJECT_ROOT=" + dir, "GIT_HTTP_EXPORT_ALL=1"},
+	})
+	t.Cleanup(server.Close)
+	return &gitRepo{t: t, dir: dir, repo: repo, server: server}
+}
+
+func (g *gitRepo) url() string {
+	return g.server.URL + "/.git"
+}
+
+// commit writes files and commits them.
+func (g *gitRepo) commit(files map[string]string) plumbing.Hash {
+	worktree, err := g.repo.Worktree()
+	require.NoError(g.t, err)
+	for name, content := range files {
+		path := filepath.Join(g.dir, name)
+		require.NoError(g.t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(g.t, os.WriteFile(path, []byte(content), 0o600))
+	}
+	require.NoError(g.t, worktree.AddWithOptions(&git.AddOptions{All: true}))
+	hash, err := worktree.Commit("update", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	require.NoError(g.t, err)
+	return hash
+}
+
+func newGitListSource(config batchopsv1alpha1.GitConfig) *batchopsv1alpha1.ListSource {
+	return &batchopsv1alpha1.ListSource{
+		ObjectMeta: metav1.ObjectMeta{Name: "tenants", Namespace: "default"},
+		Spec: batchopsv1alpha1.ListSourceSpec{
+			Type: batchopsv1alpha1.GitList,
+			Git:  &config,
+		},
+	}
+}
+
+func TestGetItemsFromGit(t *testing.T) {
+	repo := newGitRepo(t)
+	repo.commit(map[string]string{
+		"tenants/acme/values.yaml":   "name: acme\n",
+		"tenants/globex/values.yaml": "name: globex\n",
+		"tenants/README.md":          "# Tenants\n",
+		"tenants.txt":                "acme\n\n  globex  \n",
+		"tenants.json":               `{"tenants": [{"name": "acme", "tier": 1}, {"name": "globex", "tier": 2}]}`,
+		"regions.json":               `["eu", "us"]`,
+	})
+
+	tests := []struct {
+		name   string
+		config batchopsv1alpha1.GitConfig
+		format batchopsv1alpha1.ItemFormat
+		want   []string
+	}{
+		{
+			name:   "directories",
+			config: batchopsv1alpha1.GitConfig{Paths: &batchopsv1alpha1.GitPaths{Include: []string{"tenants/*"}, Type: batchopsv1alpha1.GitDirectoryPath}},
+			want:   []string{"tenants/acme", "tenants/globex"},
+		},
+		{
+			name: "files by glob",
+			config: batchopsv1alpha1.GitConfig{Paths: &batchopsv1alpha1.GitPaths{
+				Include: []string{"tenants/**"},
+				Exclude: []string{"**/globex/**"},
+				Type:    batchopsv1alpha1.GitFilePath,
+			}},
+			want: []string{"tenants/README.md", "tenants/acme/values.yaml"},
+		},
+		{
+			name:   "lines of a file",
+			config: batchopsv1alpha1.GitConfig{File: &batchopsv1alpha1.GitFile{Path: "tenants.txt"}},
+			want:   []string{"acme", "globex"},
+		},
+		{
+			name:   "top-level JSON array",
+			config: batchopsv1alpha1.GitConfig{File: &batchopsv1alpha1.GitFile{Path: "regions.json", Format: batchopsv1alpha1.GitJSONFormat}},
+			want:   []string{"eu", "us"},
+		},
+		{
+			name: "JSONPath as JSON",
+			config: batchopsv1alpha1.GitConfig{File: &batchopsv1alpha1.GitFile{
+				Path:     "tenants.json",
+				Format:   batchopsv1alpha1.GitJSONFormat,
+				JSONPath: "$.tenants[*]",
+			}},
+			format: batchopsv1alpha1.JSONItemFormat,
+			want:   []string{`{"name":"acme","tier":1}`, `{"name":"globex","tier":2}`},
+		},
+	}
+	r := newAPIReconciler(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := tt.config
+			config.URL = repo.url()
+			listSource := newGitListSource(config)
+			listSource.Spec.ItemFormat = tt.format
+
+			items, err := r.getItems(context.Background(), listSource)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, items)
+		})
+	}
+}
+
+func TestGetItemsFromGit_Refs(t *testing.T) {
+	repo := newGitRepo(t)
+	first := repo.commit(map[string]string{"tenants.txt": "acme\n"})
+	_, err := repo.repo.CreateTag("v1", first, &git.CreateTagOptions{
+		Tagger:  &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+		Message: "v1",
+	})
+	require.NoError(t, err)
+	head, err := repo.repo.Head()
+	require.NoError(t, err)
+	second := repo.commit(map[string]string{"tenants.txt": "acme\nglobex\n"})
+	require.NoError(t, repo.repo.Storer.SetReference(plumbing.NewHashReference("refs/heads/release", first)))
+
+	tests := []struct {
+		name string
+		ref  string
+		want []string
+		sha  plumbing.Hash
+	}{
+		{name: "default branch", want: []string{"acme", "globex"}, sha: second},
+		{name: "branch", ref: head.Name().Short(), want: []string{"acme", "globex"}, sha: second},
+		{name: "other branch", ref: "release", want: []string{"acme"}, sha: first},
+		{name: "annotated tag", ref: "v1", want: []string{"acme"}, sha: first},
+		{name: "full ref", ref: "refs/tags/v1", want: []string{"acme"}, sha: first},
+		{name: "commit", ref: first.String(), want: []string{"acme"}, sha: first},
+	}
+	r := newAPIReconciler(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listSource := newGitListSource(batchopsv1alpha1.GitConfig{
+				URL:  repo.url(),
+				Ref:  tt.ref,
+				File: &batchopsv1alpha1.GitFile{Path: "tenants.txt"},
+			})
+
+			ctx, stats := withFetchStats(context.Background())
+			items, err := r.getItems(ctx, listSource)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, items)
+			assert.Equal(t, tt.sha.String(), stats.revision)
+		})
+	}
+}
+
+func TestGetItemsFromGit_NotRetried(t *testing.T) {
+	repo := newGitRepo(t)
+	repo.commit(map[string]string{"tenants.txt": "acme\n"})
+
+	tests := []struct {
+		name    string
+		config  batchopsv1alpha1.GitConfig
+		wantErr string
+	}{
+		{
+			name:    "missing ref",
+			config:  batchopsv1alpha1.GitConfig{URL: repo.url(), Ref: "missing", File: &batchopsv1alpha1.GitFile{Path: "tenants.txt"}},
+			wantErr: "couldn't find remote ref",
+		},
+		{
+			name:    "missing file",
+			config:  batchopsv1alpha1.GitConfig{URL: repo.url(), File: &batchopsv1alpha1.GitFile{Path: "missing.txt"}},
+			wantErr: "failed to read file missing.txt",
+		},
+		{
+			name:    "file URL",
+			config:  batchopsv1alpha1.GitConfig{URL: "file://" + repo.dir, File: &batchopsv1alpha1.GitFile{Path: "tenants.txt"}},
+			wantErr: "repository URL must be https, http, ssh or git, not file",
+		},
+		{
+			name:    "local path",
+			config:  batchopsv1alpha1.GitConfig{URL: repo.dir, File: &batchopsv1alpha1.GitFile{Path: "tenants.txt"}},
+			wantErr: "repository URL must be https, http, ssh or git, not file",
+		},
+	}
+	r := newAPIReconciler(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listSource := newGitListSource(tt.config)
+			listSource.Spec.FetchPolicy = &batchopsv1alpha1.FetchPolicy{Retries: 3}
+
+			ctx, stats := withFetchStats(context.Background())
+			_, err := r.getItems(ctx, listSource)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+			assert.Equal(t, int32(1), stats.attempts)
+		})
+	}
+}
+
+func TestGitStorage_Size(t *testing.T) {
+	storage := &gitStorage{Storage: memory.NewStorage()}
+	newBlob := func(size int) plumbing.EncodedObject {
+		obj := storage.NewEncodedObject()
+		obj.SetType(plumbing.BlobObject)
+		writer, err := obj.Writer()
+		require.NoError(t, err)
+		_, err = writer.Write(make([]byte, size))
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+		return obj
+	}
+
+	_, err := storage.SetEncodedObject(newBlob(maxGitRepositorySize - 1))
+	require.NoError(t, err)
+	_, err = storage.SetEncodedObject(newBlob(2))
+	assert.ErrorIs(t, err, errGitRepositoryTooLarge)
+	assert.False(t, retryableGitError(err))
+}
+
+func TestKnownHostsCallback(t *testing.T) {
+	newKey := func() ssh.PublicKey {
+		public, _, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+		key, err := ssh.NewPublicKey(public)
+		require.NoError(t, err)
+		return key
+	}
+	github, gitlab, other := newKey(), newKey(), newKey()
+	addr := &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 22}
+
+	callback, err := knownHostsCallback([]byte("# comment\n" +
+		knownhosts.Line([]string{"github.com"}, github) + "\n" +
+		knownhosts.Line([]string{knownhosts.HashHostname(knownhosts.Normalize("gitlab.example.com:2222"))}, gitlab) + "\n" +
+		knownhosts.Line([]string{"*.internal"}, other) + "\n"))
+	require.NoError(t, err)
+
+	assert.NoError(t, callback("github.com:22", addr, github))
+	assert.Error(t, callback("github.com:22", addr, gitlab), "key of another host")
+	assert.Error(t, callback("github.com:2222", addr, github), "host on another port")
+	assert.NoError(t, callback("gitlab.example.com:2222", addr, gitlab))
+	assert.Error(t, callback("gitlab.example.com:22", addr, gitlab), "hashed host on another port")
+	assert.NoError(t, callback("git.internal:22", addr, other))
+
+	_, err = knownHostsCallback([]byte("# nothing\n"))
+	assert.Error(t, err)
+}
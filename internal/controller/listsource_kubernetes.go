@@ -0,0 +1,353 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/jsonpath"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	batchopsv1alpha1 "github.com/matanryngler/parallax/api/v1alpha1"
+)
+
+// defaultKubernetesJSONPath extracts the name of every object.
+const defaultKubernetesJSONPath = "$.metadata.name"
+
+// kubernetesListChunk is the number of objects requested per list call.
+const kubernetesListChunk = 500
+
+// errKubernetesSourceDenied rejects the kinds and namespaces the operator was
+// not configured to list for kubernetes sources.
+var errKubernetesSourceDenied = errors.New("denied by the operator")
+
+func (r *ListSourceReconciler) getItemsFromKubernetes(ctx context.Context, listSource *batchopsv1alpha1.ListSource) ([]string, error) {
+	config := listSource.Spec.Kubernetes
+	gvk := schema.FromAPIVersionAndKind(config.APIVersion, config.Kind)
+	log := log.FromContext(ctx).WithValues(
+		"type", "kubernetes",
+		"gvk", gvk.String(),
+	)
+	log.Info("Listing Kubernetes objects to fetch items")
+
+	jp, err := kubernetesJSONPath(config)
+	if err != nil {
+		return nil, err
+	}
+	source := client.ObjectKeyFromObject(listSource)
+	if err := r.checkKubernetesKind(gvk.GroupKind()); err != nil {
+		r.watches.release(source)
+		return nil, err
+	}
+	namespace, err := r.kubernetesNamespace(listSource, gvk)
+	if err != nil {
+		r.watches.release(source)
+		return nil, err
+	}
+	opts, err := kubernetesListOptions(config, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []string
+	objects := 0
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+	for {
+		if err := r.List(ctx, list, append(opts, client.Continue(list.GetContinue()))...); err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", config.Kind, err)
+		}
+		for i := range list.Items {
+			objectItems, err := extractAPIItems(jp, list.Items[i].Object, listSource.Spec.ItemFormat)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", config.Kind, client.ObjectKeyFromObject(&list.Items[i]), err)
+			}
+			items = append(items, objectItems...)
+		}
+		objects += len(list.Items)
+		if list.GetContinue() == "" {
+			break
+		}
+	}
+	log.V(1).Info("Listed Kubernetes objects", "objects", objects, "items", len(items))
+
+	if config.Watch {
+		if err := r.watches.ensure(source, watchKey{gvk: gvk, namespace: namespace}); err != nil {
+			// The list is still refetched every intervalSeconds
+			log.Error(err, "Failed to watch objects of the kind")
+		}
+	} else {
+		r.watches.release(source)
+	}
+	return items, nil
+}
+
+// checkKubernetesKind refuses the kinds kubernetes sources may not list: those
+// missing from KubernetesKinds and Secrets, whose data would land in a
+// ConfigMap readable by anyone allowed to read the list.
+func (r *ListSourceReconciler) checkKubernetesKind(kind schema.GroupKind) error {
+	if kind == (schema.GroupKind{Kind: "Secret"}) {
+		return fmt.Errorf("listing Secrets is %w", errKubernetesSourceDenied)
+	}
+	if !slices.Contains(r.KubernetesKinds, kind) {
+		return fmt.Errorf("listing %s is %w, it is not among --kubernetes-source-kinds", kind, errKubernetesSourceDenied)
+	}
+	return nil
+}
+
+// kubernetesNamespace returns the namespace a kubernetes source lists, empty
+// for cluster-scoped kinds and all namespaces. Namespaced kinds are listed in
+// the namespace of the ListSource, other namespaces only when
+// KubernetesCrossNamespace allows them.
+func (r *ListSourceReconciler) kubernetesNamespace(listSource *batchopsv1alpha1.ListSource, gvk schema.GroupVersionKind) (string, error) {
+	config := listSource.Spec.Kubernetes
+	object := &unstructured.Unstructured{}
+	object.SetGroupVersionKind(gvk)
+	namespaced, err := r.IsObjectNamespaced(object)
+	if err != nil {
+		return "", fmt.Errorf("failed to look up kind %s: %w", gvk, err)
+	}
+	if !namespaced {
+		return "", nil
+	}
+
+	namespace := cmp.Or(config.Namespace, listSource.Namespace)
+	if r.KubernetesCrossNamespace {
+		if config.AllNamespaces {
+			return "", nil
+		}
+		return namespace, nil
+	}
+	if config.AllNamespaces {
+		return "", fmt.Errorf("listing all namespaces is %w, it requires --kubernetes-source-cross-namespace", errKubernetesSourceDenied)
+	}
+	if namespace != listSource.Namespace {
+		return "", fmt.Errorf("listing namespace %s is %w, it requires --kubernetes-source-cross-namespace", namespace, errKubernetesSourceDenied)
+	}
+	return namespace, nil
+}
+
+// kubernetesJSONPath parses the JSONPath of a kubernetes source, which skips
+// the objects without the fields it selects.
+func kubernetesJSONPath(config *batchopsv1alpha1.KubernetesConfig) (*jsonpath.JSONPath, error) {
+	jp := jsonpath.New("items").AllowMissingKeys(true)
+	if err := jp.Parse(fmt.Sprintf("{%s}", cmp.Or(config.JSONPath, defaultKubernetesJSONPath))); err != nil {
+		return nil, fmt.Errorf("failed to parse JSONPath expression: %w", err)
+	}
+	return jp, nil
+}
+
+// kubernetesListOptions selects the objects of a kubernetes source in
+// namespace, of every namespace when empty.
+func kubernetesListOptions(config *batchopsv1alpha1.KubernetesConfig, namespace string) ([]client.ListOption, error) {
+	opts := []client.ListOption{client.Limit(kubernetesListChunk)}
+	if namespace != "" {
+		opts = append(opts, client.InNamespace(namespace))
+	}
+
+	if config.LabelSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(config.LabelSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid label selector: %w", err)
+		}
+		opts = append(opts, client.MatchingLabelsSelector{Selector: selector})
+	}
+	if config.FieldSelector != "" {
+		selector, err := fields.ParseSelector(config.FieldSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid field selector: %w", err)
+		}
+		opts = append(opts, client.MatchingFieldsSelector{Selector: selector})
+	}
+	return opts, nil
+}
+
+// retryableKubernetesError reports whether a failed list is worth repeating:
+// unknown or denied kinds, missing permissions and rejected selectors are not.
+func retryableKubernetesError(err error) bool {
+	if meta.IsNoMatchError(err) || errors.Is(err, errKubernetesSourceDenied) {
+		return false
+	}
+	switch {
+	case apierrors.IsForbidden(err), apierrors.IsUnauthorized(err),
+		apierrors.IsNotFound(err), apierrors.IsBadRequest(err), apierrors.IsInvalid(err):
+		return false
+	}
+	return true
+}
+
+// watchKey is a kind watched in a namespace, in all namespaces when empty.
+type watchKey struct {
+	gvk       schema.GroupVersionKind
+	namespace string
+}
+
+// kindWatches runs one watch per kind and namespace of kubernetes sources,
+// started once a list of the kind succeeded and stopped once no source
+// watches the kind in the namespace anymore.
+type kindWatches struct {
+	mu      sync.Mutex
+	watches map[watchKey]*kindWatch
+	// start starts a watch and returns the function stopping it, nil until
+	// the controller is built
+	start func(key watchKey) (stop func(), err error)
+}
+
+// kindWatch is a running watch and the sources it serves.
+type kindWatch struct {
+	stop    func()
+	sources sets.Set[types.NamespacedName]
+}
+
+// ensure watches key for source, and releases the watch source used before
+// if it watched another kind or namespace.
+func (w *kindWatches) ensure(source types.NamespacedName, key watchKey) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.start == nil {
+		return nil
+	}
+	w.releaseLocked(source, &key)
+	if watch, ok := w.watches[key]; ok {
+		watch.sources.Insert(source)
+		return nil
+	}
+	stop, err := w.start(key)
+	if err != nil {
+		return err
+	}
+	if w.watches == nil {
+		w.watches = map[watchKey]*kindWatch{}
+	}
+	w.watches[key] = &kindWatch{stop: stop, sources: sets.New(source)}
+	return nil
+}
+
+// release stops the watch of source, once it is deleted or no longer watches.
+func (w *kindWatches) release(source types.NamespacedName) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.releaseLocked(source, nil)
+}
+
+func (w *kindWatches) releaseLocked(source types.NamespacedName, keep *watchKey) {
+	for key, watch := range w.watches {
+		if keep != nil && key == *keep {
+			continue
+		}
+		watch.sources.Delete(source)
+		if watch.sources.Len() == 0 {
+			watch.stop()
+			delete(w.watches, key)
+		}
+	}
+}
+
+// listSourcesWatching returns the kubernetes sources with watch enabled whose
+// list may change with an object, given before and after an update or once
+// for other events.
+func (r *ListSourceReconciler) listSourcesWatching(ctx context.Context, objects ...client.Object) []reconcile.Request {
+	log := log.FromContext(ctx)
+
+	var listSources batchopsv1alpha1.ListSourceList
+	if err := r.List(ctx, &listSources); err != nil {
+		log.Error(err, "Unable to list ListSources watching an object")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range listSources.Items {
+		listSource := &listSources.Items[i]
+		config := listSource.Spec.Kubernetes
+		if listSource.Spec.Type != batchopsv1alpha1.KubernetesList || config == nil || !config.Watch {
+			continue
+		}
+		if watchedChange(listSource, objects) {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{
+				Name:      listSource.Name,
+				Namespace: listSource.Namespace,
+			}})
+		}
+	}
+	return requests
+}
+
+// watchedChange reports whether an event of objects may change the list of a
+// kubernetes source. An update only does when the object enters or leaves the
+// label selector, or yields other items. Field selectors are not evaluated.
+func watchedChange(listSource *batchopsv1alpha1.ListSource, objects []client.Object) bool {
+	config := listSource.Spec.Kubernetes
+	object := objects[0]
+	if object.GetObjectKind().GroupVersionKind() != schema.FromAPIVersionAndKind(config.APIVersion, config.Kind) {
+		return false
+	}
+	if namespace := object.GetNamespace(); namespace != "" && !config.AllNamespaces &&
+		namespace != cmp.Or(config.Namespace, listSource.Namespace) {
+		return false
+	}
+
+	selector := labels.Everything()
+	if config.LabelSelector != nil {
+		var err error
+		if selector, err = metav1.LabelSelectorAsSelector(config.LabelSelector); err != nil {
+			return false
+		}
+	}
+	matches := slices.IndexFunc(objects, func(o client.Object) bool {
+		return selector.Matches(labels.Set(o.GetLabels()))
+	}) >= 0
+	if !matches || len(objects) < 2 || config.FieldSelector != "" {
+		return matches
+	}
+
+	oldLabels, newLabels := labels.Set(objects[0].GetLabels()), labels.Set(objects[1].GetLabels())
+	if selector.Matches(oldLabels) != selector.Matches(newLabels) {
+		return true
+	}
+	jp, err := kubernetesJSONPath(config)
+	if err != nil {
+		return false
+	}
+	var extracted [2][]string
+	for i, o := range objects[:2] {
+		content, ok := o.(*unstructured.Unstructured)
+		if !ok {
+			return true
+		}
+		if extracted[i], err = extractAPIItems(jp, content.Object, listSource.Spec.ItemFormat); err != nil {
+			return true
+		}
+	}
+	return !slices.Equal(extracted[0], extracted[1])
+}
@@ -0,0 +1,299 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	batchopsv1alpha1 "github.com/matanryngler/parallax/api/v1alpha1"
+)
+
+// newKubernetesReconciler returns a reconciler whose client knows the
+// cluster-scoped Namespaces and the namespaced Pods.
+func newKubernetesReconciler(t *testing.T, objects ...client.Object) *ListSourceReconciler {
+	r := newAPIReconciler(t)
+	r.KubernetesKinds = []schema.GroupKind{{Kind: "Namespace"}, {Kind: "Pod"}}
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("Namespace"), meta.RESTScopeRoot)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("Pod"), meta.RESTScopeNamespace)
+	r.Client = fake.NewClientBuilder().
+		WithScheme(r.Scheme).
+		WithRESTMapper(mapper).
+		WithObjects(objects...).
+		WithIndex(&corev1.Pod{}, "spec.nodeName", func(o client.Object) []string {
+			node, _, _ := unstructured.NestedString(o.(*unstructured.Unstructured).Object, "spec", "nodeName")
+			return []string{node}
+		}).
+		Build()
+	return r
+}
+
+func newPod(namespace, name, node string, labels map[string]string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+		Spec:       corev1.PodSpec{NodeName: node},
+	}
+}
+
+func newKubernetesListSource(config batchopsv1alpha1.KubernetesConfig) *batchopsv1alpha1.ListSource {
+	return &batchopsv1alpha1.ListSource{
+		ObjectMeta: metav1.ObjectMeta{Name: "fanout", Namespace: "default"},
+		Spec: batchopsv1alpha1.ListSourceSpec{
+			Type:       batchopsv1alpha1.KubernetesList,
+			Kubernetes: &config,
+		},
+	}
+}
+
+func TestGetItemsFromKubernetes(t *testing.T) {
+	r := newKubernetesReconciler(t,
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Labels: map[string]string{"fanout": "true"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-b", Labels: map[string]string{"fanout": "true"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system"}},
+		newPod("default", "web-1", "node-1", map[string]string{"app": "web"}),
+		newPod("default", "web-2", "node-2", map[string]string{"app": "web"}),
+		newPod("default", "db-1", "node-1", map[string]string{"app": "db"}),
+		newPod("other", "web-3", "node-1", map[string]string{"app": "web"}),
+	)
+	r.KubernetesCrossNamespace = true
+	webPods := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}
+
+	tests := []struct {
+		name   string
+		config batchopsv1alpha1.KubernetesConfig
+		format batchopsv1alpha1.ItemFormat
+		want   []string
+	}{
+		{
+			name: "cluster-scoped by label",
+			config: batchopsv1alpha1.KubernetesConfig{
+				APIVersion:    "v1",
+				Kind:          "Namespace",
+				LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"fanout": "true"}},
+			},
+			want: []string{"team-a", "team-b"},
+		},
+		{
+			name:   "namespace of the ListSource",
+			config: batchopsv1alpha1.KubernetesConfig{APIVersion: "v1", Kind: "Pod", LabelSelector: webPods},
+			want:   []string{"web-1", "web-2"},
+		},
+		{
+			name:   "other namespace",
+			config: batchopsv1alpha1.KubernetesConfig{APIVersion: "v1", Kind: "Pod", Namespace: "other"},
+			want:   []string{"web-3"},
+		},
+		{
+			name: "all namespaces as JSON",
+			config: batchopsv1alpha1.KubernetesConfig{
+				APIVersion:    "v1",
+				Kind:          "Pod",
+				AllNamespaces: true,
+				LabelSelector: webPods,
+				JSONPath:      "$.metadata['namespace', 'name']",
+			},
+			format: batchopsv1alpha1.JSONItemFormat,
+			want:   []string{`"default"`, `"web-1"`, `"default"`, `"web-2"`, `"other"`, `"web-3"`},
+		},
+		{
+			name:   "field selector",
+			config: batchopsv1alpha1.KubernetesConfig{APIVersion: "v1", Kind: "Pod", FieldSelector: "spec.nodeName=node-1"},
+			want:   []string{"db-1", "web-1"},
+		},
+		{
+			name:   "objects without a match yield nothing",
+			config: batchopsv1alpha1.KubernetesConfig{APIVersion: "v1", Kind: "Pod", JSONPath: "$.metadata.annotations.owner"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listSource := newKubernetesListSource(tt.config)
+			listSource.Spec.ItemFormat = tt.format
+			items, err := r.getItems(context.Background(), listSource)
+			require.NoError(t, err)
+			assert.ElementsMatch(t, tt.want, items)
+		})
+	}
+}
+
+func TestGetItemsFromKubernetes_UnknownKind(t *testing.T) {
+	r := newKubernetesReconciler(t)
+	r.KubernetesKinds = append(r.KubernetesKinds, schema.GroupKind{Group: "example.com", Kind: "Widget"})
+	listSource := newKubernetesListSource(batchopsv1alpha1.KubernetesConfig{APIVersion: "example.com/v1", Kind: "Widget"})
+	listSource.Spec.FetchPolicy = &batchopsv1alpha1.FetchPolicy{Retries: 3}
+
+	ctx, stats := withFetchStats(context.Background())
+	_, err := r.getItems(ctx, listSource)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "example.com/v1, Kind=Widget")
+	assert.Equal(t, int32(1), stats.attempts, "unknown kinds are not retried")
+}
+
+func TestGetItemsFromKubernetes_Denied(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  batchopsv1alpha1.KubernetesConfig
+		wantErr string
+	}{
+		{
+			name:    "secrets",
+			config:  batchopsv1alpha1.KubernetesConfig{APIVersion: "v1", Kind: "Secret"},
+			wantErr: "listing Secrets is denied by the operator",
+		},
+		{
+			name:    "kind not allowed",
+			config:  batchopsv1alpha1.KubernetesConfig{APIVersion: "v1", Kind: "ConfigMap"},
+			wantErr: "listing ConfigMap is denied by the operator, it is not among --kubernetes-source-kinds",
+		},
+		{
+			name:    "other namespace",
+			config:  batchopsv1alpha1.KubernetesConfig{APIVersion: "v1", Kind: "Pod", Namespace: "other"},
+			wantErr: "listing namespace other is denied by the operator",
+		},
+		{
+			name:    "all namespaces",
+			config:  batchopsv1alpha1.KubernetesConfig{APIVersion: "v1", Kind: "Pod", AllNamespaces: true},
+			wantErr: "listing all namespaces is denied by the operator",
+		},
+	}
+	r := newKubernetesReconciler(t, newPod("other", "web-1", "node-1", nil))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listSource := newKubernetesListSource(tt.config)
+			listSource.Spec.FetchPolicy = &batchopsv1alpha1.FetchPolicy{Retries: 3}
+
+			ctx, stats := withFetchStats(context.Background())
+			_, err := r.getItems(ctx, listSource)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+			assert.Equal(t, int32(1), stats.attempts, "denied lists are not retried")
+		})
+	}
+
+	// The namespace of the ListSource may be named explicitly
+	listSource := newKubernetesListSource(batchopsv1alpha1.KubernetesConfig{APIVersion: "v1", Kind: "Pod", Namespace: "default"})
+	_, err := r.getItems(context.Background(), listSource)
+	assert.NoError(t, err)
+}
+
+func TestGetItemsFromKubernetes_Watches(t *testing.T) {
+	r := newKubernetesReconciler(t, newPod("default", "web-1", "node-1", nil))
+	r.KubernetesCrossNamespace = true
+	var started, stopped []watchKey
+	r.watches.start = func(key watchKey) (func(), error) {
+		started = append(started, key)
+		return func() { stopped = append(stopped, key) }, nil
+	}
+	pods := watchKey{gvk: corev1.SchemeGroupVersion.WithKind("Pod"), namespace: "default"}
+	allPods := watchKey{gvk: corev1.SchemeGroupVersion.WithKind("Pod")}
+
+	listSource := newKubernetesListSource(batchopsv1alpha1.KubernetesConfig{APIVersion: "v1", Kind: "Pod", Watch: true})
+	other := listSource.DeepCopy()
+	other.Name = "other"
+	for _, source := range []*batchopsv1alpha1.ListSource{listSource, listSource, other} {
+		items, err := r.getItems(context.Background(), source)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"web-1"}, items)
+	}
+	assert.Equal(t, []watchKey{pods}, started, "a kind is watched once per namespace")
+
+	// Moving to all namespaces starts a cluster-wide watch, the namespaced one
+	// still serves the other source
+	listSource.Spec.Kubernetes.AllNamespaces = true
+	_, err := r.getItems(context.Background(), listSource)
+	require.NoError(t, err)
+	assert.Equal(t, []watchKey{pods, allPods}, started)
+	assert.Empty(t, stopped)
+
+	// Watches stop once no source uses them
+	listSource.Spec.Kubernetes.Watch = false
+	_, err = r.getItems(context.Background(), listSource)
+	require.NoError(t, err)
+	assert.Equal(t, []watchKey{allPods}, stopped)
+	r.watches.release(client.ObjectKeyFromObject(other))
+	assert.Equal(t, []watchKey{allPods, pods}, stopped)
+	assert.Empty(t, r.watches.watches)
+}
+
+func TestListSourcesWatching(t *testing.T) {
+	watching := func(name string, config batchopsv1alpha1.KubernetesConfig) *batchopsv1alpha1.ListSource {
+		listSource := newKubernetesListSource(config)
+		listSource.Name = name
+		return listSource
+	}
+	r := newKubernetesReconciler(t,
+		watching("web", batchopsv1alpha1.KubernetesConfig{
+			APIVersion:    "v1",
+			Kind:          "Pod",
+			LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			Watch:         true,
+		}),
+		watching("images", batchopsv1alpha1.KubernetesConfig{
+			APIVersion:    "v1",
+			Kind:          "Pod",
+			AllNamespaces: true,
+			JSONPath:      "$.spec.containers[*].image",
+			Watch:         true,
+		}),
+		watching("polled", batchopsv1alpha1.KubernetesConfig{APIVersion: "v1", Kind: "Pod"}),
+		watching("namespaces", batchopsv1alpha1.KubernetesConfig{APIVersion: "v1", Kind: "Namespace", Watch: true}),
+	)
+
+	pod := func(namespace string, labels map[string]string, image string) client.Object {
+		p := newPod(namespace, "p", "", labels)
+		p.Spec.Containers = []corev1.Container{{Name: "main", Image: image}}
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(p)
+		require.NoError(t, err)
+		object := &unstructured.Unstructured{Object: content}
+		object.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Pod"))
+		return object
+	}
+	web := map[string]string{"app": "web"}
+
+	tests := []struct {
+		name    string
+		objects []client.Object
+		want    []string
+	}{
+		{name: "created", objects: []client.Object{pod("default", web, "nginx")}, want: []string{"images", "web"}},
+		{name: "other namespace", objects: []client.Object{pod("other", web, "nginx")}, want: []string{"images"}},
+		{name: "unchanged items", objects: []client.Object{pod("default", web, "nginx"), pod("default", web, "nginx")}},
+		{name: "items changed", objects: []client.Object{pod("default", nil, "nginx"), pod("default", nil, "httpd")}, want: []string{"images"}},
+		{name: "left the selector", objects: []client.Object{pod("default", web, "nginx"), pod("default", nil, "nginx")}, want: []string{"web"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var names []string
+			for _, request := range r.listSourcesWatching(context.Background(), tt.objects...) {
+				names = append(names, request.Name)
+			}
+			assert.ElementsMatch(t, tt.want, names)
+		})
+	}
+}
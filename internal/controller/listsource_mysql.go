@@ -0,0 +1,187 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/tls"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/go-sql-driver/mysql"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	batchopsv1alpha1 "github.com/matanryngler/parallax/api/v1alpha1"
+)
+
+const defaultMySQLPort = 3306
+
+func (r *ListSourceReconciler) getItemsFromMySQL(ctx context.Context, listSource *batchopsv1alpha1.ListSource) ([]string, error) {
+	config := listSource.Spec.MySQL
+	log := log.FromContext(ctx).WithValues(
+		"type", "mysql",
+		"namespace", listSource.Namespace,
+		"query", config.Query,
+	)
+	if config.Auth != nil {
+		secretID := fmt.Sprintf("Secret/%s.%s", config.Auth.SecretRef.Name, config.Auth.SecretRef.Namespace)
+		log = log.WithValues("auth_secret", secretID)
+	}
+	log.Info("Starting MySQL query to fetch items")
+
+	open := func(ctx context.Context) (*sql.DB, error) {
+		return r.openMySQL(ctx, config, listSource.Namespace)
+	}
+	return r.queryDatabase(logr.NewContext(ctx, log), listSource, open, config.Query, config.Parameters)
+}
+
+// openMySQL opens the database of a MySQL source with the credentials of its
+// Secret. The password is never logged.
+func (r *ListSourceReconciler) openMySQL(ctx context.Context, config *batchopsv1alpha1.MySQLConfig, namespace string) (*sql.DB, error) {
+	log := log.FromContext(ctx)
+
+	user, password, err := r.databaseCredentials(ctx, namespace, config.User, config.Auth)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig, err := r.mysqlTLSConfig(ctx, config, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure TLS: %w", err)
+	}
+	cfg, err := mysqlConfig(config, user, password, tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+	connector, err := mysql.NewConnector(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MySQL connection settings: %w", err)
+	}
+
+	log.V(1).Info("Establishing database connection",
+		"address", cfg.Addr,
+		"database", cfg.DBName,
+		"user", cfg.User,
+		"sslmode", mysqlSSLMode(config),
+		"password_set", cfg.Passwd != "",
+	)
+	return sql.OpenDB(connector), nil
+}
+
+// mysqlSSLMode returns the sslMode a MySQL source asks for, empty when it is
+// left to the DSN. TLS settings imply verify-identity, and a Host without
+// them preferred, like the mysql client does.
+func mysqlSSLMode(config *batchopsv1alpha1.MySQLConfig) batchopsv1alpha1.MySQLSSLMode {
+	switch {
+	case config.SSLMode != "":
+		return config.SSLMode
+	case config.TLS != nil:
+		return batchopsv1alpha1.MySQLSSLModeVerifyIdentity
+	case config.DSN == "":
+		return batchopsv1alpha1.MySQLSSLModePreferred
+	}
+	return ""
+}
+
+// mysqlConfig builds the driver configuration of a MySQL source. Settings
+// override those of the DSN. Times are read as time.Time in UTC, so that they
+// convert like those of other databases.
+func mysqlConfig(config *batchopsv1alpha1.MySQLConfig, user, password string, tlsConfig *tls.Config) (*mysql.Config, error) {
+	cfg := mysql.NewConfig()
+	if config.DSN != "" {
+		var err error
+		if cfg, err = mysql.ParseDSN(config.DSN); err != nil {
+			// The error could quote the DSN, including any password in it
+			return nil, errors.New("DSN is not a valid MySQL data source name")
+		}
+	}
+
+	if config.Host != "" {
+		port := config.Port
+		if port == 0 {
+			port = defaultMySQLPort
+		}
+		cfg.Net = "tcp"
+		cfg.Addr = net.JoinHostPort(config.Host, strconv.Itoa(int(port)))
+	}
+	if config.Database != "" {
+		cfg.DBName = config.Database
+	}
+	if user != "" {
+		cfg.User = user
+	}
+	if password != "" {
+		cfg.Passwd = password
+	}
+	if config.ConnectTimeout != nil {
+		cfg.Timeout = config.ConnectTimeout.Duration
+	}
+	cfg.ParseTime = true
+	cfg.Loc = time.UTC
+
+	switch mode := mysqlSSLMode(config); mode {
+	case "":
+		// TLS as configured by the DSN
+	case batchopsv1alpha1.MySQLSSLModeDisabled:
+		cfg.TLS = nil
+		cfg.TLSConfig = "false"
+	default:
+		cfg.TLS = tlsConfig
+		cfg.AllowFallbackToPlaintext = mode == batchopsv1alpha1.MySQLSSLModePreferred
+	}
+	return cfg, nil
+}
+
+// mysqlTLSConfig builds the TLS configuration of a MySQL source, nil when TLS
+// is left to the DSN or disabled. The sslModes keep their mysql client meaning.
+func (r *ListSourceReconciler) mysqlTLSConfig(ctx context.Context, config *batchopsv1alpha1.MySQLConfig, namespace string) (*tls.Config, error) {
+	mode := mysqlSSLMode(config)
+	if mode == "" || mode == batchopsv1alpha1.MySQLSSLModeDisabled {
+		return nil, nil
+	}
+
+	var ca *batchopsv1alpha1.CABundleRef
+	var clientCert *batchopsv1alpha1.ClientCertificateRef
+	if config.TLS != nil {
+		ca, clientCert = config.TLS.CA, config.TLS.ClientCertificate
+	}
+	tlsConfig, _, err := r.tlsConfig(ctx, namespace, ca, clientCert)
+	if err != nil {
+		return nil, err
+	}
+
+	switch mode {
+	case batchopsv1alpha1.MySQLSSLModePreferred, batchopsv1alpha1.MySQLSSLModeRequired:
+		if ca == nil {
+			// Encrypted without verifying the server
+			tlsConfig.InsecureSkipVerify = true // #nosec G402
+			break
+		}
+		// With a CA, the server is verified like verify-ca
+		verifyCertificateChain(tlsConfig)
+	case batchopsv1alpha1.MySQLSSLModeVerifyCA:
+		verifyCertificateChain(tlsConfig)
+	case batchopsv1alpha1.MySQLSSLModeVerifyIdentity:
+		// The driver verifies the host of the address when ServerName is unset
+		tlsConfig.ServerName = config.Host
+	}
+	return tlsConfig, nil
+}
@@ -0,0 +1,199 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-logr/logr/funcr"
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	batchopsv1alpha1 "github.com/matanryngler/parallax/api/v1alpha1"
+)
+
+func TestMySQLConfig(t *testing.T) {
+	t.Run("structured", func(t *testing.T) {
+		cfg, err := mysqlConfig(&batchopsv1alpha1.MySQLConfig{
+			Host:           "db.internal",
+			Database:       "app",
+			ConnectTimeout: &metav1.Duration{Duration: 5 * time.Second},
+		}, "svc", "s3cret", &tls.Config{})
+		require.NoError(t, err)
+		assert.Equal(t, "tcp", cfg.Net)
+		assert.Equal(t, "db.internal:3306", cfg.Addr)
+		assert.Equal(t, "app", cfg.DBName)
+		assert.Equal(t, "svc", cfg.User)
+		assert.Equal(t, "s3cret", cfg.Passwd)
+		assert.Equal(t, 5*time.Second, cfg.Timeout)
+		assert.True(t, cfg.ParseTime)
+		assert.Equal(t, time.UTC, cfg.Loc)
+		assert.NotNil(t, cfg.TLS)
+		assert.True(t, cfg.AllowFallbackToPlaintext, "a host without sslMode prefers TLS")
+	})
+
+	t.Run("DSN with settings", func(t *testing.T) {
+		cfg, err := mysqlConfig(&batchopsv1alpha1.MySQLConfig{
+			DSN:      "svc@tcp(db:3307)/app?tls=skip-verify&readTimeout=10s",
+			Database: "other",
+			SSLMode:  batchopsv1alpha1.MySQLSSLModeDisabled,
+		}, "", "s3cret", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "db:3307", cfg.Addr)
+		assert.Equal(t, "other", cfg.DBName)
+		assert.Equal(t, "svc", cfg.User)
+		assert.Equal(t, "s3cret", cfg.Passwd)
+		assert.Equal(t, 10*time.Second, cfg.ReadTimeout)
+		assert.Nil(t, cfg.TLS)
+	})
+
+	t.Run("DSN keeps its TLS setting", func(t *testing.T) {
+		cfg, err := mysqlConfig(&batchopsv1alpha1.MySQLConfig{DSN: "svc@tcp(db)/app?tls=skip-verify"}, "", "", nil)
+		require.NoError(t, err)
+		require.NotNil(t, cfg.TLS)
+		assert.True(t, cfg.TLS.InsecureSkipVerify)
+	})
+
+	t.Run("invalid DSN", func(t *testing.T) {
+		_, err := mysqlConfig(&batchopsv1alpha1.MySQLConfig{DSN: "svc:hunter2@tcp(db/app"}, "", "", nil)
+		require.Error(t, err)
+		assert.NotContains(t, err.Error(), "hunter2")
+	})
+}
+
+func TestMySQLTLSConfig(t *testing.T) {
+	ca := newTestCA(t)
+	r := newAPIReconciler(t)
+	require.NoError(t, r.Create(context.Background(), &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "db-ca", Namespace: "default"},
+		Data:       map[string][]byte{"ca.crt": ca.certPEM},
+	}))
+	withCA := &batchopsv1alpha1.DatabaseTLSConfig{
+		CA: &batchopsv1alpha1.CABundleRef{SecretRef: &batchopsv1alpha1.SecretRef{Name: "db-ca", Key: "ca.crt"}},
+	}
+
+	tests := []struct {
+		name       string
+		config     batchopsv1alpha1.MySQLConfig
+		wantNil    bool
+		wantVerify bool
+		wantServer string
+	}{
+		{name: "left to the DSN", config: batchopsv1alpha1.MySQLConfig{DSN: "svc@tcp(db)/app"}, wantNil: true},
+		{name: "disabled", config: batchopsv1alpha1.MySQLConfig{Host: "db", SSLMode: batchopsv1alpha1.MySQLSSLModeDisabled}, wantNil: true},
+		{name: "preferred by default", config: batchopsv1alpha1.MySQLConfig{Host: "db"}},
+		{name: "required with CA verifies the chain", config: batchopsv1alpha1.MySQLConfig{
+			Host: "db", SSLMode: batchopsv1alpha1.MySQLSSLModeRequired, TLS: withCA,
+		}, wantVerify: true},
+		{name: "TLS settings imply verify-identity", config: batchopsv1alpha1.MySQLConfig{
+			Host: "db.internal", TLS: withCA,
+		}, wantServer: "db.internal"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tlsConfig, err := r.mysqlTLSConfig(context.Background(), &tt.config, "default")
+			require.NoError(t, err)
+			if tt.wantNil {
+				assert.Nil(t, tlsConfig)
+				return
+			}
+			require.NotNil(t, tlsConfig)
+			assert.Equal(t, tt.wantServer, tlsConfig.ServerName)
+			assert.Equal(t, tt.wantServer == "", tlsConfig.InsecureSkipVerify)
+			assert.Equal(t, tt.wantVerify, tlsConfig.VerifyConnection != nil)
+		})
+	}
+}
+
+func TestOpenMySQL_NeverLogsPassword(t *testing.T) {
+	r := newAPIReconciler(t)
+	ctx := context.Background()
+	require.NoError(t, r.Create(ctx, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "db-credentials", Namespace: "default"},
+		Data:       map[string][]byte{"username": []byte("svc"), "password": []byte("hunter2")},
+	}))
+
+	var logs bytes.Buffer
+	ctx = log.IntoContext(ctx, funcr.New(func(prefix, args string) {
+		logs.WriteString(prefix + args + "\n")
+	}, funcr.Options{Verbosity: 10}))
+
+	db, err := r.openMySQL(ctx, &batchopsv1alpha1.MySQLConfig{
+		Host:     "db.internal",
+		Database: "app",
+		SSLMode:  batchopsv1alpha1.MySQLSSLModeDisabled,
+		Auth: &batchopsv1alpha1.DatabaseAuth{
+			SecretRef:   batchopsv1alpha1.SecretRef{Name: "db-credentials", Key: "password"},
+			UsernameKey: "username",
+			PasswordKey: "password",
+		},
+	}, "default")
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	assert.Contains(t, logs.String(), `"user"="svc"`)
+	assert.NotContains(t, logs.String(), "hunter2")
+}
+
+func TestListSourceController_MySQL(t *testing.T) {
+	listSource := &batchopsv1alpha1.ListSource{
+		ObjectMeta: metav1.ObjectMeta{Name: "tenants", Namespace: "default", Finalizers: []string{listSourceFinalizer}},
+		Spec: batchopsv1alpha1.ListSourceSpec{
+			Type: batchopsv1alpha1.MySQLList,
+			MySQL: &batchopsv1alpha1.MySQLConfig{
+				Host:       "db",
+				Query:      "SELECT id, name, created_at FROM tenants WHERE plan = ?",
+				Parameters: []batchopsv1alpha1.QueryParameter{{Value: ptr.To("pro")}},
+			},
+		},
+	}
+	r := newStaticListReconciler(t, listSource)
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+	created := time.Date(2025, 3, 14, 9, 26, 53, 0, time.UTC)
+	mock.ExpectQuery(listSource.Spec.MySQL.Query).WithArgs("pro").WillReturnRows(
+		sqlmock.NewRowsWithColumnDefinition(
+			sqlmock.NewColumn("id").OfType("UNSIGNED BIGINT", uint64(0)),
+			sqlmock.NewColumn("name").OfType("VARCHAR", ""),
+			sqlmock.NewColumn("created_at").OfType("DATETIME", time.Time{}),
+		).AddRow(uint64(1), []byte("acme"), created))
+
+	ctx := context.WithValue(context.Background(), dbKey, db)
+	items, err := r.getItems(ctx, listSource)
+	require.NoError(t, err)
+	assert.Equal(t, []string{`{"created_at":"2025-03-14T09:26:53Z","id":1,"name":"acme"}`}, items)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRetryableDBError(t *testing.T) {
+	assert.False(t, retryableDBError(&mysql.MySQLError{Number: 1045, Message: "Access denied"}))
+	assert.False(t, retryableDBError(&mysql.MySQLError{Number: 1146, Message: "Table doesn't exist"}))
+	assert.True(t, retryableDBError(&mysql.MySQLError{Number: 1040, Message: "Too many connections"}))
+	assert.True(t, retryableDBError(mysql.ErrInvalidConn))
+}
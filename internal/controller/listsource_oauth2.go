@@ -0,0 +1,136 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	batchopsv1alpha1 "github.com/matanryngler/parallax/api/v1alpha1"
+)
+
+const defaultOAuth2ClientIDKey = "client_id"
+
+// oauth2TokenCache keeps the token sources of API sources using oauth2 auth,
+// so that an access token is reused by every fetch until it expires. A token
+// source is replaced once its configuration or credentials change.
+type oauth2TokenCache struct {
+	mu      sync.Mutex
+	sources map[types.NamespacedName]cachedTokenSource
+}
+
+type cachedTokenSource struct {
+	fingerprint [sha256.Size]byte
+	source      oauth2.TokenSource
+}
+
+// get returns the token source of a ListSource, building it with build when
+// there is none or the fingerprint changed.
+func (c *oauth2TokenCache) get(key types.NamespacedName, fingerprint [sha256.Size]byte, build func() oauth2.TokenSource) oauth2.TokenSource {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if cached, ok := c.sources[key]; ok && cached.fingerprint == fingerprint {
+		return cached.source
+	}
+	source := build()
+	if c.sources == nil {
+		c.sources = map[types.NamespacedName]cachedTokenSource{}
+	}
+	c.sources[key] = cachedTokenSource{fingerprint: fingerprint, source: source}
+	return source
+}
+
+// forget drops the token source, and with it the cached token, of a ListSource.
+func (c *oauth2TokenCache) forget(key types.NamespacedName) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.sources, key)
+}
+
+// apiTokenSource returns the token source of an API source using oauth2
+// auth, nil for other auth types. Tokens are requested through httpClient,
+// so the TLS settings of the API apply to the token endpoint as well.
+func (r *ListSourceReconciler) apiTokenSource(ctx context.Context, listSource *batchopsv1alpha1.ListSource, httpClient *http.Client) (oauth2.TokenSource, error) {
+	key := types.NamespacedName{Name: listSource.Name, Namespace: listSource.Namespace}
+	auth := listSource.Spec.API.Auth
+	if auth == nil || auth.Type != batchopsv1alpha1.OAuth2Auth {
+		r.tokens.forget(key)
+		return nil, nil
+	}
+	if auth.OAuth2 == nil {
+		return nil, fmt.Errorf("oauth2 auth requires the oauth2 settings")
+	}
+
+	secret, err := r.getSecret(ctx, listSource.Namespace, auth.SecretRef)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get auth secret: %w", err)
+	}
+	clientIDKey := auth.OAuth2.ClientIDKey
+	if clientIDKey == "" {
+		clientIDKey = defaultOAuth2ClientIDKey
+	}
+
+	params := url.Values{}
+	for name, value := range auth.OAuth2.EndpointParams {
+		params.Set(name, value)
+	}
+	if auth.OAuth2.Audience != "" {
+		params.Set("audience", auth.OAuth2.Audience)
+	}
+	config := &clientcredentials.Config{
+		ClientID:       secret[clientIDKey],
+		ClientSecret:   secret[auth.SecretRef.Key],
+		TokenURL:       auth.OAuth2.TokenURL,
+		Scopes:         auth.OAuth2.Scopes,
+		EndpointParams: params,
+	}
+
+	// The token source outlives this fetch, so does the transport it uses
+	encoded, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode OAuth2 settings: %w", err)
+	}
+	fingerprint := sha256.Sum256(fmt.Appendf(encoded, "%p", httpClient.Transport))
+
+	return r.tokens.get(key, fingerprint, func() oauth2.TokenSource {
+		log.FromContext(ctx).V(1).Info("Configured OAuth2 client credentials", "token_url", config.TokenURL)
+		tokenCtx := context.WithValue(context.Background(), oauth2.HTTPClient, httpClient)
+		return config.TokenSource(tokenCtx)
+	}), nil
+}
+
+// authorizeWithToken returns header with the Authorization of a token from source.
+func authorizeWithToken(header http.Header, source oauth2.TokenSource) (http.Header, error) {
+	token, err := source.Token()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get OAuth2 token: %w", err)
+	}
+	header = header.Clone()
+	header.Set("Authorization", token.Type()+" "+token.AccessToken)
+	return header, nil
+}
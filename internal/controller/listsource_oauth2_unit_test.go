@@ -0,0 +1,160 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	batchopsv1alpha1 "github.com/matanryngler/parallax/api/v1alpha1"
+)
+
+// tokenServer issues numbered access tokens and records the token requests.
+type tokenServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []url.Values
+}
+
+func newTokenServer(t *testing.T) *tokenServer {
+	ts := &tokenServer{}
+	ts.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		clientID, clientSecret, _ := r.BasicAuth()
+		if clientID != "parallax" || clientSecret != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		ts.mu.Lock()
+		ts.requests = append(ts.requests, r.PostForm)
+		issued := len(ts.requests)
+		ts.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token":"token-%d","token_type":"bearer","expires_in":3600}`, issued)
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func newOAuth2ListSource(apiURL, tokenURL string) *batchopsv1alpha1.ListSource {
+	listSource := newAPIListSource(apiURL, nil)
+	listSource.Spec.API.Auth = &batchopsv1alpha1.APIAuth{
+		Type:      batchopsv1alpha1.OAuth2Auth,
+		SecretRef: batchopsv1alpha1.SecretRef{Name: "oauth-client", Key: "client_secret"},
+		OAuth2: &batchopsv1alpha1.OAuth2ClientCredentials{
+			TokenURL:       tokenURL,
+			Scopes:         []string{"inventory.read", "inventory.list"},
+			Audience:       "https://inventory.internal",
+			EndpointParams: map[string]string{"resource": "assets"},
+		},
+	}
+	return listSource
+}
+
+func newOAuth2Reconciler(t *testing.T) *ListSourceReconciler {
+	r := newAPIReconciler(t)
+	require.NoError(t, r.Create(context.Background(), &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "oauth-client", Namespace: "default"},
+		Data: map[string][]byte{
+			"client_id":     []byte("parallax"),
+			"client_secret": []byte("s3cret"),
+		},
+	}))
+	return r
+}
+
+func TestGetItemsFromAPI_OAuth2(t *testing.T) {
+	t.Run("token is requested once and reused", func(t *testing.T) {
+		tokens := newTokenServer(t)
+		var authorizations []string
+		api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authorizations = append(authorizations, r.Header.Get("Authorization"))
+			writePage(w, 0, 2, "")
+		}))
+		defer api.Close()
+
+		r := newOAuth2Reconciler(t)
+		listSource := newOAuth2ListSource(api.URL, tokens.URL)
+		for range 2 {
+			items, err := r.getItemsFromAPI(context.Background(), listSource)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"a", "b"}, items)
+		}
+
+		assert.Equal(t, []string{"Bearer token-1", "Bearer token-1"}, authorizations)
+		require.Len(t, tokens.requests, 1)
+		request := tokens.requests[0]
+		assert.Equal(t, "client_credentials", request.Get("grant_type"))
+		assert.Equal(t, "inventory.read inventory.list", request.Get("scope"))
+		assert.Equal(t, "https://inventory.internal", request.Get("audience"))
+		assert.Equal(t, "assets", request.Get("resource"))
+	})
+
+	t.Run("rejected token is refreshed", func(t *testing.T) {
+		tokens := newTokenServer(t)
+		api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// The first token was revoked
+			if r.Header.Get("Authorization") == "Bearer token-1" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			writePage(w, 0, 2, "")
+		}))
+		defer api.Close()
+
+		items, err := newOAuth2Reconciler(t).getItemsFromAPI(context.Background(), newOAuth2ListSource(api.URL, tokens.URL))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "b"}, items)
+		assert.Len(t, tokens.requests, 2)
+	})
+
+	t.Run("token is refreshed only once", func(t *testing.T) {
+		tokens := newTokenServer(t)
+		api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer api.Close()
+
+		_, err := newOAuth2Reconciler(t).getItemsFromAPI(context.Background(), newOAuth2ListSource(api.URL, tokens.URL))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "status 401")
+		assert.Len(t, tokens.requests, 2)
+	})
+
+	t.Run("rejected client credentials", func(t *testing.T) {
+		tokens := newTokenServer(t)
+		r := newOAuth2Reconciler(t)
+		listSource := newOAuth2ListSource("http://127.0.0.1:1", tokens.URL)
+		listSource.Spec.API.Auth.OAuth2.ClientIDKey = "client_secret"
+
+		_, err := r.getItemsFromAPI(context.Background(), listSource)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to get OAuth2 token")
+	})
+}
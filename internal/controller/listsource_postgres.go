@@ -0,0 +1,282 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/lib/pq" // PostgreSQL driver
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	batchopsv1alpha1 "github.com/matanryngler/parallax/api/v1alpha1"
+)
+
+const defaultPostgresPort = 5432
+
+// defaultPostgresConnectTimeout bounds, in seconds, connecting to a server
+// whose connection string sets no connect_timeout.
+const defaultPostgresConnectTimeout = "10"
+
+func (r *ListSourceReconciler) getItemsFromPostgres(ctx context.Context, listSource *batchopsv1alpha1.ListSource) ([]string, error) {
+	config := listSource.Spec.Postgres
+	log := log.FromContext(ctx).WithValues(
+		"type", "postgresql",
+		"namespace", listSource.Namespace,
+		"query", config.Query,
+	)
+	if config.Auth != nil {
+		secretID := fmt.Sprintf("Secret/%s.%s", config.Auth.SecretRef.Name, config.Auth.SecretRef.Namespace)
+		log = log.WithValues("auth_secret", secretID)
+	}
+	log.Info("Starting PostgreSQL query to fetch items")
+
+	open := func(ctx context.Context) (*sql.DB, error) {
+		return r.openPostgres(ctx, config, listSource.Namespace)
+	}
+	return r.queryDatabase(logr.NewContext(ctx, log), listSource, open, config.Query, config.Parameters)
+}
+
+// openPostgres opens the database of a PostgreSQL source with the
+// credentials of its Secret. The connection string holds the password and is
+// never logged.
+func (r *ListSourceReconciler) openPostgres(ctx context.Context, config *batchopsv1alpha1.PostgresConfig, namespace string) (*sql.DB, error) {
+	log := log.FromContext(ctx)
+
+	user, password, err := r.databaseCredentials(ctx, namespace, config.User, config.Auth)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig, err := r.postgresTLSConfig(ctx, config, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure TLS: %w", err)
+	}
+	dsn, err := postgresDSN(config, user, password, tlsConfig != nil)
+	if err != nil {
+		return nil, err
+	}
+	connector, err := pq.NewConnector(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PostgreSQL connection settings: %w", err)
+	}
+	if tlsConfig != nil {
+		connector.Dialer(postgresTLSDialer{config: tlsConfig})
+	}
+
+	log.V(1).Info("Establishing database connection",
+		"host", config.Host,
+		"database", config.Database,
+		"user", user,
+		"sslmode", postgresSSLMode(config),
+		"password_set", password != "",
+	)
+	return sql.OpenDB(connector), nil
+}
+
+// postgresSSLMode returns the sslmode a PostgreSQL source asks for, empty
+// when it is left to the connection string. TLS settings imply verify-full.
+func postgresSSLMode(config *batchopsv1alpha1.PostgresConfig) batchopsv1alpha1.PostgresSSLMode {
+	if config.SSLMode == "" && config.TLS != nil {
+		return batchopsv1alpha1.SSLModeVerifyFull
+	}
+	return config.SSLMode
+}
+
+// postgresDSN builds the libpq connection string of a PostgreSQL source.
+// Settings are appended to the ConnectionString, where they take precedence.
+// When tlsDialer is set, TLS is negotiated by postgresTLSDialer and lib/pq
+// must not attempt it itself.
+func postgresDSN(config *batchopsv1alpha1.PostgresConfig, user, password string, tlsDialer bool) (string, error) {
+	dsn := config.ConnectionString
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		converted, err := pq.ParseURL(dsn)
+		if err != nil {
+			// The error would quote the URL, including any password in it
+			return "", fmt.Errorf("connection string is not a valid URL")
+		}
+		dsn = converted
+	}
+
+	var params [][2]string
+	if config.Host != "" {
+		port := config.Port
+		if port == 0 {
+			port = defaultPostgresPort
+		}
+		params = append(params, [2]string{"host", config.Host}, [2]string{"port", strconv.Itoa(int(port))})
+	}
+	if config.Database != "" {
+		params = append(params, [2]string{"dbname", config.Database})
+	}
+	if user != "" {
+		params = append(params, [2]string{"user", user})
+	}
+	if password != "" {
+		params = append(params, [2]string{"password", password})
+	}
+	switch {
+	case tlsDialer:
+		params = append(params, [2]string{"sslmode", string(batchopsv1alpha1.SSLModeDisable)})
+	case config.SSLMode != "":
+		params = append(params, [2]string{"sslmode", string(config.SSLMode)})
+	case config.TLS == nil && !strings.Contains(dsn, "sslmode="):
+		// lib/pq would default to require, sources without any TLS settings
+		// keep connecting without TLS as in earlier releases
+		params = append(params, [2]string{"sslmode", string(batchopsv1alpha1.SSLModeDisable)})
+	}
+	if !strings.Contains(dsn, "connect_timeout=") {
+		params = append(params, [2]string{"connect_timeout", defaultPostgresConnectTimeout})
+	}
+
+	var b strings.Builder
+	b.WriteString(dsn)
+	for _, param := range params {
+		if b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(param[0])
+		b.WriteString("='")
+		b.WriteString(strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(param[1]))
+		b.WriteByte('\'')
+	}
+	return b.String(), nil
+}
+
+// postgresTLSConfig builds the TLS configuration of a PostgreSQL source, nil
+// when TLS is left to the connection string or disabled. The sslmodes keep
+// their libpq meaning.
+func (r *ListSourceReconciler) postgresTLSConfig(ctx context.Context, config *batchopsv1alpha1.PostgresConfig, namespace string) (*tls.Config, error) {
+	mode := postgresSSLMode(config)
+	if mode == "" || mode == batchopsv1alpha1.SSLModeDisable {
+		return nil, nil
+	}
+
+	var ca *batchopsv1alpha1.CABundleRef
+	var clientCert *batchopsv1alpha1.ClientCertificateRef
+	if config.TLS != nil {
+		ca, clientCert = config.TLS.CA, config.TLS.ClientCertificate
+	}
+	tlsConfig, _, err := r.tlsConfig(ctx, namespace, ca, clientCert)
+	if err != nil {
+		return nil, err
+	}
+
+	switch mode {
+	case batchopsv1alpha1.SSLModeRequire:
+		if ca == nil {
+			// sslmode require encrypts without verifying the server
+			tlsConfig.InsecureSkipVerify = true // #nosec G402
+			break
+		}
+		// With a CA, require verifies like verify-ca
+		verifyCertificateChain(tlsConfig)
+	case batchopsv1alpha1.SSLModeVerifyCA:
+		verifyCertificateChain(tlsConfig)
+	case batchopsv1alpha1.SSLModeVerifyFull:
+		tlsConfig.ServerName = config.Host
+	}
+	return tlsConfig, nil
+}
+
+// verifyCertificateChain makes tlsConfig verify the certificate chain of the
+// server, but not its host name.
+func verifyCertificateChain(tlsConfig *tls.Config) {
+	roots := tlsConfig.RootCAs
+	// The chain is verified by VerifyConnection
+	tlsConfig.InsecureSkipVerify = true // #nosec G402
+	tlsConfig.VerifyConnection = func(state tls.ConnectionState) error {
+		if len(state.PeerCertificates) == 0 {
+			return errors.New("server presented no certificate")
+		}
+		opts := x509.VerifyOptions{Roots: roots, Intermediates: x509.NewCertPool()}
+		for _, cert := range state.PeerCertificates[1:] {
+			opts.Intermediates.AddCert(cert)
+		}
+		_, err := state.PeerCertificates[0].Verify(opts)
+		return err
+	}
+}
+
+// postgresSSLRequest asks a PostgreSQL server to switch to TLS, see
+// https://www.postgresql.org/docs/current/protocol-flow.html#PROTOCOL-FLOW-SSL
+var postgresSSLRequest = []byte{0, 0, 0, 8, 0x04, 0xd2, 0x16, 0x2f}
+
+// postgresTLSDialer opens TLS connections for lib/pq, so that certificates
+// from Secrets are used without writing them to files. lib/pq runs with
+// sslmode=disable on top of the connections it returns.
+type postgresTLSDialer struct {
+	config *tls.Config
+}
+
+func (d postgresTLSDialer) Dial(network, address string) (net.Conn, error) {
+	return d.DialContext(context.Background(), network, address)
+}
+
+func (d postgresTLSDialer) DialTimeout(network, address string, timeout time.Duration) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return d.DialContext(ctx, network, address)
+}
+
+func (d postgresTLSDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if _, err := conn.Write(postgresSSLRequest); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to request TLS: %w", err)
+	}
+	reply := make([]byte, 1)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to request TLS: %w", err)
+	}
+	if reply[0] != 'S' {
+		conn.Close()
+		return nil, errors.New("server does not support TLS connections")
+	}
+
+	config := d.config
+	if !config.InsecureSkipVerify && config.ServerName == "" {
+		config = config.Clone()
+		config.ServerName, _, _ = net.SplitHostPort(address)
+	}
+	tlsConn := tls.Client(conn, config)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("TLS handshake failed: %w", err)
+	}
+	_ = conn.SetDeadline(time.Time{})
+	return tlsConn, nil
+}
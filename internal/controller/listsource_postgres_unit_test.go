@@ -0,0 +1,235 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr/funcr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	batchopsv1alpha1 "github.com/matanryngler/parallax/api/v1alpha1"
+)
+
+func TestPostgresDSN(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    batchopsv1alpha1.PostgresConfig
+		user      string
+		password  string
+		tlsDialer bool
+		want      string
+	}{
+		{
+			name:     "structured",
+			config:   batchopsv1alpha1.PostgresConfig{Host: "db.internal", Database: "app", SSLMode: batchopsv1alpha1.SSLModeRequire},
+			user:     "svc",
+			password: `it's a \secret`,
+			want:     `host='db.internal' port='5432' dbname='app' user='svc' password='it\'s a \\secret' sslmode='require' connect_timeout='10'`,
+		},
+		{
+			name:     "connection string",
+			config:   batchopsv1alpha1.PostgresConfig{ConnectionString: "host=db dbname=app"},
+			password: "s3cret",
+			want:     `host=db dbname=app password='s3cret' sslmode='disable' connect_timeout='10'`,
+		},
+		{
+			name:   "connection URL",
+			config: batchopsv1alpha1.PostgresConfig{ConnectionString: "postgres://svc@db:5433/app"},
+			user:   "other",
+			want:   `dbname='app' host='db' port='5433' user='svc' user='other' sslmode='disable' connect_timeout='10'`,
+		},
+		{
+			name:   "connect timeout of the connection string",
+			config: batchopsv1alpha1.PostgresConfig{ConnectionString: "postgres://svc@db/app?connect_timeout=3"},
+			want:   `connect_timeout='3' dbname='app' host='db' user='svc' sslmode='disable'`,
+		},
+		{
+			name:   "sslmode of the connection string",
+			config: batchopsv1alpha1.PostgresConfig{ConnectionString: "postgres://svc@db/app?sslmode=verify-full"},
+			want:   `dbname='app' host='db' sslmode='verify-full' user='svc' connect_timeout='10'`,
+		},
+		{
+			name:      "TLS negotiated by the dialer",
+			config:    batchopsv1alpha1.PostgresConfig{Host: "db", Port: 6432, SSLMode: batchopsv1alpha1.SSLModeVerifyFull},
+			tlsDialer: true,
+			want:      `host='db' port='6432' sslmode='disable' connect_timeout='10'`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dsn, err := postgresDSN(&tt.config, tt.user, tt.password, tt.tlsDialer)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, dsn)
+		})
+	}
+
+	_, err := postgresDSN(&batchopsv1alpha1.PostgresConfig{ConnectionString: "postgres://svc:hunter2@db:port/app"}, "", "", false)
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "hunter2")
+}
+
+func TestOpenPostgres_NeverLogsPassword(t *testing.T) {
+	r := newAPIReconciler(t)
+	ctx := context.Background()
+	require.NoError(t, r.Create(ctx, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "db-credentials", Namespace: "default"},
+		Data:       map[string][]byte{"username": []byte("svc"), "password": []byte("hunter2")},
+	}))
+
+	var logs bytes.Buffer
+	ctx = log.IntoContext(ctx, funcr.New(func(prefix, args string) {
+		logs.WriteString(prefix + args + "\n")
+	}, funcr.Options{Verbosity: 10}))
+
+	db, err := r.openPostgres(ctx, &batchopsv1alpha1.PostgresConfig{
+		Host:     "db.internal",
+		Database: "app",
+		SSLMode:  batchopsv1alpha1.SSLModeDisable,
+		Auth: &batchopsv1alpha1.DatabaseAuth{
+			SecretRef:   batchopsv1alpha1.SecretRef{Name: "db-credentials", Key: "password"},
+			UsernameKey: "username",
+			PasswordKey: "password",
+		},
+	}, "default")
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	assert.Contains(t, logs.String(), `"user"="svc"`)
+	assert.NotContains(t, logs.String(), "hunter2")
+}
+
+// startPostgresTLSServer accepts one connection, answers the SSLRequest with
+// reply and completes a TLS handshake when it accepted.
+func startPostgresTLSServer(t *testing.T, ca *testCA, reply byte, ips []net.IP) string {
+	certPEM, keyPEM := ca.issue(t, nil, ips, x509.ExtKeyUsageServerAuth)
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(t, err)
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		request := make([]byte, len(postgresSSLRequest))
+		if _, err := io.ReadFull(conn, request); err != nil || !bytes.Equal(request, postgresSSLRequest) {
+			return
+		}
+		if _, err := conn.Write([]byte{reply}); err != nil || reply != 'S' {
+			return
+		}
+		server := tls.Server(conn, &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			ClientCAs:    pool,
+			ClientAuth:   tls.RequireAndVerifyClientCert,
+		})
+		if server.Handshake() == nil {
+			_, _ = server.Write([]byte("ok"))
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func TestPostgresTLSDialer(t *testing.T) {
+	ca := newTestCA(t)
+	clientCertPEM, clientKeyPEM := ca.issue(t, nil, nil, x509.ExtKeyUsageClientAuth)
+	localhost := []net.IP{net.ParseIP("127.0.0.1")}
+
+	r := newAPIReconciler(t)
+	require.NoError(t, r.Create(context.Background(), &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "db-tls", Namespace: "default"},
+		Data:       map[string][]byte{"ca.crt": ca.certPEM, "tls.crt": clientCertPEM, "tls.key": clientKeyPEM},
+	}))
+	config := func(mode batchopsv1alpha1.PostgresSSLMode) *batchopsv1alpha1.PostgresConfig {
+		return &batchopsv1alpha1.PostgresConfig{
+			SSLMode: mode,
+			TLS: &batchopsv1alpha1.DatabaseTLSConfig{
+				CA:                &batchopsv1alpha1.CABundleRef{SecretRef: &batchopsv1alpha1.SecretRef{Name: "db-tls", Key: "ca.crt"}},
+				ClientCertificate: &batchopsv1alpha1.ClientCertificateRef{SecretName: "db-tls"},
+			},
+		}
+	}
+	dial := func(t *testing.T, config *batchopsv1alpha1.PostgresConfig, address string) error {
+		tlsConfig, err := r.postgresTLSConfig(context.Background(), config, "default")
+		require.NoError(t, err)
+		conn, err := postgresTLSDialer{config: tlsConfig}.DialContext(context.Background(), "tcp", address)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		reply, err := io.ReadAll(conn)
+		require.NoError(t, err)
+		assert.Equal(t, "ok", string(reply))
+		return nil
+	}
+
+	t.Run("verify-full with client certificate", func(t *testing.T) {
+		require.NoError(t, dial(t, config(batchopsv1alpha1.SSLModeVerifyFull), startPostgresTLSServer(t, ca, 'S', localhost)))
+	})
+
+	t.Run("verify-full checks the host name", func(t *testing.T) {
+		err := dial(t, config(batchopsv1alpha1.SSLModeVerifyFull), startPostgresTLSServer(t, ca, 'S', nil))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "127.0.0.1")
+	})
+
+	t.Run("verify-ca ignores the host name", func(t *testing.T) {
+		require.NoError(t, dial(t, config(batchopsv1alpha1.SSLModeVerifyCA), startPostgresTLSServer(t, ca, 'S', nil)))
+	})
+
+	t.Run("verify-ca checks the CA", func(t *testing.T) {
+		other := newTestCA(t)
+		err := dial(t, config(batchopsv1alpha1.SSLModeVerifyCA), startPostgresTLSServer(t, other, 'S', localhost))
+		require.Error(t, err)
+		assert.True(t, strings.Contains(err.Error(), "certificate"), err.Error())
+	})
+
+	t.Run("server without TLS", func(t *testing.T) {
+		err := dial(t, config(batchopsv1alpha1.SSLModeVerifyFull), startPostgresTLSServer(t, ca, 'N', localhost))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "does not support TLS")
+	})
+
+	t.Run("disable and unset modes leave TLS to lib/pq", func(t *testing.T) {
+		for _, config := range []*batchopsv1alpha1.PostgresConfig{
+			{SSLMode: batchopsv1alpha1.SSLModeDisable},
+			{ConnectionString: "host=db sslmode=verify-full sslrootcert=/etc/ca.crt"},
+		} {
+			tlsConfig, err := r.postgresTLSConfig(context.Background(), config, "default")
+			require.NoError(t, err)
+			assert.Nil(t, tlsConfig)
+		}
+	})
+}
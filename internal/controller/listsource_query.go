@@ -0,0 +1,215 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	mssql "github.com/microsoft/go-mssqldb"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	batchopsv1alpha1 "github.com/matanryngler/parallax/api/v1alpha1"
+)
+
+// dbKeyType is the type of the context key under which tests pass a mock
+// database to the fetch of a database source.
+type dbKeyType string
+
+const dbKey dbKeyType = "db"
+
+// queryDatabase runs the query of a database source and converts its rows
+// into items. open connects to the database, unless a mock database is passed
+// in the context.
+func (r *ListSourceReconciler) queryDatabase(ctx context.Context, listSource *batchopsv1alpha1.ListSource,
+	open func(context.Context) (*sql.DB, error), query string, params []batchopsv1alpha1.QueryParameter) ([]string, error) {
+	log := log.FromContext(ctx)
+
+	// Check if we have a mock DB in the context (for testing)
+	var db *sql.DB
+	if mockDB, ok := ctx.Value(dbKey).(*sql.DB); ok {
+		log.V(1).Info("Using mock database from context")
+		db = mockDB
+	} else {
+		var err error
+		if db, err = open(ctx); err != nil {
+			return nil, err
+		}
+		defer db.Close()
+	}
+
+	args, err := r.queryArgs(ctx, listSource, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify connection
+	log.V(1).Info("Verifying database connection")
+	if err := db.PingContext(ctx); err != nil {
+		log.Error(err, "Database connection test failed")
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	// Execute query
+	log.V(1).Info("Executing database query", "query", query, "parameters", len(args))
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Error(err, "Database query failed")
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	items, err := scanItems(rows, listSource.Spec.ItemFormat)
+	if err != nil {
+		log.Error(err, "Failed to read query results")
+		return nil, err
+	}
+
+	log.Info("Successfully executed database query", "items_found", len(items))
+	return items, nil
+}
+
+// databaseCredentials returns the user and password of a database source,
+// read from the Secret of auth when it is set.
+func (r *ListSourceReconciler) databaseCredentials(ctx context.Context, namespace, user string, auth *batchopsv1alpha1.DatabaseAuth) (string, string, error) {
+	if auth == nil {
+		return user, "", nil
+	}
+
+	log := log.FromContext(ctx)
+	log.V(1).Info("Retrieving database credentials")
+	secretData, err := r.getSecret(ctx, namespace, auth.SecretRef)
+	if err != nil {
+		log.Error(err, "Failed to retrieve database credentials")
+		return "", "", fmt.Errorf("failed to get secret: %w", err)
+	}
+	if auth.UsernameKey != "" {
+		user = secretData[auth.UsernameKey]
+	}
+	password, ok := secretData[auth.PasswordKey]
+	if !ok {
+		return "", "", fmt.Errorf("database credentials secret %s has no key %s", auth.SecretRef.Name, auth.PasswordKey)
+	}
+	return user, password, nil
+}
+
+// queryArgs resolves the parameters of a query into the arguments bound to
+// its placeholders. Values from Secrets are not logged.
+func (r *ListSourceReconciler) queryArgs(ctx context.Context, listSource *batchopsv1alpha1.ListSource, params []batchopsv1alpha1.QueryParameter) ([]interface{}, error) {
+	log := log.FromContext(ctx)
+
+	args := make([]interface{}, 0, len(params))
+	for i, param := range params {
+		name := fmt.Sprintf("%d", i+1)
+		if param.Name != "" {
+			name = fmt.Sprintf("%s (%s)", name, param.Name)
+		}
+
+		switch {
+		case param.Value != nil:
+			args = append(args, *param.Value)
+
+		case param.SecretRef != nil:
+			secret, err := r.getSecret(ctx, listSource.Namespace, *param.SecretRef)
+			if err != nil {
+				return nil, fmt.Errorf("query parameter %s: %w", name, err)
+			}
+			value, ok := secret[param.SecretRef.Key]
+			if !ok {
+				return nil, fmt.Errorf("query parameter %s: secret %s has no key %s", name, param.SecretRef.Name, param.SecretRef.Key)
+			}
+			args = append(args, value)
+
+		case param.ConfigMapRef != nil:
+			data, err := r.getConfigMap(ctx, listSource.Namespace, *param.ConfigMapRef)
+			if err != nil {
+				return nil, fmt.Errorf("query parameter %s: %w", name, err)
+			}
+			value, ok := data[param.ConfigMapRef.Key]
+			if !ok {
+				return nil, fmt.Errorf("query parameter %s: ConfigMap %s has no key %s", name, param.ConfigMapRef.Name, param.ConfigMapRef.Key)
+			}
+			args = append(args, value)
+
+		case param.Runtime != "":
+			value, err := runtimeQueryValue(ctx, listSource, param.Runtime)
+			if err != nil {
+				return nil, fmt.Errorf("query parameter %s: %w", name, err)
+			}
+			log.V(1).Info("Resolved runtime query parameter", "parameter", name, "value", value)
+			args = append(args, value)
+
+		default:
+			return nil, fmt.Errorf("query parameter %s has no value", name)
+		}
+	}
+	return args, nil
+}
+
+// runtimeQueryValue returns a value known at fetch time. Timestamps are passed
+// in UTC, nil standing for NULL.
+func runtimeQueryValue(ctx context.Context, listSource *batchopsv1alpha1.ListSource, value batchopsv1alpha1.QueryRuntimeValue) (interface{}, error) {
+	switch value {
+	case batchopsv1alpha1.LastSuccessfulFetchTimeValue:
+		if listSource.Status.LastSuccessfulFetchTime == nil {
+			return nil, nil
+		}
+		return listSource.Status.LastSuccessfulFetchTime.UTC(), nil
+	case batchopsv1alpha1.FetchTimeValue:
+		if stats := fetchStatsFrom(ctx); stats != nil {
+			return stats.started.UTC(), nil
+		}
+		return time.Now().Truncate(time.Second).UTC(), nil
+	}
+	return nil, fmt.Errorf("unknown runtime value %q", value)
+}
+
+// retryableDBError reports whether a failed database fetch is worth repeating.
+// Errors in the query and rejected credentials are not.
+func retryableDBError(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code.Class() {
+		case "28", "42": // invalid authorization, syntax error or access rule violation
+			return false
+		}
+	}
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		switch mysqlErr.Number {
+		case 1044, 1045, 1142, 1143: // access denied to the database, user, table or column
+			return false
+		case 1054, 1064, 1146, 1149: // unknown column or table, syntax errors
+			return false
+		}
+	}
+	var mssqlErr mssql.Error
+	if errors.As(err, &mssqlErr) {
+		switch mssqlErr.Number {
+		case 229, 230, 18456: // permission denied, login failed
+			return false
+		case 102, 156, 207, 208: // syntax errors, invalid column or object name
+			return false
+		}
+	}
+	return true
+}
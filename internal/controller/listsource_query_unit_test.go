@@ -0,0 +1,113 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	batchopsv1alpha1 "github.com/matanryngler/parallax/api/v1alpha1"
+)
+
+func TestGetItemsFromPostgres_Parameters(t *testing.T) {
+	r := newAPIReconciler(t)
+	ctx := context.Background()
+	require.NoError(t, r.Create(ctx, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "tenant-token", Namespace: "default"},
+		Data:       map[string][]byte{"token": []byte("s3cret'; DROP TABLE items; --")},
+	}))
+	require.NoError(t, r.Create(ctx, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "tenant", Namespace: "default"},
+		Data:       map[string]string{"region": "eu-west-1"},
+	}))
+
+	lastFetch := metav1.NewTime(time.Date(2025, 3, 14, 9, 0, 0, 0, time.FixedZone("CET", 3600)))
+	listSource := &batchopsv1alpha1.ListSource{
+		ObjectMeta: metav1.ObjectMeta{Name: "changed", Namespace: "default"},
+		Spec: batchopsv1alpha1.ListSourceSpec{
+			Type: batchopsv1alpha1.PostgresList,
+			Postgres: &batchopsv1alpha1.PostgresConfig{
+				Host:  "db",
+				Query: "SELECT id FROM items WHERE updated_at > $1 AND updated_at <= $2 AND status = $3 AND token = $4 AND region = $5",
+				Parameters: []batchopsv1alpha1.QueryParameter{
+					{Name: "since", Runtime: batchopsv1alpha1.LastSuccessfulFetchTimeValue},
+					{Name: "until", Runtime: batchopsv1alpha1.FetchTimeValue},
+					{Value: ptr.To("pending")},
+					{SecretRef: &batchopsv1alpha1.SecretRef{Name: "tenant-token", Key: "token"}},
+					{ConfigMapRef: &batchopsv1alpha1.ConfigMapRef{Name: "tenant", Key: "region"}},
+				},
+			},
+		},
+		Status: batchopsv1alpha1.ListSourceStatus{LastSuccessfulFetchTime: &lastFetch},
+	}
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+	fetchCtx, stats := withFetchStats(context.WithValue(ctx, dbKey, db))
+	mock.ExpectQuery(listSource.Spec.Postgres.Query).
+		WithArgs(lastFetch.UTC(), stats.started.UTC(), "pending", "s3cret'; DROP TABLE items; --", "eu-west-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("7"))
+
+	items, err := r.getItemsFromPostgres(fetchCtx, listSource)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"7"}, items)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestQueryArgs(t *testing.T) {
+	r := newAPIReconciler(t)
+	ctx := context.Background()
+	listSource := &batchopsv1alpha1.ListSource{ObjectMeta: metav1.ObjectMeta{Name: "first", Namespace: "default"}}
+
+	t.Run("last successful fetch before the first one", func(t *testing.T) {
+		args, err := r.queryArgs(ctx, listSource, []batchopsv1alpha1.QueryParameter{
+			{Runtime: batchopsv1alpha1.LastSuccessfulFetchTimeValue},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []interface{}{nil}, args)
+	})
+
+	t.Run("missing key", func(t *testing.T) {
+		require.NoError(t, r.Create(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "default"},
+			Data:       map[string]string{"region": "eu-west-1"},
+		}))
+		_, err := r.queryArgs(ctx, listSource, []batchopsv1alpha1.QueryParameter{
+			{Value: ptr.To("")},
+			{Name: "tier", ConfigMapRef: &batchopsv1alpha1.ConfigMapRef{Name: "settings", Key: "tier"}},
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "query parameter 2 (tier): ConfigMap settings has no key tier")
+	})
+
+	t.Run("missing secret", func(t *testing.T) {
+		_, err := r.queryArgs(ctx, listSource, []batchopsv1alpha1.QueryParameter{
+			{SecretRef: &batchopsv1alpha1.SecretRef{Name: "absent", Key: "token"}},
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "query parameter 1")
+	})
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	batchopsv1alpha1 "github.com/matanryngler/parallax/api/v1alpha1"
+)
+
+// scanItems converts the rows of a query into items. A single column yields
+// its values as text, formatted by format, and rows where it is NULL are
+// skipped. Several columns yield one JSON object per row, keyed by column
+// name, whatever the format.
+func scanItems(rows *sql.Rows, format batchopsv1alpha1.ItemFormat) ([]string, error) {
+	columns, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read result columns: %w", err)
+	}
+	seen := make(map[string]bool, len(columns))
+	for _, column := range columns {
+		if seen[column.Name()] {
+			return nil, fmt.Errorf("query returns column %q more than once, give the columns distinct names", column.Name())
+		}
+		seen[column.Name()] = true
+	}
+
+	values := make([]interface{}, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+
+	var items []string
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		if len(columns) == 1 {
+			value := convertColumn(values[0], columns[0].DatabaseTypeName())
+			if value == nil {
+				continue
+			}
+			items = append(items, columnText(value))
+			continue
+		}
+
+		row := make(map[string]interface{}, len(columns))
+		for i, column := range columns {
+			row[column.Name()] = convertColumn(values[i], column.DatabaseTypeName())
+		}
+		item, err := json.Marshal(row)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode row as JSON: %w", err)
+		}
+		items = append(items, string(item))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	if len(columns) == 1 {
+		return formatStringItems(items, format)
+	}
+	return items, nil
+}
+
+// convertColumn converts a scanned value into its JSON representation, using
+// the database type of its column for the values drivers return as bytes.
+// Timestamps become RFC 3339 strings and dates YYYY-MM-DD.
+func convertColumn(value interface{}, dbType string) interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case time.Time:
+		if dbType == "DATE" {
+			return v.Format(time.DateOnly)
+		}
+		return v.Format(time.RFC3339Nano)
+	case []byte:
+		return convertBytesColumn(v, dbType)
+	default:
+		return v
+	}
+}
+
+func convertBytesColumn(value []byte, dbType string) interface{} {
+	text := string(value)
+	switch dbType {
+	case "JSON", "JSONB":
+		if json.Valid(value) {
+			var compact bytes.Buffer
+			if json.Compact(&compact, value) == nil {
+				return json.RawMessage(compact.Bytes())
+			}
+		}
+	case "INT", "INT2", "INT4", "INT8", "INTEGER", "SMALLINT", "BIGINT", "TINYINT", "MEDIUMINT",
+		"UNSIGNED INT", "UNSIGNED BIGINT", "UNSIGNED SMALLINT", "UNSIGNED TINYINT", "UNSIGNED MEDIUMINT":
+		if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return n
+		}
+		if n, err := strconv.ParseUint(text, 10, 64); err == nil {
+			return n
+		}
+	case "FLOAT", "FLOAT4", "FLOAT8", "DOUBLE", "REAL":
+		if f, err := strconv.ParseFloat(text, 64); err == nil {
+			return f
+		}
+	case "NUMERIC", "DECIMAL":
+		// Kept as a number literal, without losing precision to float64
+		if _, err := strconv.ParseFloat(text, 64); err == nil {
+			return json.Number(text)
+		}
+	case "BOOL", "BOOLEAN":
+		if b, err := strconv.ParseBool(text); err == nil {
+			return b
+		}
+	case "BYTEA", "BLOB", "TINYBLOB", "MEDIUMBLOB", "LONGBLOB", "BINARY", "VARBINARY":
+		return base64.StdEncoding.EncodeToString(value)
+	case "UNIQUEIDENTIFIER":
+		// SQL Server GUIDs come as bytes, the first three groups little-endian
+		if len(value) == 16 {
+			return fmt.Sprintf("%X-%X-%X-%X-%X",
+				[]byte{value[3], value[2], value[1], value[0]}, []byte{value[5], value[4]},
+				[]byte{value[7], value[6]}, value[8:10], value[10:])
+		}
+	}
+	// Text, UUIDs, and whatever the driver formats as text
+	return text
+}
+
+// columnText returns a converted value as the text of a single column item.
+func columnText(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case json.RawMessage:
+		return string(v)
+	case json.Number:
+		return v.String()
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	batchopsv1alpha1 "github.com/matanryngler/parallax/api/v1alpha1"
+)
+
+// queryItems runs a query returning rows against a mock database and scans
+// the result with the given item format.
+func queryItems(t *testing.T, rows *sqlmock.Rows, format batchopsv1alpha1.ItemFormat) ([]string, error) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	mock.ExpectQuery("SELECT").WillReturnRows(rows)
+
+	result, err := db.Query("SELECT")
+	require.NoError(t, err)
+	defer result.Close()
+	return scanItems(result, format)
+}
+
+func TestScanItems_MultipleColumns(t *testing.T) {
+	created := time.Date(2025, 3, 14, 9, 26, 53, 0, time.UTC)
+	rows := sqlmock.NewRowsWithColumnDefinition(
+		sqlmock.NewColumn("id").OfType("INT8", int64(0)),
+		sqlmock.NewColumn("uuid").OfType("UUID", ""),
+		sqlmock.NewColumn("price").OfType("NUMERIC", ""),
+		sqlmock.NewColumn("active").OfType("BOOL", false),
+		sqlmock.NewColumn("created").OfType("TIMESTAMPTZ", time.Time{}),
+		sqlmock.NewColumn("day").OfType("DATE", time.Time{}),
+		sqlmock.NewColumn("labels").OfType("JSONB", ""),
+		sqlmock.NewColumn("note").OfType("TEXT", "").Nullable(true),
+	).
+		AddRow([]byte("7"), []byte("5b6c0f0e-3f4c-4a8e-9d7f-2f1e0c9b8a71"), []byte("19.990"), []byte("t"),
+			created, created, []byte(`{ "tier": "gold" }`), nil).
+		AddRow(int64(8), []byte("0e9d3b1c-8a4f-4c2e-b6d1-7f5a2c9e4b30"), []byte("0.5"), false,
+			created, created, []byte(`[]`), []byte("hello"))
+
+	items, err := queryItems(t, rows, batchopsv1alpha1.TextItemFormat)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		`{"active":true,"created":"2025-03-14T09:26:53Z","day":"2025-03-14","id":7,"labels":{"tier":"gold"},` +
+			`"note":null,"price":19.990,"uuid":"5b6c0f0e-3f4c-4a8e-9d7f-2f1e0c9b8a71"}`,
+		`{"active":false,"created":"2025-03-14T09:26:53Z","day":"2025-03-14","id":8,"labels":[],` +
+			`"note":"hello","price":0.5,"uuid":"0e9d3b1c-8a4f-4c2e-b6d1-7f5a2c9e4b30"}`,
+	}, items)
+}
+
+func TestScanItems_SingleColumn(t *testing.T) {
+	newRows := func() *sqlmock.Rows {
+		return sqlmock.NewRowsWithColumnDefinition(sqlmock.NewColumn("name").OfType("TEXT", "").Nullable(true)).
+			AddRow([]byte("alpha")).
+			AddRow(nil).
+			AddRow([]byte("beta"))
+	}
+
+	items, err := queryItems(t, newRows(), batchopsv1alpha1.TextItemFormat)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alpha", "beta"}, items, "NULL rows are skipped")
+
+	items, err = queryItems(t, sqlmock.NewRowsWithColumnDefinition(sqlmock.NewColumn("doc").OfType("JSONB", "")).
+		AddRow([]byte(`{"id": 1}`)), batchopsv1alpha1.JSONItemFormat)
+	require.NoError(t, err)
+	assert.Equal(t, []string{`{"id":1}`}, items)
+
+	_, err = queryItems(t, newRows(), batchopsv1alpha1.JSONItemFormat)
+	require.Error(t, err, "text rows are not JSON items")
+}
+
+func TestScanItems_DuplicateColumns(t *testing.T) {
+	rows := sqlmock.NewRowsWithColumnDefinition(
+		sqlmock.NewColumn("id").OfType("INT4", int64(0)),
+		sqlmock.NewColumn("id").OfType("INT4", int64(0)),
+	).AddRow(int64(1), int64(2))
+
+	_, err := queryItems(t, rows, batchopsv1alpha1.TextItemFormat)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `column "id" more than once`)
+}
+
+func TestGetItemsFromPostgres_MockDB(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	require.NoError(t, err)
+	defer db.Close()
+	mock.ExpectPing()
+	mock.ExpectQuery("SELECT id, name FROM tenants").WillReturnRows(
+		sqlmock.NewRowsWithColumnDefinition(
+			sqlmock.NewColumn("id").OfType("INT4", int64(0)),
+			sqlmock.NewColumn("name").OfType("VARCHAR", ""),
+		).AddRow(int64(1), []byte("acme")))
+
+	r := newAPIReconciler(t)
+	items, err := r.getItemsFromPostgres(context.WithValue(context.Background(), dbKey, db), &batchopsv1alpha1.ListSource{
+		ObjectMeta: metav1.ObjectMeta{Name: "tenants", Namespace: "default"},
+		Spec: batchopsv1alpha1.ListSourceSpec{
+			Type:     batchopsv1alpha1.PostgresList,
+			Postgres: &batchopsv1alpha1.PostgresConfig{Host: "db", Query: "SELECT id, name FROM tenants"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{`{"id":1,"name":"acme"}`}, items)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,215 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	batchopsv1alpha1 "github.com/matanryngler/parallax/api/v1alpha1"
+)
+
+const defaultS3Endpoint = "https://s3.amazonaws.com"
+
+// s3Object is the structured item of an object.
+type s3Object struct {
+	Key          string `json:"key"`
+	Size         int64  `json:"size"`
+	ETag         string `json:"etag"`
+	LastModified string `json:"lastModified"`
+}
+
+func (r *ListSourceReconciler) getItemsFromS3(ctx context.Context, listSource *batchopsv1alpha1.ListSource) ([]string, error) {
+	config := listSource.Spec.S3
+	log := log.FromContext(ctx).WithValues(
+		"type", "s3",
+		"endpoint", cmp.Or(config.Endpoint, defaultS3Endpoint),
+		"bucket", config.Bucket,
+		"prefix", config.Prefix,
+	)
+	log.Info("Listing S3 objects to fetch items")
+
+	filter, err := newPathFilter(config.Include, config.Exclude, config.KeyRegex)
+	if err != nil {
+		return nil, err
+	}
+	var modifiedAfter time.Time
+	if config.ModifiedAfter != nil {
+		modifiedAfter = config.ModifiedAfter.Time
+	}
+	if config.ModifiedWithin != nil {
+		modifiedAfter = maxTime(modifiedAfter, time.Now().Add(-config.ModifiedWithin.Duration))
+	}
+
+	client, err := r.s3Client(ctx, listSource)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []string
+	listed := 0
+	// Pages through ListObjectsV2, 1000 keys at a time
+	for object := range client.ListObjects(ctx, config.Bucket, minio.ListObjectsOptions{
+		Prefix:    config.Prefix,
+		Recursive: true,
+	}) {
+		if object.Err != nil {
+			return nil, fmt.Errorf("failed to list bucket %s: %w", config.Bucket, object.Err)
+		}
+		listed++
+		if strings.HasSuffix(object.Key, "/") || !filter.matches(object.Key) ||
+			!object.LastModified.After(modifiedAfter) {
+			continue
+		}
+		if listSource.Spec.ItemFormat != batchopsv1alpha1.JSONItemFormat {
+			items = append(items, object.Key)
+			continue
+		}
+		item, err := encodeS3Object(object)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	log.V(1).Info("Listed S3 objects", "objects", listed, "items", len(items))
+	return items, nil
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func encodeS3Object(object minio.ObjectInfo) (string, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(s3Object{
+		Key:          object.Key,
+		Size:         object.Size,
+		ETag:         strings.Trim(object.ETag, `"`),
+		LastModified: object.LastModified.UTC().Format(time.RFC3339),
+	}); err != nil {
+		return "", fmt.Errorf("failed to encode object %s as JSON: %w", object.Key, err)
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// s3Client returns a client of the endpoint of an S3 source. Requests are not
+// retried by the client, retries are left to the fetch policy.
+func (r *ListSourceReconciler) s3Client(ctx context.Context, listSource *batchopsv1alpha1.ListSource) (*minio.Client, error) {
+	config := listSource.Spec.S3
+	endpoint, err := url.Parse(cmp.Or(config.Endpoint, defaultS3Endpoint))
+	if err != nil || endpoint.Host == "" || (endpoint.Scheme != "http" && endpoint.Scheme != "https") ||
+		strings.Trim(endpoint.Path, "/") != "" {
+		return nil, fmt.Errorf("endpoint %q is not an http or https URL without a path", config.Endpoint)
+	}
+
+	creds, err := r.s3Credentials(ctx, listSource.Namespace, config.Auth)
+	if err != nil {
+		return nil, err
+	}
+
+	var transport http.RoundTripper
+	key := types.NamespacedName{Name: listSource.Name, Namespace: listSource.Namespace}
+	if config.TLS != nil {
+		tlsConfig, fingerprint, err := r.apiTLSConfig(ctx, listSource.Namespace, config.TLS)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure TLS: %w", err)
+		}
+		transport = r.transports.get(key, fingerprint, tlsConfig)
+		if config.TLS.InsecureSkipVerify {
+			r.Recorder.Event(listSource, corev1.EventTypeWarning, "InsecureSkipVerify",
+				fmt.Sprintf("TLS certificate verification is disabled, the identity of %s is not checked", endpoint.Host))
+		}
+	} else {
+		r.transports.forget(key)
+	}
+
+	client, err := minio.New(endpoint.Host, &minio.Options{
+		Creds:      creds,
+		Secure:     endpoint.Scheme == "https",
+		Region:     config.Region,
+		Transport:  transport,
+		MaxRetries: 1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 client: %w", err)
+	}
+	return client, nil
+}
+
+// s3Credentials reads the access keys of an S3 source. The secret access key
+// and session token are never logged.
+func (r *ListSourceReconciler) s3Credentials(ctx context.Context, namespace string, auth *batchopsv1alpha1.S3Auth) (*credentials.Credentials, error) {
+	if auth == nil {
+		return credentials.NewStatic("", "", "", credentials.SignatureAnonymous), nil
+	}
+
+	log := log.FromContext(ctx)
+	log.V(1).Info("Retrieving S3 credentials")
+	secretData, err := r.getSecret(ctx, namespace, auth.SecretRef)
+	if err != nil {
+		log.Error(err, "Failed to retrieve S3 credentials")
+		return nil, fmt.Errorf("failed to get secret: %w", err)
+	}
+	accessKeyID, ok := secretData[auth.AccessKeyIDKey]
+	if !ok {
+		return nil, fmt.Errorf("S3 credentials secret %s has no key %s", auth.SecretRef.Name, auth.AccessKeyIDKey)
+	}
+	secretAccessKey, ok := secretData[auth.SecretRef.Key]
+	if !ok {
+		return nil, fmt.Errorf("S3 credentials secret %s has no key %s", auth.SecretRef.Name, auth.SecretRef.Key)
+	}
+	var sessionToken string
+	if auth.SessionTokenKey != "" {
+		if sessionToken, ok = secretData[auth.SessionTokenKey]; !ok {
+			return nil, fmt.Errorf("S3 credentials secret %s has no key %s", auth.SecretRef.Name, auth.SessionTokenKey)
+		}
+	}
+	return credentials.NewStaticV4(accessKeyID, secretAccessKey, sessionToken), nil
+}
+
+// retryableS3Error reports whether a failed listing is worth repeating:
+// denied access, bad credentials and missing buckets are not.
+func retryableS3Error(err error) bool {
+	var s3Err minio.ErrorResponse
+	if !errors.As(err, &s3Err) {
+		return true
+	}
+	switch s3Err.StatusCode {
+	case http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,228 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	batchopsv1alpha1 "github.com/matanryngler/parallax/api/v1alpha1"
+)
+
+// s3Listing is an object of the fake bucket.
+type s3Listing struct {
+	key      string
+	size     int
+	modified time.Time
+}
+
+// newS3Server serves the objects of the bucket "data" through ListObjectsV2,
+// two per page, and records the Authorization headers it receives.
+func newS3Server(t *testing.T, objects []s3Listing, authorizations *[]string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*authorizations = append(*authorizations, r.Header.Get("Authorization"))
+		if r.URL.Path != "/data/" && r.URL.Path != "/data" {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `<Error><Code>NoSuchBucket</Code><Message>The specified bucket does not exist</Message></Error>`)
+			return
+		}
+
+		query := r.URL.Query()
+		var page []s3Listing
+		for _, object := range objects {
+			if strings.HasPrefix(object.key, query.Get("prefix")) {
+				page = append(page, object)
+			}
+		}
+		start := 0
+		if token := query.Get("continuation-token"); token != "" {
+			_, err := fmt.Sscanf(token, "page-%d", &start)
+			require.NoError(t, err)
+		}
+		page = page[start:]
+		next := ""
+		if len(page) > 2 {
+			page = page[:2]
+			next = fmt.Sprintf("page-%d", start+2)
+		}
+
+		var contents strings.Builder
+		for _, object := range page {
+			fmt.Fprintf(&contents, `<Contents><Key>%s</Key><LastModified>%s</LastModified><ETag>"etag-%d"</ETag><Size>%d</Size><StorageClass>STANDARD</StorageClass></Contents>`,
+				object.key, object.modified.Format(time.RFC3339), object.size, object.size)
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<ListBucketResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/"><Name>data</Name><Prefix>%s</Prefix><KeyCount>%d</KeyCount><MaxKeys>1000</MaxKeys><IsTruncated>%t</IsTruncated><NextContinuationToken>%s</NextContinuationToken>%s</ListBucketResult>`,
+			query.Get("prefix"), len(page), next != "", next, contents.String())
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGetItemsFromS3(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	objects := []s3Listing{
+		{key: "incoming/", size: 0, modified: now},
+		{key: "incoming/2025/a.csv", size: 10, modified: now.Add(-48 * time.Hour)},
+		{key: "incoming/2025/b.csv", size: 20, modified: now.Add(-time.Hour)},
+		{key: "incoming/2025/b.json", size: 30, modified: now.Add(-time.Hour)},
+		{key: "incoming/2025/tmp/c.csv", size: 40, modified: now},
+		{key: "incoming/d.csv", size: 50, modified: now},
+		{key: "outgoing/e.csv", size: 60, modified: now},
+	}
+	var authorizations []string
+	server := newS3Server(t, objects, &authorizations)
+
+	r := newAPIReconciler(t)
+	require.NoError(t, r.Create(context.Background(), &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "s3-credentials", Namespace: "default"},
+		Data: map[string][]byte{
+			"access_key_id":     []byte("AKIAEXAMPLE"),
+			"secret_access_key": []byte("s3cret"),
+		},
+	}))
+
+	tests := []struct {
+		name   string
+		config batchopsv1alpha1.S3Config
+		format batchopsv1alpha1.ItemFormat
+		want   []string
+	}{
+		{
+			name:   "every key under the prefix",
+			config: batchopsv1alpha1.S3Config{Prefix: "incoming/"},
+			want:   []string{"incoming/2025/a.csv", "incoming/2025/b.csv", "incoming/2025/b.json", "incoming/2025/tmp/c.csv", "incoming/d.csv"},
+		},
+		{
+			name:   "include and exclude globs",
+			config: batchopsv1alpha1.S3Config{Include: []string{"incoming/**/*.csv"}, Exclude: []string{"**/tmp/**"}},
+			want:   []string{"incoming/2025/a.csv", "incoming/2025/b.csv", "incoming/d.csv"},
+		},
+		{
+			name:   "key regex",
+			config: batchopsv1alpha1.S3Config{KeyRegex: `/b\.(csv|json)$`},
+			want:   []string{"incoming/2025/b.csv", "incoming/2025/b.json"},
+		},
+		{
+			name: "modified within",
+			config: batchopsv1alpha1.S3Config{
+				Prefix:         "incoming/2025/",
+				ModifiedWithin: &metav1.Duration{Duration: 24 * time.Hour},
+				ModifiedAfter:  &metav1.Time{Time: now.Add(-30 * time.Minute)},
+			},
+			want: []string{"incoming/2025/tmp/c.csv"},
+		},
+		{
+			name:   "structured items",
+			config: batchopsv1alpha1.S3Config{Prefix: "outgoing/"},
+			format: batchopsv1alpha1.JSONItemFormat,
+			want: []string{fmt.Sprintf(`{"key":"outgoing/e.csv","size":60,"etag":"etag-60","lastModified":%q}`,
+				now.Format(time.RFC3339))},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := tt.config
+			config.Endpoint = server.URL
+			config.Region = "us-east-1"
+			config.Bucket = "data"
+			config.Auth = &batchopsv1alpha1.S3Auth{
+				SecretRef:      batchopsv1alpha1.SecretRef{Name: "s3-credentials", Key: "secret_access_key"},
+				AccessKeyIDKey: "access_key_id",
+			}
+			listSource := &batchopsv1alpha1.ListSource{
+				ObjectMeta: metav1.ObjectMeta{Name: "files", Namespace: "default"},
+				Spec: batchopsv1alpha1.ListSourceSpec{
+					Type:       batchopsv1alpha1.S3List,
+					ItemFormat: tt.format,
+					S3:         &config,
+				},
+			}
+
+			authorizations = nil
+			items, err := r.getItems(context.Background(), listSource)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, items)
+			require.NotEmpty(t, authorizations)
+			assert.Contains(t, authorizations[0], "Credential=AKIAEXAMPLE/")
+		})
+	}
+}
+
+func TestGetItemsFromS3_MissingBucket(t *testing.T) {
+	var authorizations []string
+	server := newS3Server(t, nil, &authorizations)
+	r := newAPIReconciler(t)
+	listSource := &batchopsv1alpha1.ListSource{
+		ObjectMeta: metav1.ObjectMeta{Name: "files", Namespace: "default"},
+		Spec: batchopsv1alpha1.ListSourceSpec{
+			Type:        batchopsv1alpha1.S3List,
+			S3:          &batchopsv1alpha1.S3Config{Endpoint: server.URL, Region: "us-east-1", Bucket: "missing"},
+			FetchPolicy: &batchopsv1alpha1.FetchPolicy{Retries: 3},
+		},
+	}
+
+	ctx, stats := withFetchStats(context.Background())
+	_, err := r.getItems(ctx, listSource)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "bucket does not exist")
+	assert.Equal(t, int32(1), stats.attempts, "missing buckets are not retried")
+	assert.Equal(t, []string{""}, authorizations, "requests without auth are anonymous")
+}
+
+func TestCompileGlob(t *testing.T) {
+	tests := []struct {
+		glob    string
+		matches []string
+		misses  []string
+	}{
+		{glob: "*.csv", matches: []string{"a.csv"}, misses: []string{"dir/a.csv", "a.csvx"}},
+		{glob: "data/?.csv", matches: []string{"data/a.csv"}, misses: []string{"data/ab.csv", "data//.csv"}},
+		{glob: "**/*.csv", matches: []string{"a.csv", "x/y/a.csv"}, misses: []string{"a.json"}},
+		{glob: "logs/**", matches: []string{"logs/a", "logs/x/y"}, misses: []string{"logsx/a"}},
+		{glob: "data/[a-c]*.txt", matches: []string{"data/b1.txt"}, misses: []string{"data/d1.txt"}},
+		{glob: "data/[^a-c]*.txt", matches: []string{"data/d1.txt"}, misses: []string{"data/b1.txt"}},
+		{glob: `f\*.txt`, matches: []string{"f*.txt"}, misses: []string{"fx.txt"}},
+		{glob: "a+b(1).txt", matches: []string{"a+b(1).txt"}, misses: []string{"aab1.txt"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.glob, func(t *testing.T) {
+			re, err := compileGlob(tt.glob)
+			require.NoError(t, err)
+			for _, path := range tt.matches {
+				assert.True(t, re.MatchString(path), "expected %q to match", path)
+			}
+			for _, path := range tt.misses {
+				assert.False(t, re.MatchString(path), "expected %q not to match", path)
+			}
+		})
+	}
+
+	_, err := compileGlob("data/[abc")
+	assert.Error(t, err)
+}
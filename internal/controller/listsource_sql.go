@@ -0,0 +1,171 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	mssql "github.com/microsoft/go-mssqldb"
+	"github.com/microsoft/go-mssqldb/msdsn"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	batchopsv1alpha1 "github.com/matanryngler/parallax/api/v1alpha1"
+)
+
+// sqlConnectorFunc returns a connector for a DSN, with the user and password
+// added when they are set. Errors must not quote the DSN, which may hold a
+// password.
+type sqlConnectorFunc func(dsn, user, password string) (driver.Connector, error)
+
+// sqlDrivers is the registry of the drivers of sql sources. Drivers that
+// depend on build options register themselves from their own files.
+var sqlDrivers = map[batchopsv1alpha1.SQLDriver]sqlConnectorFunc{
+	batchopsv1alpha1.PostgresDriver:  postgresConnector,
+	batchopsv1alpha1.MySQLDriver:     mysqlConnector,
+	batchopsv1alpha1.SQLServerDriver: sqlServerConnector,
+}
+
+func registerSQLDriver(name batchopsv1alpha1.SQLDriver, connector sqlConnectorFunc) {
+	sqlDrivers[name] = connector
+}
+
+// registeredSQLDrivers returns the names of the drivers compiled into the
+// operator, sorted.
+func registeredSQLDrivers() []string {
+	names := make([]string, 0, len(sqlDrivers))
+	for name := range sqlDrivers {
+		names = append(names, string(name))
+	}
+	slices.Sort(names)
+	return names
+}
+
+func (r *ListSourceReconciler) getItemsFromSQL(ctx context.Context, listSource *batchopsv1alpha1.ListSource) ([]string, error) {
+	config := listSource.Spec.SQL
+	log := log.FromContext(ctx).WithValues(
+		"type", "sql",
+		"driver", config.Driver,
+		"namespace", listSource.Namespace,
+		"query", config.Query,
+	)
+	log.Info("Starting SQL query to fetch items")
+
+	open := func(ctx context.Context) (*sql.DB, error) {
+		return r.openSQL(ctx, config, listSource.Namespace)
+	}
+	return r.queryDatabase(logr.NewContext(ctx, log), listSource, open, config.Query, config.Parameters)
+}
+
+// openSQL opens the database of a sql source with the driver it names. The
+// DSN and password are never logged.
+func (r *ListSourceReconciler) openSQL(ctx context.Context, config *batchopsv1alpha1.SQLConfig, namespace string) (*sql.DB, error) {
+	log := log.FromContext(ctx)
+
+	connectorFor, ok := sqlDrivers[config.Driver]
+	if !ok {
+		return nil, fmt.Errorf("driver %q is not compiled into this operator, available drivers: %s",
+			config.Driver, strings.Join(registeredSQLDrivers(), ", "))
+	}
+
+	dsn := config.DSN
+	if config.DSNFrom != nil {
+		secret, err := r.getSecret(ctx, namespace, *config.DSNFrom)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get DSN secret: %w", err)
+		}
+		if dsn, ok = secret[config.DSNFrom.Key]; !ok {
+			return nil, fmt.Errorf("DSN secret %s has no key %s", config.DSNFrom.Name, config.DSNFrom.Key)
+		}
+	}
+	user, password, err := r.databaseCredentials(ctx, namespace, "", config.Auth)
+	if err != nil {
+		return nil, err
+	}
+
+	connector, err := connectorFor(dsn, user, password)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s connection settings: %w", config.Driver, err)
+	}
+	log.V(1).Info("Establishing database connection",
+		"user", user,
+		"password_set", password != "",
+	)
+	return sql.OpenDB(connector), nil
+}
+
+func postgresConnector(dsn, user, password string) (driver.Connector, error) {
+	dsn, err := postgresDSN(&batchopsv1alpha1.PostgresConfig{ConnectionString: dsn}, user, password, false)
+	if err != nil {
+		return nil, err
+	}
+	connector, err := pq.NewConnector(dsn)
+	if err != nil {
+		return nil, errors.New("DSN is not a valid PostgreSQL connection string")
+	}
+	return connector, nil
+}
+
+func mysqlConnector(dsn, user, password string) (driver.Connector, error) {
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return nil, errors.New("DSN is not a valid MySQL data source name")
+	}
+	if user != "" {
+		cfg.User = user
+	}
+	if password != "" {
+		cfg.Passwd = password
+	}
+	return mysql.NewConnector(cfg)
+}
+
+func sqlServerConnector(dsn, user, password string) (driver.Connector, error) {
+	cfg, err := msdsn.Parse(dsn)
+	if err != nil {
+		return nil, errors.New("DSN is not a valid SQL Server connection string")
+	}
+	if user != "" {
+		cfg.User = user
+	}
+	if password != "" {
+		cfg.Password = password
+	}
+	return mssql.NewConnectorConfig(cfg), nil
+}
+
+// dsnConnector is the connector of drivers that only open connections by DSN.
+type dsnConnector struct {
+	driver driver.Driver
+	dsn    string
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
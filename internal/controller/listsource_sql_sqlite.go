@@ -0,0 +1,71 @@
+//go:build cgo
+
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/mattn/go-sqlite3"
+
+	batchopsv1alpha1 "github.com/matanryngler/parallax/api/v1alpha1"
+)
+
+// The SQLite driver is a cgo binding, so it is only registered in builds with
+// cgo. The operator image is built with cgo and linked statically.
+func init() {
+	registerSQLDriver(batchopsv1alpha1.SQLiteDriver, sqliteConnector)
+}
+
+func sqliteConnector(dsn, user, password string) (driver.Connector, error) {
+	if user != "" || password != "" {
+		return nil, errors.New("sqlite does not take credentials")
+	}
+	dsn, err := readOnlySQLiteDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return dsnConnector{driver: &sqlite3.SQLiteDriver{ConnectHook: noAttach}, dsn: dsn}, nil
+}
+
+// noAttach refuses ATTACH on a connection, which would otherwise open or
+// create other database files with the access of the operator.
+func noAttach(conn *sqlite3.SQLiteConn) error {
+	conn.SetLimit(sqlite3.SQLITE_LIMIT_ATTACHED, 0)
+	return nil
+}
+
+// readOnlySQLiteDSN turns a database path or file: URI into a file: URI with
+// mode=ro, replacing any other mode, so that the database has to exist and a
+// query cannot write to it.
+func readOnlySQLiteDSN(dsn string) (string, error) {
+	name, query, _ := strings.Cut(dsn, "?")
+	if !strings.HasPrefix(name, "file:") {
+		name = "file:" + strings.NewReplacer("%", "%25", "#", "%23").Replace(name)
+	}
+	params, err := url.ParseQuery(query)
+	if err != nil {
+		return "", fmt.Errorf("invalid sqlite DSN parameters: %w", err)
+	}
+	params.Set("mode", "ro")
+	return name + "?" + params.Encode(), nil
+}
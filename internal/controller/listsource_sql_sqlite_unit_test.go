@@ -0,0 +1,115 @@
+//go:build cgo
+
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	batchopsv1alpha1 "github.com/matanryngler/parallax/api/v1alpha1"
+)
+
+func TestListSourceController_SQLite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "items.db")
+	db, err := sql.Open("sqlite3", path)
+	require.NoError(t, err)
+	_, err = db.Exec(`CREATE TABLE items (id INTEGER, name TEXT, price REAL, status TEXT);
+		INSERT INTO items VALUES (1, 'alpha', 1.5, 'pending'), (2, 'beta', NULL, 'pending'), (3, 'gamma', 2, 'done');`)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	listSource := &batchopsv1alpha1.ListSource{
+		ObjectMeta: metav1.ObjectMeta{Name: "items", Namespace: "default", Finalizers: []string{listSourceFinalizer}},
+		Spec: batchopsv1alpha1.ListSourceSpec{
+			Type: batchopsv1alpha1.SQLList,
+			SQL: &batchopsv1alpha1.SQLConfig{
+				Driver:     batchopsv1alpha1.SQLiteDriver,
+				DSN:        path,
+				Query:      "SELECT id, name, price FROM items WHERE status = ? ORDER BY id",
+				Parameters: []batchopsv1alpha1.QueryParameter{{Value: ptr.To("pending")}},
+			},
+		},
+	}
+	r := newStaticListReconciler(t, listSource)
+	ctx := context.Background()
+
+	_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(listSource)})
+	require.NoError(t, err)
+	require.NoError(t, r.Get(ctx, client.ObjectKeyFromObject(listSource), listSource))
+	assert.Equal(t, "Ready", listSource.Status.State, listSource.Status.Error)
+
+	items, err := readItems(ctx, r.Client, "default", "items")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		`{"id":1,"name":"alpha","price":1.5}`,
+		`{"id":2,"name":"beta","price":null}`,
+	}, items)
+}
+
+func TestReadOnlySQLiteDSN(t *testing.T) {
+	for dsn, expected := range map[string]string{
+		"/data/items.db":      "file:/data/items.db?mode=ro",
+		"/data/50%.db":        "file:/data/50%25.db?mode=ro",
+		"file:/data/items.db": "file:/data/items.db?mode=ro",
+		"file:/data/items.db?mode=rwc&cache=shared": "file:/data/items.db?cache=shared&mode=ro",
+		"items.db?_busy_timeout=100":                "file:items.db?_busy_timeout=100&mode=ro",
+	} {
+		actual, err := readOnlySQLiteDSN(dsn)
+		require.NoError(t, err, dsn)
+		assert.Equal(t, expected, actual, dsn)
+	}
+}
+
+func TestSQLiteConnector_ReadOnly(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "items.db")
+	db, err := sql.Open("sqlite3", path)
+	require.NoError(t, err)
+	_, err = db.Exec(`CREATE TABLE items (id INTEGER)`)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	open := func(dsn string) *sql.DB {
+		connector, err := sqliteConnector(dsn, "", "")
+		require.NoError(t, err)
+		db := sql.OpenDB(connector)
+		t.Cleanup(func() { _ = db.Close() })
+		return db
+	}
+
+	db = open("file:" + path + "?mode=rwc")
+	_, err = db.Exec(`INSERT INTO items VALUES (1)`)
+	assert.Error(t, err, "writes are refused")
+	_, err = db.Exec(`ATTACH DATABASE ? AS other`, filepath.Join(dir, "other.db"))
+	assert.Error(t, err, "attached databases are not created")
+	assert.NoFileExists(t, filepath.Join(dir, "other.db"))
+
+	missing := filepath.Join(dir, "missing.db")
+	assert.Error(t, open(missing).Ping(), "missing databases are not created")
+	assert.NoFileExists(t, missing)
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	mssql "github.com/microsoft/go-mssqldb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	batchopsv1alpha1 "github.com/matanryngler/parallax/api/v1alpha1"
+)
+
+func TestSQLDriverConnectors(t *testing.T) {
+	t.Run("postgres", func(t *testing.T) {
+		connector, err := postgresConnector("postgres://db/app?sslmode=disable", "svc", "s3cret")
+		require.NoError(t, err)
+		assert.IsType(t, &pq.Connector{}, connector)
+	})
+
+	t.Run("mysql", func(t *testing.T) {
+		connector, err := mysqlConnector("reader:old@tcp(db:3306)/app", "svc", "s3cret")
+		require.NoError(t, err)
+		assert.IsType(t, (*mysql.MySQLDriver)(nil), connector.Driver())
+	})
+
+	t.Run("sqlserver", func(t *testing.T) {
+		connector, err := sqlServerConnector("sqlserver://db:1433?database=app", "svc", "s3cret;with=separators")
+		require.NoError(t, err)
+		assert.IsType(t, &mssql.Connector{}, connector)
+	})
+
+	t.Run("invalid DSNs are not quoted", func(t *testing.T) {
+		for _, tt := range []struct {
+			connectorFor sqlConnectorFunc
+			dsn          string
+		}{
+			{postgresConnector, "postgres://svc:hunter2@db:port/app"},
+			{mysqlConnector, "svc:hunter2@tcp(db/app"},
+			{sqlServerConnector, "sqlserver://svc:hunter2@db/%zz"},
+		} {
+			_, err := tt.connectorFor(tt.dsn, "", "")
+			require.Error(t, err, tt.dsn)
+			assert.NotContains(t, err.Error(), "hunter2", tt.dsn)
+		}
+	})
+}
+
+func TestOpenSQL(t *testing.T) {
+	r := newAPIReconciler(t)
+	ctx := context.Background()
+	require.NoError(t, r.Create(ctx, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+		Data:       map[string][]byte{"dsn": []byte("sqlserver://svc:s3cret@db?database=app")},
+	}))
+
+	db, err := r.openSQL(ctx, &batchopsv1alpha1.SQLConfig{
+		Driver:  batchopsv1alpha1.SQLServerDriver,
+		DSNFrom: &batchopsv1alpha1.SecretRef{Name: "db", Key: "dsn"},
+	}, "default")
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	_, err = r.openSQL(ctx, &batchopsv1alpha1.SQLConfig{
+		Driver:  batchopsv1alpha1.SQLServerDriver,
+		DSNFrom: &batchopsv1alpha1.SecretRef{Name: "db", Key: "url"},
+	}, "default")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "DSN secret db has no key url")
+
+	_, err = r.openSQL(ctx, &batchopsv1alpha1.SQLConfig{Driver: "oracle", DSN: "oracle://db"}, "default")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `driver "oracle" is not compiled into this operator`)
+	assert.Contains(t, err.Error(), "mysql, postgres")
+}
+
+func TestConvertColumn_UniqueIdentifier(t *testing.T) {
+	// 6F9619FF-8B86-D011-B42D-00C04FC964FF as sent by SQL Server
+	guid := []byte{0xFF, 0x19, 0x96, 0x6F, 0x86, 0x8B, 0x11, 0xD0, 0xB4, 0x2D, 0x00, 0xC0, 0x4F, 0xC9, 0x64, 0xFF}
+	assert.Equal(t, "6F9619FF-8B86-D011-B42D-00C04FC964FF", convertColumn(guid, "UNIQUEIDENTIFIER"))
+}
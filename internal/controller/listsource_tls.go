@@ -0,0 +1,196 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"hash"
+	"net/http"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	batchopsv1alpha1 "github.com/matanryngler/parallax/api/v1alpha1"
+)
+
+const (
+	defaultClientCertificateKey = corev1.TLSCertKey
+	defaultClientPrivateKeyKey  = corev1.TLSPrivateKeyKey
+)
+
+// apiTransportCache keeps the HTTP transports of API sources with TLS
+// settings, so that connections are reused between fetches. A transport is
+// replaced, and its connections closed, once the certificate material it was
+// built from changes, e.g. after a Secret rotation.
+type apiTransportCache struct {
+	mu         sync.Mutex
+	transports map[types.NamespacedName]cachedTransport
+}
+
+type cachedTransport struct {
+	fingerprint [sha256.Size]byte
+	transport   *http.Transport
+}
+
+// get returns the transport of a ListSource, building it when there is none
+// or the material changed since it was built.
+func (c *apiTransportCache) get(key types.NamespacedName, fingerprint [sha256.Size]byte, tlsConfig *tls.Config) *http.Transport {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if cached, ok := c.transports[key]; ok {
+		if cached.fingerprint == fingerprint {
+			return cached.transport
+		}
+		cached.transport.CloseIdleConnections()
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	if c.transports == nil {
+		c.transports = map[types.NamespacedName]cachedTransport{}
+	}
+	c.transports[key] = cachedTransport{fingerprint: fingerprint, transport: transport}
+	return transport
+}
+
+// forget drops the transport of a ListSource.
+func (c *apiTransportCache) forget(key types.NamespacedName) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if cached, ok := c.transports[key]; ok {
+		cached.transport.CloseIdleConnections()
+		delete(c.transports, key)
+	}
+}
+
+// apiHTTPClient returns the HTTP client of an API source. The TLS material is
+// read on every fetch, so rotated Secrets are picked up by the next one.
+func (r *ListSourceReconciler) apiHTTPClient(ctx context.Context, listSource *batchopsv1alpha1.ListSource) (*http.Client, error) {
+	key := types.NamespacedName{Name: listSource.Name, Namespace: listSource.Namespace}
+	config := listSource.Spec.API.TLS
+	if config == nil {
+		r.transports.forget(key)
+		return &http.Client{}, nil
+	}
+
+	tlsConfig, fingerprint, err := r.apiTLSConfig(ctx, listSource.Namespace, config)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{Transport: r.transports.get(key, fingerprint, tlsConfig)}, nil
+}
+
+// apiTLSConfig builds the TLS configuration of an API source, along with a
+// fingerprint of everything it was built from.
+func (r *ListSourceReconciler) apiTLSConfig(ctx context.Context, namespace string, config *batchopsv1alpha1.APITLSConfig) (*tls.Config, [sha256.Size]byte, error) {
+	tlsConfig, digest, err := r.tlsConfig(ctx, namespace, config.CA, config.ClientCertificate)
+	if err != nil {
+		return nil, [sha256.Size]byte{}, err
+	}
+	fmt.Fprintf(digest, "%s\x00%t\x00", config.ServerName, config.InsecureSkipVerify)
+	tlsConfig.ServerName = config.ServerName
+	// Opt-in, every fetch records a Warning event while it is set
+	tlsConfig.InsecureSkipVerify = config.InsecureSkipVerify // #nosec G402
+
+	var fingerprint [sha256.Size]byte
+	copy(fingerprint[:], digest.Sum(nil))
+	return tlsConfig, fingerprint, nil
+}
+
+// tlsConfig builds a TLS configuration trusting the CA bundle and presenting
+// the client certificate, both optional. The returned digest has been fed
+// everything the configuration was built from.
+func (r *ListSourceReconciler) tlsConfig(ctx context.Context, namespace string, ca *batchopsv1alpha1.CABundleRef, clientCert *batchopsv1alpha1.ClientCertificateRef) (*tls.Config, hash.Hash, error) {
+	log := log.FromContext(ctx)
+	digest := sha256.New()
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if ca != nil {
+		bundle, err := r.caBundle(ctx, namespace, ca)
+		if err != nil {
+			return nil, nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(bundle) {
+			return nil, nil, fmt.Errorf("CA bundle contains no PEM encoded certificates")
+		}
+		tlsConfig.RootCAs = pool
+		digest.Write(bundle)
+		log.V(1).Info("Configured CA bundle")
+	}
+
+	if ref := clientCert; ref != nil {
+		secret, err := r.getSecret(ctx, namespace, batchopsv1alpha1.SecretRef{Name: ref.SecretName, Namespace: ref.Namespace})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get client certificate secret: %w", err)
+		}
+		certKey := ref.CertificateKey
+		if certKey == "" {
+			certKey = defaultClientCertificateKey
+		}
+		privateKeyKey := ref.PrivateKeyKey
+		if privateKeyKey == "" {
+			privateKeyKey = defaultClientPrivateKeyKey
+		}
+		certPEM, keyPEM := []byte(secret[certKey]), []byte(secret[privateKeyKey])
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load client certificate from secret %s: %w", ref.SecretName, err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+		digest.Write(certPEM)
+		digest.Write(keyPEM)
+		log.V(1).Info("Configured client certificate", "secret", ref.SecretName)
+	}
+	return tlsConfig, digest, nil
+}
+
+// caBundle reads a CA bundle from the Secret or ConfigMap it references.
+func (r *ListSourceReconciler) caBundle(ctx context.Context, namespace string, ref *batchopsv1alpha1.CABundleRef) ([]byte, error) {
+	switch {
+	case ref.SecretRef != nil:
+		secret, err := r.getSecret(ctx, namespace, *ref.SecretRef)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get CA bundle secret: %w", err)
+		}
+		bundle, ok := secret[ref.SecretRef.Key]
+		if !ok {
+			return nil, fmt.Errorf("CA bundle secret %s has no key %s", ref.SecretRef.Name, ref.SecretRef.Key)
+		}
+		return []byte(bundle), nil
+
+	case ref.ConfigMapRef != nil:
+		data, err := r.getConfigMap(ctx, namespace, *ref.ConfigMapRef)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get CA bundle ConfigMap: %w", err)
+		}
+		bundle, ok := data[ref.ConfigMapRef.Key]
+		if !ok {
+			return nil, fmt.Errorf("CA bundle ConfigMap %s has no key %s", ref.ConfigMapRef.Name, ref.ConfigMapRef.Key)
+		}
+		return []byte(bundle), nil
+	}
+	return nil, fmt.Errorf("CA bundle must reference a Secret or a ConfigMap")
+}
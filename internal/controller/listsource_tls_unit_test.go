@@ -0,0 +1,223 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+
+	batchopsv1alpha1 "github.com/matanryngler/parallax/api/v1alpha1"
+)
+
+// testCA issues certificates for the TLS tests.
+type testCA struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCA{cert: cert, key: key, certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue returns the PEM encoded certificate and key of a server or client.
+func (ca *testCA) issue(t *testing.T, dnsNames []string, ips []net.IP, usage x509.ExtKeyUsage) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		DNSNames:     dnsNames,
+		IPAddresses:  ips,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// newTLSServer serves two items over TLS with a certificate for the given
+// names, requiring a client certificate signed by ca when mutual is set.
+func newTLSServer(t *testing.T, ca *testCA, dnsNames []string, ips []net.IP, mutual bool) *httptest.Server {
+	certPEM, keyPEM := ca.issue(t, dnsNames, ips, x509.ExtKeyUsageServerAuth)
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(t, err)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writePage(w, 0, 2, "")
+	}))
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{cert}}
+	if mutual {
+		pool := x509.NewCertPool()
+		pool.AddCert(ca.cert)
+		server.TLS.ClientCAs = pool
+		server.TLS.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGetItemsFromAPI_TLS(t *testing.T) {
+	ca := newTestCA(t)
+	clientCertPEM, clientKeyPEM := ca.issue(t, nil, nil, x509.ExtKeyUsageClientAuth)
+	localhost := []net.IP{net.ParseIP("127.0.0.1")}
+
+	setup := func(t *testing.T) *ListSourceReconciler {
+		r := newAPIReconciler(t)
+		ctx := context.Background()
+		require.NoError(t, r.Create(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "internal-ca", Namespace: "default"},
+			Data:       map[string]string{"ca.crt": string(ca.certPEM)},
+		}))
+		require.NoError(t, r.Create(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "internal-ca", Namespace: "default"},
+			Data:       map[string][]byte{"ca.crt": ca.certPEM},
+		}))
+		require.NoError(t, r.Create(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "client-cert", Namespace: "default"},
+			Type:       corev1.SecretTypeTLS,
+			Data:       map[string][]byte{"tls.crt": clientCertPEM, "tls.key": clientKeyPEM},
+		}))
+		return r
+	}
+
+	t.Run("private CA is not trusted by default", func(t *testing.T) {
+		server := newTLSServer(t, ca, nil, localhost, false)
+		_, err := setup(t).getItemsFromAPI(context.Background(), newAPIListSource(server.URL, nil))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "certificate")
+	})
+
+	t.Run("CA from ConfigMap with client certificate", func(t *testing.T) {
+		server := newTLSServer(t, ca, nil, localhost, true)
+		listSource := newAPIListSource(server.URL, nil)
+		listSource.Spec.API.TLS = &batchopsv1alpha1.APITLSConfig{
+			CA:                &batchopsv1alpha1.CABundleRef{ConfigMapRef: &batchopsv1alpha1.ConfigMapRef{Name: "internal-ca", Key: "ca.crt"}},
+			ClientCertificate: &batchopsv1alpha1.ClientCertificateRef{SecretName: "client-cert"},
+		}
+		items, err := setup(t).getItemsFromAPI(context.Background(), listSource)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "b"}, items)
+	})
+
+	t.Run("mutual TLS without client certificate", func(t *testing.T) {
+		server := newTLSServer(t, ca, nil, localhost, true)
+		listSource := newAPIListSource(server.URL, nil)
+		listSource.Spec.API.TLS = &batchopsv1alpha1.APITLSConfig{
+			CA: &batchopsv1alpha1.CABundleRef{SecretRef: &batchopsv1alpha1.SecretRef{Name: "internal-ca", Key: "ca.crt"}},
+		}
+		_, err := setup(t).getItemsFromAPI(context.Background(), listSource)
+		require.Error(t, err)
+	})
+
+	t.Run("server name", func(t *testing.T) {
+		server := newTLSServer(t, ca, []string{"api.internal"}, nil, false)
+		listSource := newAPIListSource(server.URL, nil)
+		listSource.Spec.API.TLS = &batchopsv1alpha1.APITLSConfig{
+			CA:         &batchopsv1alpha1.CABundleRef{SecretRef: &batchopsv1alpha1.SecretRef{Name: "internal-ca", Key: "ca.crt"}},
+			ServerName: "api.internal",
+		}
+		items, err := setup(t).getItemsFromAPI(context.Background(), listSource)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "b"}, items)
+	})
+
+	t.Run("insecure skip verify is evented", func(t *testing.T) {
+		server := newTLSServer(t, ca, nil, localhost, false)
+		listSource := newAPIListSource(server.URL, nil)
+		listSource.Spec.API.TLS = &batchopsv1alpha1.APITLSConfig{InsecureSkipVerify: true}
+		r := setup(t)
+		items, err := r.getItemsFromAPI(context.Background(), listSource)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "b"}, items)
+		event := <-r.Recorder.(*record.FakeRecorder).Events
+		assert.Contains(t, event, "Warning InsecureSkipVerify")
+	})
+
+	t.Run("invalid CA bundle", func(t *testing.T) {
+		r := setup(t)
+		require.NoError(t, r.Create(context.Background(), &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "broken-ca", Namespace: "default"},
+			Data:       map[string]string{"ca.crt": "not a certificate"},
+		}))
+		listSource := newAPIListSource("https://127.0.0.1:1", nil)
+		listSource.Spec.API.TLS = &batchopsv1alpha1.APITLSConfig{
+			CA: &batchopsv1alpha1.CABundleRef{ConfigMapRef: &batchopsv1alpha1.ConfigMapRef{Name: "broken-ca", Key: "ca.crt"}},
+		}
+		_, err := r.getItemsFromAPI(context.Background(), listSource)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no PEM encoded certificates")
+	})
+}
+
+func TestAPITransportCache(t *testing.T) {
+	var cache apiTransportCache
+	key := types.NamespacedName{Name: "source", Namespace: "default"}
+	first := sha256.Sum256([]byte("ca-1"))
+
+	transport := cache.get(key, first, &tls.Config{})
+	assert.Same(t, transport, cache.get(key, first, &tls.Config{}), "unchanged material reuses the transport")
+
+	// A rotated Secret yields a new transport
+	rotated := cache.get(key, sha256.Sum256([]byte("ca-2")), &tls.Config{})
+	assert.NotSame(t, transport, rotated)
+
+	cache.forget(key)
+	assert.Empty(t, cache.transports)
+}
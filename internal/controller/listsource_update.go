@@ -0,0 +1,119 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	batchopsv1alpha1 "github.com/matanryngler/parallax/api/v1alpha1"
+)
+
+// rejectedUpdateError is returned for a fetched list that the UpdatePolicy
+// does not let replace the served one.
+type rejectedUpdateError struct {
+	reason string
+}
+
+func (e *rejectedUpdateError) Error() string {
+	return "update rejected: " + e.reason
+}
+
+// checkUpdate applies the guardrails of the UpdatePolicy to a fetched list.
+// The served list is only read when the change has to be measured against it.
+func (r *ListSourceReconciler) checkUpdate(ctx context.Context, listSource *batchopsv1alpha1.ListSource, items []string) error {
+	policy := listSource.Spec.UpdatePolicy
+	if policy == nil {
+		return nil
+	}
+
+	if policy.MinItems != nil && len(items) < int(*policy.MinItems) {
+		return &rejectedUpdateError{reason: fmt.Sprintf("fetched %d items, fewer than minItems %d", len(items), *policy.MinItems)}
+	}
+
+	if policy.MaxChangePercent == nil {
+		return nil
+	}
+	served, err := readItems(ctx, r.Client, listSource.Namespace, listSource.Name)
+	if apierrors.IsNotFound(err) || (err == nil && len(served) == 0) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read the served list: %w", err)
+	}
+	if change := listChangePercent(served, items); change > float64(*policy.MaxChangePercent) {
+		return &rejectedUpdateError{reason: fmt.Sprintf("fetched list changes %.1f%% of the %d served items, more than maxChangePercent %d",
+			change, len(served), *policy.MaxChangePercent)}
+	}
+	return nil
+}
+
+// listChangePercent returns the items added and removed between two lists,
+// as a percentage of the old list. Duplicate items are counted one by one.
+func listChangePercent(old, updated []string) float64 {
+	counts := make(map[string]int, len(old))
+	for _, item := range old {
+		counts[item]++
+	}
+	for _, item := range updated {
+		counts[item]--
+	}
+
+	changed := 0
+	for _, count := range counts {
+		changed += max(count, -count)
+	}
+	return float64(changed) * 100 / float64(len(old))
+}
+
+// staleAt returns when the served list of a ListSource outlives its
+// maxStaleness, the zero time when it may be served indefinitely.
+func staleAt(listSource *batchopsv1alpha1.ListSource) time.Time {
+	policy := listSource.Spec.UpdatePolicy
+	if policy == nil || policy.MaxStaleness == nil || listSource.Status.LastSuccessfulFetchTime == nil {
+		return time.Time{}
+	}
+	return listSource.Status.LastSuccessfulFetchTime.Add(policy.MaxStaleness.Duration)
+}
+
+// isListStale reports whether a ListSource whose fetches fail serves a list
+// older than its maxStaleness. A list is never stale while fetches succeed.
+func isListStale(listSource *batchopsv1alpha1.ListSource, now time.Time) bool {
+	at := staleAt(listSource)
+	return listSource.Status.ConsecutiveFailures > 0 && !at.IsZero() && now.After(at)
+}
+
+// checkListSourceFresh fails when the ListSource of the given name serves a
+// stale list. ConfigMaps without a ListSource are not checked.
+func checkListSourceFresh(ctx context.Context, c client.Reader, namespace, name string) error {
+	var listSource batchopsv1alpha1.ListSource
+	if err := c.Get(ctx, client.ObjectKey{Name: name, Namespace: namespace}, &listSource); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get ListSource %s: %w", name, err)
+	}
+	if isListStale(&listSource, time.Now()) {
+		return fmt.Errorf("ListSource %s serves a list fetched at %s, older than its maxStaleness of %s",
+			name, listSource.Status.LastSuccessfulFetchTime.UTC().Format(time.RFC3339), listSource.Spec.UpdatePolicy.MaxStaleness.Duration)
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	batchopsv1alpha1 "github.com/matanryngler/parallax/api/v1alpha1"
+)
+
+func newStaticListReconciler(t *testing.T, listSource *batchopsv1alpha1.ListSource) *ListSourceReconciler {
+	r := newAPIReconciler(t)
+	r.Client = fake.NewClientBuilder().
+		WithScheme(r.Scheme).
+		WithObjects(listSource).
+		WithStatusSubresource(&batchopsv1alpha1.ListSource{}).
+		Build()
+	return r
+}
+
+func TestListSourceController_UpdatePolicy(t *testing.T) {
+	listSource := &batchopsv1alpha1.ListSource{
+		ObjectMeta: metav1.ObjectMeta{Name: "source", Namespace: "default", Finalizers: []string{listSourceFinalizer}},
+		Spec: batchopsv1alpha1.ListSourceSpec{
+			Type:       batchopsv1alpha1.StaticList,
+			StaticList: []string{"a", "b", "c", "d", "e"},
+			UpdatePolicy: &batchopsv1alpha1.UpdatePolicy{
+				MinItems:         ptr.To[int32](3),
+				MaxChangePercent: ptr.To[int32](20),
+			},
+		},
+	}
+	r := newStaticListReconciler(t, listSource)
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(listSource)}
+
+	_, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	<-r.Recorder.(*record.FakeRecorder).Events
+
+	update := func(t *testing.T, items []string) {
+		require.NoError(t, r.Get(ctx, req.NamespacedName, listSource))
+		listSource.Spec.StaticList = items
+		require.NoError(t, r.Update(ctx, listSource))
+		result, err := r.Reconcile(ctx, req)
+		require.NoError(t, err)
+		require.NoError(t, r.Get(ctx, req.NamespacedName, listSource))
+		if listSource.Status.ConsecutiveFailures > 0 {
+			assert.Positive(t, result.RequeueAfter)
+		}
+	}
+	assertRejected := func(t *testing.T, message string) {
+		assert.Equal(t, "Error", listSource.Status.State)
+		assert.Contains(t, listSource.Status.Error, message)
+		assert.True(t, meta.IsStatusConditionTrue(listSource.Status.Conditions, batchopsv1alpha1.ConditionReady))
+		degraded := meta.FindStatusCondition(listSource.Status.Conditions, batchopsv1alpha1.ConditionDegraded)
+		require.NotNil(t, degraded)
+		assert.Equal(t, "UpdateRejected", degraded.Reason)
+		assert.Contains(t, <-r.Recorder.(*record.FakeRecorder).Events, "Warning UpdateRejected Kept the served list")
+
+		served, err := readItems(ctx, r.Client, "default", "source")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "b", "c", "d", "e"}, served)
+	}
+
+	t.Run("too few items", func(t *testing.T) {
+		update(t, []string{"a", "b"})
+		assertRejected(t, "fewer than minItems 3")
+	})
+
+	t.Run("too large a change", func(t *testing.T) {
+		update(t, []string{"a", "b", "c", "x", "y"})
+		assertRejected(t, "changes 80.0% of the 5 served items")
+	})
+
+	t.Run("change within limit", func(t *testing.T) {
+		update(t, []string{"a", "b", "c", "d", "e", "f"})
+		assert.Equal(t, "Ready", listSource.Status.State)
+		assert.Zero(t, listSource.Status.ConsecutiveFailures)
+		served, err := readItems(ctx, r.Client, "default", "source")
+		require.NoError(t, err)
+		assert.Len(t, served, 6)
+	})
+}
+
+func TestListSourceController_MaxStaleness(t *testing.T) {
+	fetched := metav1.NewTime(time.Now().Add(-2 * time.Hour))
+	listSource := &batchopsv1alpha1.ListSource{
+		ObjectMeta: metav1.ObjectMeta{Name: "source", Namespace: "default", Finalizers: []string{listSourceFinalizer}},
+		Spec: batchopsv1alpha1.ListSourceSpec{
+			Type: "unknown",
+			UpdatePolicy: &batchopsv1alpha1.UpdatePolicy{
+				MaxStaleness: &metav1.Duration{Duration: 3 * time.Hour},
+			},
+		},
+		Status: batchopsv1alpha1.ListSourceStatus{LastSuccessfulFetchTime: &fetched},
+	}
+	r := newStaticListReconciler(t, listSource)
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(listSource)}
+	require.NoError(t, r.Create(ctx, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "source", Namespace: "default"},
+		Data:       map[string]string{itemsKey: "a\nb"},
+	}))
+
+	// Within maxStaleness the previous list is served
+	result, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, result.RequeueAfter, time.Hour)
+	require.NoError(t, r.Get(ctx, req.NamespacedName, listSource))
+	assert.True(t, meta.IsStatusConditionTrue(listSource.Status.Conditions, batchopsv1alpha1.ConditionReady))
+	_, err = getListSourceItems(ctx, r.Client, "default", "source")
+	require.NoError(t, err)
+
+	// Beyond it the list is no longer served
+	listSource.Spec.UpdatePolicy.MaxStaleness.Duration = time.Hour
+	require.NoError(t, r.Update(ctx, listSource))
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	require.NoError(t, r.Get(ctx, req.NamespacedName, listSource))
+	ready := meta.FindStatusCondition(listSource.Status.Conditions, batchopsv1alpha1.ConditionReady)
+	require.NotNil(t, ready)
+	assert.Equal(t, metav1.ConditionFalse, ready.Status)
+	assert.Equal(t, "Stale", ready.Reason)
+	_, err = getListSourceItems(ctx, r.Client, "default", "source")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "older than its maxStaleness")
+}
+
+func TestListChangePercent(t *testing.T) {
+	assert.InDelta(t, 0, listChangePercent([]string{"a", "b"}, []string{"b", "a"}), 0.01)
+	assert.InDelta(t, 50, listChangePercent([]string{"a", "b"}, []string{"a"}), 0.01)
+	assert.InDelta(t, 100, listChangePercent([]string{"a", "b"}, []string{"a", "c"}), 0.01)
+	assert.InDelta(t, 50, listChangePercent([]string{"a", "a"}, []string{"a"}), 0.01)
+	assert.InDelta(t, 200, listChangePercent([]string{"a"}, []string{"a", "b", "c"}), 0.01)
+}
@@ -0,0 +1,395 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	batchopsv1alpha1 "github.com/matanryngler/parallax/api/v1alpha1"
+)
+
+const (
+	// itemsGzipKey is the binaryData key holding the gzip-compressed items.
+	itemsGzipKey = "items.gz"
+	// shardsAnnotation is set on the first ConfigMap of a list stored in more
+	// than one ConfigMap to the number of ConfigMaps.
+	shardsAnnotation = "batchops.io/shards"
+	// itemsHashAnnotation holds a hash over all items of a list, so readers can
+	// tell a consistent set of shards from one that is being rewritten.
+	itemsHashAnnotation = "batchops.io/items-hash"
+	// shardOfLabel is set on the additional ConfigMaps of a sharded list to the
+	// name of its first ConfigMap.
+	shardOfLabel = "batchops.io/shard-of"
+	// maxConfigMapItemsSize keeps a ConfigMap well below the 1 MiB object limit,
+	// leaving room for metadata.
+	maxConfigMapItemsSize = 900 * 1024
+	// maxGzipChunkSize bounds the raw size of the items compressed into one
+	// ConfigMap. Chunks that still compress above maxConfigMapItemsSize are split.
+	maxGzipChunkSize = 16 * maxConfigMapItemsSize
+)
+
+// shardName returns the name of the n-th shard of a list object. The first
+// shard keeps the plain name, so unsharded lists look exactly as before.
+func shardName(name string, shard int) string {
+	if shard == 0 {
+		return name
+	}
+	return fmt.Sprintf("%s-shard-%d", name, shard)
+}
+
+// maxJobNameLength is the longest Job name, as Kubernetes copies it into a
+// label of the Job's pods.
+const maxJobNameLength = 63
+
+// jobShardName returns the name of the Job of the n-th shard of a ListJob.
+// Names that would not fit maxJobNameLength are truncated and keep a hash of
+// the ListJob name, so they stay unique and are the same on every reconcile.
+func jobShardName(name string, shard int) string {
+	full := shardName(name, shard)
+	if len(full) <= maxJobNameLength {
+		return full
+	}
+	sum := sha256.Sum256([]byte(name))
+	suffix := fmt.Sprintf("-%s-shard-%d", hex.EncodeToString(sum[:4]), shard)
+	return strings.TrimRight(name[:maxJobNameLength-len(suffix)], "-.") + suffix
+}
+
+// splitItems splits items into contiguous chunks. Chunks hold whole units of
+// unit items, at most maxItems items when maxItems is positive, and the summed
+// cost of their items stays within limit unless a single unit exceeds it.
+// It always returns at least one, possibly empty, chunk.
+func splitItems(items []string, unit, maxItems int, cost func(string) int, limit int) [][]string {
+	if unit < 1 {
+		unit = 1
+	}
+	var chunks [][]string
+	start, size := 0, 0
+	for first := 0; first < len(items); first += unit {
+		last := min(first+unit, len(items))
+		unitCost := 0
+		for _, item := range items[first:last] {
+			unitCost += cost(item)
+		}
+		full := size+unitCost > limit || (maxItems > 0 && last-start > maxItems)
+		if full && first > start {
+			chunks = append(chunks, items[start:first])
+			start, size = first, 0
+		}
+		size += unitCost
+	}
+	return append(chunks, items[start:])
+}
+
+// itemCost is the number of bytes an item takes in the newline-separated list.
+func itemCost(item string) int {
+	return len(item) + 1
+}
+
+// itemsHash returns a short hash identifying the full list.
+func itemsHash(items []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(items, "\n")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// buildListConfigMaps renders items into one or more ConfigMaps named after
+// shardName, with the items either under the "items" key or gzip-compressed
+// under the "items.gz" binaryData key.
+func buildListConfigMaps(name, namespace string, items []string, compression batchopsv1alpha1.ListCompression) ([]*corev1.ConfigMap, error) {
+	var chunks [][]string
+	if compression == batchopsv1alpha1.GzipCompression {
+		for _, chunk := range splitItems(items, 1, 0, itemCost, maxGzipChunkSize) {
+			compressed, err := gzipChunks(chunk)
+			if err != nil {
+				return nil, err
+			}
+			chunks = append(chunks, compressed...)
+		}
+	} else {
+		chunks = splitItems(items, 1, 0, itemCost, maxConfigMapItemsSize)
+	}
+
+	configMaps := make([]*corev1.ConfigMap, 0, len(chunks))
+	for i, chunk := range chunks {
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      shardName(name, i),
+				Namespace: namespace,
+			},
+		}
+		if compression == batchopsv1alpha1.GzipCompression {
+			data, err := gzipItems(chunk)
+			if err != nil {
+				return nil, err
+			}
+			cm.BinaryData = map[string][]byte{itemsGzipKey: data}
+		} else {
+			cm.Data = map[string]string{itemsKey: strings.Join(chunk, "\n")}
+		}
+		if i > 0 {
+			cm.Labels = map[string]string{shardOfLabel: name}
+		}
+		configMaps = append(configMaps, cm)
+	}
+
+	configMaps[0].Annotations = map[string]string{itemsHashAnnotation: itemsHash(items)}
+	if len(configMaps) > 1 {
+		configMaps[0].Annotations[shardsAnnotation] = strconv.Itoa(len(configMaps))
+	}
+	return configMaps, nil
+}
+
+// gzipChunks halves a chunk until every part compresses to fit a ConfigMap.
+func gzipChunks(items []string) ([][]string, error) {
+	data, err := gzipItems(items)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) <= maxConfigMapItemsSize || len(items) < 2 {
+		return [][]string{items}, nil
+	}
+	first, err := gzipChunks(items[:len(items)/2])
+	if err != nil {
+		return nil, err
+	}
+	second, err := gzipChunks(items[len(items)/2:])
+	if err != nil {
+		return nil, err
+	}
+	return append(first, second...), nil
+}
+
+func gzipItems(items []string) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := io.WriteString(writer, strings.Join(items, "\n")); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// storeItems publishes items into the ConfigMaps of a list owned by owner.
+// Additional shards are written before the first ConfigMap, which carries the
+// shard count and items hash, and shards no longer needed are removed last.
+// It returns the number of ConfigMaps used.
+func storeItems(ctx context.Context, c client.Client, scheme *runtime.Scheme, owner client.Object, items []string, compression batchopsv1alpha1.ListCompression) (int, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	configMaps, err := buildListConfigMaps(owner.GetName(), owner.GetNamespace(), items, compression)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode items: %w", err)
+	}
+
+	for i := len(configMaps) - 1; i >= 0; i-- {
+		if err := ctrl.SetControllerReference(owner, configMaps[i], scheme); err != nil {
+			return 0, err
+		}
+		if err := applyListConfigMap(ctx, c, configMaps[i]); err != nil {
+			return 0, err
+		}
+	}
+
+	var shards corev1.ConfigMapList
+	if err := c.List(ctx, &shards, client.InNamespace(owner.GetNamespace()), client.MatchingLabels{shardOfLabel: owner.GetName()}); err != nil {
+		return 0, fmt.Errorf("failed to list ConfigMap shards: %w", err)
+	}
+	for i := range shards.Items {
+		shard := &shards.Items[i]
+		if shardIndex(owner.GetName(), shard.Name) < len(configMaps) {
+			continue
+		}
+		log.Info("Removing unused ConfigMap shard", "configMap", shard.Name)
+		if err := c.Delete(ctx, shard); err != nil && !apierrors.IsNotFound(err) {
+			return 0, fmt.Errorf("failed to delete ConfigMap shard %s: %w", shard.Name, err)
+		}
+	}
+
+	return len(configMaps), nil
+}
+
+// shardIndex returns the shard number encoded in a shard ConfigMap name, or a
+// large number for names that do not follow shardName.
+func shardIndex(name, shard string) int {
+	index, err := strconv.Atoi(strings.TrimPrefix(shard, name+"-shard-"))
+	if err != nil || index < 1 {
+		return int(^uint(0) >> 1)
+	}
+	return index
+}
+
+// applyListConfigMap creates the ConfigMap or updates the items, labels and
+// annotations of an existing one.
+func applyListConfigMap(ctx context.Context, c client.Client, desired *corev1.ConfigMap) error {
+	var existing corev1.ConfigMap
+	err := c.Get(ctx, client.ObjectKeyFromObject(desired), &existing)
+	if apierrors.IsNotFound(err) {
+		if err := c.Create(ctx, desired); err != nil {
+			return fmt.Errorf("failed to create ConfigMap %s: %w", desired.Name, err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get ConfigMap %s: %w", desired.Name, err)
+	}
+
+	if equality.Semantic.DeepEqual(existing.Data, desired.Data) &&
+		equality.Semantic.DeepEqual(existing.BinaryData, desired.BinaryData) &&
+		equality.Semantic.DeepEqual(existing.Annotations, desired.Annotations) &&
+		equality.Semantic.DeepEqual(existing.Labels, desired.Labels) {
+		return nil
+	}
+	existing.Data = desired.Data
+	existing.BinaryData = desired.BinaryData
+	existing.Annotations = desired.Annotations
+	existing.Labels = desired.Labels
+	if err := c.Update(ctx, &existing); err != nil {
+		return fmt.Errorf("failed to update ConfigMap %s: %w", desired.Name, err)
+	}
+	return nil
+}
+
+// readItems reads the items of a list stored by storeItems or of a single
+// plain ConfigMap, following shards and decompressing as needed.
+func readItems(ctx context.Context, c client.Reader, namespace, name string) ([]string, error) {
+	return readItemsWith(ctx, c, namespace, name, decodeItems)
+}
+
+// readItemsWith is readItems with the items of every ConfigMap decoded by decode.
+func readItemsWith(ctx context.Context, c client.Reader, namespace, name string, decode func(*corev1.ConfigMap) ([]string, error)) ([]string, error) {
+	var first corev1.ConfigMap
+	if err := c.Get(ctx, client.ObjectKey{Name: name, Namespace: namespace}, &first); err != nil {
+		return nil, err
+	}
+
+	shards := 1
+	if value, ok := first.Annotations[shardsAnnotation]; ok {
+		count, err := strconv.Atoi(value)
+		if err != nil || count < 1 {
+			return nil, fmt.Errorf("invalid %s annotation %q on ConfigMap %s", shardsAnnotation, value, name)
+		}
+		shards = count
+	}
+
+	var items []string
+	for i := 0; i < shards; i++ {
+		cm := &first
+		if i > 0 {
+			cm = &corev1.ConfigMap{}
+			if err := c.Get(ctx, client.ObjectKey{Name: shardName(name, i), Namespace: namespace}, cm); err != nil {
+				return nil, err
+			}
+		}
+		chunk, err := decode(cm)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, chunk...)
+	}
+
+	if hash, ok := first.Annotations[itemsHashAnnotation]; ok && shards > 1 && hash != itemsHash(items) {
+		return nil, fmt.Errorf("shards of ConfigMap %s are being updated", name)
+	}
+	return items, nil
+}
+
+// decodeItems returns the newline-separated items of a single ConfigMap.
+func decodeItems(cm *corev1.ConfigMap) ([]string, error) {
+	itemsStr := cm.Data[itemsKey]
+	if compressed, ok := cm.BinaryData[itemsGzipKey]; ok {
+		reader, err := gzip.NewReader(bytes.NewReader(compressed))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress items of ConfigMap %s: %w", cm.Name, err)
+		}
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress items of ConfigMap %s: %w", cm.Name, err)
+		}
+		itemsStr = string(data)
+	}
+	if itemsStr == "" {
+		return nil, nil
+	}
+	return strings.Split(itemsStr, "\n"), nil
+}
+
+// itemsCache keeps the decoded items of list ConfigMaps, so that reading a
+// list again only decodes the ConfigMaps whose resourceVersion changed.
+// Entries are grouped by the object reading the list and dropped with forget.
+type itemsCache struct {
+	mu      sync.Mutex
+	entries map[types.NamespacedName]map[string]cachedItems
+}
+
+type cachedItems struct {
+	resourceVersion string
+	items           []string
+}
+
+// read is readItems for owner, with the ConfigMaps decoded from the cache.
+// The returned items are shared and must not be modified.
+func (c *itemsCache) read(ctx context.Context, reader client.Reader, owner types.NamespacedName, name string) ([]string, error) {
+	return readItemsWith(ctx, reader, owner.Namespace, name, func(cm *corev1.ConfigMap) ([]string, error) {
+		c.mu.Lock()
+		cached, ok := c.entries[owner][cm.Name]
+		c.mu.Unlock()
+		if ok && cached.resourceVersion == cm.ResourceVersion {
+			return cached.items, nil
+		}
+
+		items, err := decodeItems(cm)
+		if err != nil {
+			return nil, err
+		}
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if c.entries == nil {
+			c.entries = map[types.NamespacedName]map[string]cachedItems{}
+		}
+		if c.entries[owner] == nil {
+			c.entries[owner] = map[string]cachedItems{}
+		}
+		c.entries[owner][cm.Name] = cachedItems{resourceVersion: cm.ResourceVersion, items: items}
+		return items, nil
+	})
+}
+
+// forget drops the cached items read for owner.
+func (c *itemsCache) forget(owner types.NamespacedName) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, owner)
+}
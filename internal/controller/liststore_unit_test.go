@@ -0,0 +1,191 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	batchopsv1alpha1 "github.com/matanryngler/parallax/api/v1alpha1"
+)
+
+// largeList returns count items of roughly 100 bytes each.
+func largeList(count int) []string {
+	items := make([]string, count)
+	for i := range items {
+		items[i] = fmt.Sprintf("item-%06d-%s", i, strings.Repeat("x", 88))
+	}
+	return items
+}
+
+func TestSplitItems(t *testing.T) {
+	items := []string{"aa", "bb", "cc", "dd", "ee"}
+
+	assert.Equal(t, [][]string{items}, splitItems(items, 1, 0, itemCost, 100))
+	assert.Equal(t, [][]string{{"aa", "bb"}, {"cc", "dd"}, {"ee"}}, splitItems(items, 1, 0, itemCost, 6))
+	assert.Equal(t, [][]string{{"aa", "bb", "cc"}, {"dd", "ee"}}, splitItems(items, 1, 3, itemCost, 100))
+	// Units are never split, even when a single unit exceeds the limit
+	assert.Equal(t, [][]string{{"aa", "bb"}, {"cc", "dd"}, {"ee"}}, splitItems(items, 2, 0, itemCost, 3))
+	chunks := splitItems(nil, 1, 0, itemCost, 100)
+	require.Len(t, chunks, 1)
+	assert.Empty(t, chunks[0])
+}
+
+func TestJobShardName(t *testing.T) {
+	assert.Equal(t, "nightly-29000000", jobShardName("nightly-29000000", 0))
+	assert.Equal(t, "nightly-29000000-shard-2", jobShardName("nightly-29000000", 2))
+
+	long := strings.Repeat("a", 51) + "-29000000"
+	assert.Equal(t, long, jobShardName(long, 0))
+	for shard := 1; shard <= 10; shard++ {
+		name := jobShardName(long, shard)
+		assert.LessOrEqual(t, len(name), maxJobNameLength)
+		assert.True(t, strings.HasSuffix(name, fmt.Sprintf("-shard-%d", shard)), name)
+		assert.Equal(t, name, jobShardName(long, shard), "names are stable")
+	}
+	assert.NotEqual(t, jobShardName(long, 1), jobShardName(strings.Repeat("a", 51)+"-29000001", 1))
+}
+
+func TestStoreItems(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, batchopsv1alpha1.AddToScheme(scheme))
+	require.NoError(t, corev1.AddToScheme(scheme))
+
+	owner := &batchopsv1alpha1.ListSource{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-source", Namespace: "default", UID: "source-uid"},
+	}
+	ctx := context.Background()
+
+	t.Run("Small List", func(t *testing.T) {
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+		shards, err := storeItems(ctx, fakeClient, scheme, owner, []string{"a", "b"}, "")
+		require.NoError(t, err)
+		assert.Equal(t, 1, shards)
+
+		var cm corev1.ConfigMap
+		require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "test-source", Namespace: "default"}, &cm))
+		assert.Equal(t, map[string]string{"items": "a\nb"}, cm.Data)
+		assert.NotContains(t, cm.Annotations, shardsAnnotation)
+	})
+
+	t.Run("Sharded List", func(t *testing.T) {
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+		items := largeList(20000)
+		shards, err := storeItems(ctx, fakeClient, scheme, owner, items, batchopsv1alpha1.NoCompression)
+		require.NoError(t, err)
+		assert.Equal(t, 3, shards)
+
+		var shard corev1.ConfigMap
+		require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "test-source-shard-2", Namespace: "default"}, &shard))
+		assert.Equal(t, "test-source", shard.Labels[shardOfLabel])
+		assert.LessOrEqual(t, len(shard.Data["items"]), maxConfigMapItemsSize)
+
+		read, err := readItems(ctx, fakeClient, "default", "test-source")
+		require.NoError(t, err)
+		assert.Equal(t, items, read)
+
+		// Shrinking the list removes the shards no longer needed
+		shards, err = storeItems(ctx, fakeClient, scheme, owner, items[:10], batchopsv1alpha1.NoCompression)
+		require.NoError(t, err)
+		assert.Equal(t, 1, shards)
+		err = fakeClient.Get(ctx, types.NamespacedName{Name: "test-source-shard-1", Namespace: "default"}, &shard)
+		assert.True(t, apierrors.IsNotFound(err))
+
+		read, err = readItems(ctx, fakeClient, "default", "test-source")
+		require.NoError(t, err)
+		assert.Equal(t, items[:10], read)
+	})
+
+	t.Run("Gzip", func(t *testing.T) {
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+		items := largeList(20000)
+		shards, err := storeItems(ctx, fakeClient, scheme, owner, items, batchopsv1alpha1.GzipCompression)
+		require.NoError(t, err)
+		assert.Equal(t, 1, shards)
+
+		var cm corev1.ConfigMap
+		require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "test-source", Namespace: "default"}, &cm))
+		assert.Empty(t, cm.Data)
+		assert.NotEmpty(t, cm.BinaryData[itemsGzipKey])
+
+		read, err := getListSourceItems(ctx, fakeClient, "default", "test-source")
+		require.NoError(t, err)
+		assert.Equal(t, items, read)
+	})
+
+	t.Run("Inconsistent Shards", func(t *testing.T) {
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+		_, err := storeItems(ctx, fakeClient, scheme, owner, largeList(20000), batchopsv1alpha1.NoCompression)
+		require.NoError(t, err)
+
+		var shard corev1.ConfigMap
+		require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "test-source-shard-1", Namespace: "default"}, &shard))
+		shard.Data["items"] = "changed"
+		require.NoError(t, fakeClient.Update(ctx, &shard))
+
+		_, err = readItems(ctx, fakeClient, "default", "test-source")
+		assert.ErrorContains(t, err, "being updated")
+	})
+}
+
+func TestItemsCache(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, batchopsv1alpha1.AddToScheme(scheme))
+	require.NoError(t, corev1.AddToScheme(scheme))
+
+	owner := &batchopsv1alpha1.ListSource{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-source", Namespace: "default", UID: "source-uid"},
+	}
+	key := types.NamespacedName{Name: "reader", Namespace: "default"}
+	ctx := context.Background()
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+	var cache itemsCache
+
+	_, err := storeItems(ctx, fakeClient, scheme, owner, []string{"a", "b"}, batchopsv1alpha1.GzipCompression)
+	require.NoError(t, err)
+	read, err := cache.read(ctx, fakeClient, key, "test-source")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, read)
+
+	// An unchanged ConfigMap is not decoded again
+	cached := cache.entries[key]["test-source"]
+	cache.entries[key]["test-source"] = cachedItems{resourceVersion: cached.resourceVersion, items: []string{"cached"}}
+	read, err = cache.read(ctx, fakeClient, key, "test-source")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cached"}, read)
+
+	// A changed one is
+	_, err = storeItems(ctx, fakeClient, scheme, owner, []string{"c"}, batchopsv1alpha1.GzipCompression)
+	require.NoError(t, err)
+	read, err = cache.read(ctx, fakeClient, key, "test-source")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c"}, read)
+
+	cache.forget(key)
+	assert.Empty(t, cache.entries)
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/robfig/cron/v3"
+	batchv1 "k8s.io/api/batch/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	batchopsv1alpha1 "github.com/matanryngler/parallax/api/v1alpha1"
+)
+
+// log is for logging in this package.
+var listcronjoblog = logf.Log.WithName("listcronjob-resource")
+
+// SetupListCronJobWebhookWithManager registers the webhook for ListCronJob in the manager.
+func SetupListCronJobWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&batchopsv1alpha1.ListCronJob{}).
+		WithValidator(&ListCronJobCustomValidator{}).
+		WithDefaulter(&ListCronJobCustomDefaulter{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-batchops-io-v1alpha1-listcronjob,mutating=true,failurePolicy=fail,sideEffects=None,groups=batchops.io,resources=listcronjobs,verbs=create;update,versions=v1alpha1,name=mlistcronjob-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// ListCronJobCustomDefaulter sets default values on ListCronJobs when they
+// are created or updated.
+type ListCronJobCustomDefaulter struct{}
+
+var _ webhook.CustomDefaulter = &ListCronJobCustomDefaulter{}
+
+// Default implements webhook.CustomDefaulter so a webhook will be registered for the Kind ListCronJob.
+func (d *ListCronJobCustomDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	listcronjob, ok := obj.(*batchopsv1alpha1.ListCronJob)
+	if !ok {
+		return fmt.Errorf("expected a ListCronJob object but got %T", obj)
+	}
+	listcronjoblog.V(1).Info("Defaulting for ListCronJob", "name", listcronjob.GetName())
+
+	defaultParallelism(&listcronjob.Spec.Parallelism)
+	defaultTemplate(&listcronjob.Spec.Template)
+	if listcronjob.Spec.ConcurrencyPolicy == "" {
+		listcronjob.Spec.ConcurrencyPolicy = batchv1.AllowConcurrent
+	}
+	if listcronjob.Spec.Suspend == nil {
+		listcronjob.Spec.Suspend = new(bool)
+	}
+	if listcronjob.Spec.SuccessfulJobsHistoryLimit == nil {
+		limit := int32(batchopsv1alpha1.DefaultSuccessfulJobsHistoryLimit)
+		listcronjob.Spec.SuccessfulJobsHistoryLimit = &limit
+	}
+	if listcronjob.Spec.FailedJobsHistoryLimit == nil {
+		limit := int32(batchopsv1alpha1.DefaultFailedJobsHistoryLimit)
+		listcronjob.Spec.FailedJobsHistoryLimit = &limit
+	}
+	return nil
+}
+
+// +kubebuilder:webhook:path=/validate-batchops-io-v1alpha1-listcronjob,mutating=false,failurePolicy=fail,sideEffects=None,groups=batchops.io,resources=listcronjobs,verbs=create;update,versions=v1alpha1,name=vlistcronjob-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// ListCronJobCustomValidator validates ListCronJobs when they are created or
+// updated.
+type ListCronJobCustomValidator struct{}
+
+var _ webhook.CustomValidator = &ListCronJobCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type ListCronJob.
+func (v *ListCronJobCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	listcronjob, ok := obj.(*batchopsv1alpha1.ListCronJob)
+	if !ok {
+		return nil, fmt.Errorf("expected a ListCronJob object but got %T", obj)
+	}
+	listcronjoblog.V(1).Info("Validation for ListCronJob upon creation", "name", listcronjob.GetName())
+
+	return nil, validateListCronJob(listcronjob)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type ListCronJob.
+func (v *ListCronJobCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	listcronjob, ok := newObj.(*batchopsv1alpha1.ListCronJob)
+	if !ok {
+		return nil, fmt.Errorf("expected a ListCronJob object for the newObj but got %T", newObj)
+	}
+	old, ok := oldObj.(*batchopsv1alpha1.ListCronJob)
+	if !ok {
+		return nil, fmt.Errorf("expected a ListCronJob object for the oldObj but got %T", oldObj)
+	}
+	listcronjoblog.V(1).Info("Validation for ListCronJob upon update", "name", listcronjob.GetName())
+
+	// The old object may predate the webhook, default it like the new one
+	old = old.DeepCopy()
+	if err := (&ListCronJobCustomDefaulter{}).Default(ctx, old); err != nil {
+		return nil, err
+	}
+	if !specChanged(listcronjob.DeletionTimestamp, old.Spec, listcronjob.Spec) {
+		return nil, nil
+	}
+	return nil, validateListCronJob(listcronjob)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type ListCronJob.
+func (v *ListCronJobCustomValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func validateListCronJob(listcronjob *batchopsv1alpha1.ListCronJob) error {
+	specPath := field.NewPath("spec")
+	errs := validateList(listcronjob.Spec.StaticList, listcronjob.Spec.ListSourceRef, specPath)
+	errs = append(errs, validateParallelism(listcronjob.Spec.Parallelism, specPath.Child("parallelism"))...)
+	errs = append(errs, validateTemplate(listcronjob.Spec.Template, specPath.Child("template"))...)
+	if _, err := cron.ParseStandard(listcronjob.Spec.Schedule); err != nil {
+		errs = append(errs, field.Invalid(specPath.Child("schedule"), listcronjob.Spec.Schedule, err.Error()))
+	}
+	if len(listcronjob.Name) > batchopsv1alpha1.MaxListCronJobNameLength {
+		errs = append(errs, field.TooLong(field.NewPath("metadata", "name"), listcronjob.Name, batchopsv1alpha1.MaxListCronJobNameLength))
+	}
+	if listcronjob.Spec.RefreshListSource && listcronjob.Spec.ListSourceRef == "" {
+		errs = append(errs, field.Forbidden(specPath.Child("refreshListSource"), "requires listSourceRef"))
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(batchopsv1alpha1.GroupVersion.WithKind("ListCronJob").GroupKind(), listcronjob.Name, errs)
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	batchopsv1alpha1 "github.com/matanryngler/parallax/api/v1alpha1"
+)
+
+func TestListCronJobDefaulter(t *testing.T) {
+	listCronJob := &batchopsv1alpha1.ListCronJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "default"},
+		Spec: batchopsv1alpha1.ListCronJobSpec{
+			Schedule:   "0 2 * * *",
+			StaticList: []string{"a"},
+			Template:   batchopsv1alpha1.JobTemplateSpec{Image: "busybox"},
+		},
+	}
+
+	require.NoError(t, (&ListCronJobCustomDefaulter{}).Default(context.Background(), listCronJob))
+	spec := listCronJob.Spec
+	assert.Equal(t, int32(1), spec.Parallelism)
+	assert.Equal(t, batchopsv1alpha1.DefaultEnvName, spec.Template.EnvName)
+	assert.Equal(t, batchv1.AllowConcurrent, spec.ConcurrencyPolicy)
+	require.NotNil(t, spec.Suspend)
+	assert.False(t, *spec.Suspend)
+	require.NotNil(t, spec.SuccessfulJobsHistoryLimit)
+	assert.Equal(t, int32(batchopsv1alpha1.DefaultSuccessfulJobsHistoryLimit), *spec.SuccessfulJobsHistoryLimit)
+	require.NotNil(t, spec.FailedJobsHistoryLimit)
+	assert.Equal(t, int32(batchopsv1alpha1.DefaultFailedJobsHistoryLimit), *spec.FailedJobsHistoryLimit)
+}
+
+func TestListCronJobValidator(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(spec *batchopsv1alpha1.ListCronJobSpec)
+		wantErr string
+	}{
+		{
+			name:   "valid",
+			mutate: func(spec *batchopsv1alpha1.ListCronJobSpec) {},
+		},
+		{
+			name:   "valid descriptor",
+			mutate: func(spec *batchopsv1alpha1.ListCronJobSpec) { spec.Schedule = "@hourly" },
+		},
+		{
+			name:    "invalid schedule",
+			mutate:  func(spec *batchopsv1alpha1.ListCronJobSpec) { spec.Schedule = "every night" },
+			wantErr: "spec.schedule",
+		},
+		{
+			name:    "no list",
+			mutate:  func(spec *batchopsv1alpha1.ListCronJobSpec) { spec.StaticList = nil },
+			wantErr: "spec.staticList",
+		},
+		{
+			name:    "negative parallelism",
+			mutate:  func(spec *batchopsv1alpha1.ListCronJobSpec) { spec.Parallelism = -1 },
+			wantErr: "spec.parallelism",
+		},
+		{
+			name:    "refresh without list source",
+			mutate:  func(spec *batchopsv1alpha1.ListCronJobSpec) { spec.RefreshListSource = true },
+			wantErr: "spec.refreshListSource",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listCronJob := &batchopsv1alpha1.ListCronJob{
+				ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "default"},
+				Spec: batchopsv1alpha1.ListCronJobSpec{
+					Schedule:    "0 2 * * *",
+					StaticList:  []string{"a", "b"},
+					Parallelism: 1,
+					Template:    batchopsv1alpha1.JobTemplateSpec{Image: "busybox", EnvName: "ITEM"},
+				},
+			}
+			tt.mutate(&listCronJob.Spec)
+
+			_, err := (&ListCronJobCustomValidator{}).ValidateCreate(context.Background(), listCronJob)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.True(t, apierrors.IsInvalid(err))
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestListCronJobValidator_NameLength(t *testing.T) {
+	listCronJob := &batchopsv1alpha1.ListCronJob{
+		ObjectMeta: metav1.ObjectMeta{Name: strings.Repeat("a", 52), Namespace: "default"},
+		Spec: batchopsv1alpha1.ListCronJobSpec{
+			Schedule:    "0 2 * * *",
+			StaticList:  []string{"a"},
+			Parallelism: 1,
+			Template:    batchopsv1alpha1.JobTemplateSpec{Image: "busybox"},
+		},
+	}
+	_, err := (&ListCronJobCustomValidator{}).ValidateCreate(context.Background(), listCronJob)
+	assert.NoError(t, err)
+
+	listCronJob.Name += "a"
+	_, err = (&ListCronJobCustomValidator{}).ValidateCreate(context.Background(), listCronJob)
+	assert.ErrorContains(t, err, "metadata.name")
+}
+
+func TestListCronJobValidator_UpdateWithoutSpecChange(t *testing.T) {
+	// Stored before the webhook was enabled, and invalid under its rules
+	old := &batchopsv1alpha1.ListCronJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "default", Finalizers: []string{"batchops.io/finalizer"}},
+		Spec: batchopsv1alpha1.ListCronJobSpec{
+			Schedule:   "not a schedule",
+			StaticList: []string{"a"},
+			Template:   batchopsv1alpha1.JobTemplateSpec{Image: "busybox"},
+		},
+	}
+	validator := &ListCronJobCustomValidator{}
+
+	updated := old.DeepCopy()
+	updated.Finalizers = nil
+	require.NoError(t, (&ListCronJobCustomDefaulter{}).Default(context.Background(), updated))
+	_, err := validator.ValidateUpdate(context.Background(), old, updated)
+	assert.NoError(t, err, "metadata updates are not validated")
+
+	updated.Spec.Parallelism = 3
+	_, err = validator.ValidateUpdate(context.Background(), old, updated)
+	assert.ErrorContains(t, err, "spec.schedule")
+
+	updated.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	_, err = validator.ValidateUpdate(context.Background(), old, updated)
+	assert.NoError(t, err, "objects being deleted are not validated")
+}
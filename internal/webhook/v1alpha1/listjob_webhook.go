@@ -0,0 +1,125 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	batchopsv1alpha1 "github.com/matanryngler/parallax/api/v1alpha1"
+)
+
+// log is for logging in this package.
+var listjoblog = logf.Log.WithName("listjob-resource")
+
+// SetupListJobWebhookWithManager registers the webhook for ListJob in the manager.
+func SetupListJobWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&batchopsv1alpha1.ListJob{}).
+		WithValidator(&ListJobCustomValidator{}).
+		WithDefaulter(&ListJobCustomDefaulter{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-batchops-io-v1alpha1-listjob,mutating=true,failurePolicy=fail,sideEffects=None,groups=batchops.io,resources=listjobs,verbs=create;update,versions=v1alpha1,name=mlistjob-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// ListJobCustomDefaulter sets default values on ListJobs when they are
+// created or updated.
+type ListJobCustomDefaulter struct{}
+
+var _ webhook.CustomDefaulter = &ListJobCustomDefaulter{}
+
+// Default implements webhook.CustomDefaulter so a webhook will be registered for the Kind ListJob.
+func (d *ListJobCustomDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	listjob, ok := obj.(*batchopsv1alpha1.ListJob)
+	if !ok {
+		return fmt.Errorf("expected a ListJob object but got %T", obj)
+	}
+	listjoblog.V(1).Info("Defaulting for ListJob", "name", listjob.GetName())
+
+	defaultParallelism(&listjob.Spec.Parallelism)
+	defaultTemplate(&listjob.Spec.Template)
+	return nil
+}
+
+// +kubebuilder:webhook:path=/validate-batchops-io-v1alpha1-listjob,mutating=false,failurePolicy=fail,sideEffects=None,groups=batchops.io,resources=listjobs,verbs=create;update,versions=v1alpha1,name=vlistjob-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// ListJobCustomValidator validates ListJobs when they are created or updated,
+// so misconfigurations are rejected instead of surfacing as reconcile errors.
+type ListJobCustomValidator struct{}
+
+var _ webhook.CustomValidator = &ListJobCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type ListJob.
+func (v *ListJobCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	listjob, ok := obj.(*batchopsv1alpha1.ListJob)
+	if !ok {
+		return nil, fmt.Errorf("expected a ListJob object but got %T", obj)
+	}
+	listjoblog.V(1).Info("Validation for ListJob upon creation", "name", listjob.GetName())
+
+	return nil, validateListJob(listjob)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type ListJob.
+func (v *ListJobCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	listjob, ok := newObj.(*batchopsv1alpha1.ListJob)
+	if !ok {
+		return nil, fmt.Errorf("expected a ListJob object for the newObj but got %T", newObj)
+	}
+	old, ok := oldObj.(*batchopsv1alpha1.ListJob)
+	if !ok {
+		return nil, fmt.Errorf("expected a ListJob object for the oldObj but got %T", oldObj)
+	}
+	listjoblog.V(1).Info("Validation for ListJob upon update", "name", listjob.GetName())
+
+	// The old object may predate the webhook, default it like the new one
+	old = old.DeepCopy()
+	if err := (&ListJobCustomDefaulter{}).Default(ctx, old); err != nil {
+		return nil, err
+	}
+	if !specChanged(listjob.DeletionTimestamp, old.Spec, listjob.Spec) {
+		return nil, nil
+	}
+	return nil, validateListJob(listjob)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type ListJob.
+func (v *ListJobCustomValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func validateListJob(listjob *batchopsv1alpha1.ListJob) error {
+	specPath := field.NewPath("spec")
+	errs := validateList(listjob.Spec.StaticList, listjob.Spec.ListSourceRef, specPath)
+	errs = append(errs, validateParallelism(listjob.Spec.Parallelism, specPath.Child("parallelism"))...)
+	errs = append(errs, validateTemplate(listjob.Spec.Template, specPath.Child("template"))...)
+	if len(listjob.Name) > batchopsv1alpha1.MaxListJobNameLength {
+		errs = append(errs, field.TooLong(field.NewPath("metadata", "name"), listjob.Name, batchopsv1alpha1.MaxListJobNameLength))
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(batchopsv1alpha1.GroupVersion.WithKind("ListJob").GroupKind(), listjob.Name, errs)
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	batchopsv1alpha1 "github.com/matanryngler/parallax/api/v1alpha1"
+)
+
+func TestListJobDefaulter(t *testing.T) {
+	listJob := &batchopsv1alpha1.ListJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: batchopsv1alpha1.ListJobSpec{
+			StaticList: []string{"a"},
+			Template:   batchopsv1alpha1.JobTemplateSpec{Image: "busybox"},
+		},
+	}
+
+	require.NoError(t, (&ListJobCustomDefaulter{}).Default(context.Background(), listJob))
+	assert.Equal(t, int32(1), listJob.Spec.Parallelism)
+	assert.Equal(t, batchopsv1alpha1.DefaultEnvName, listJob.Spec.Template.EnvName)
+
+	// Values that are set are kept
+	listJob.Spec.Parallelism = 5
+	listJob.Spec.Template.EnvName = "CUSTOMER"
+	require.NoError(t, (&ListJobCustomDefaulter{}).Default(context.Background(), listJob))
+	assert.Equal(t, int32(5), listJob.Spec.Parallelism)
+	assert.Equal(t, "CUSTOMER", listJob.Spec.Template.EnvName)
+}
+
+func TestListJobValidator(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(spec *batchopsv1alpha1.ListJobSpec)
+		wantErr string
+	}{
+		{
+			name:   "valid",
+			mutate: func(spec *batchopsv1alpha1.ListJobSpec) {},
+		},
+		{
+			name:    "no list",
+			mutate:  func(spec *batchopsv1alpha1.ListJobSpec) { spec.StaticList = nil },
+			wantErr: "spec.staticList",
+		},
+		{
+			name:    "both lists",
+			mutate:  func(spec *batchopsv1alpha1.ListJobSpec) { spec.ListSourceRef = "customers" },
+			wantErr: "spec.listSourceRef",
+		},
+		{
+			name:    "zero parallelism",
+			mutate:  func(spec *batchopsv1alpha1.ListJobSpec) { spec.Parallelism = 0 },
+			wantErr: "spec.parallelism",
+		},
+		{
+			name:    "invalid env name",
+			mutate:  func(spec *batchopsv1alpha1.ListJobSpec) { spec.Template.EnvName = "MY-ITEM" },
+			wantErr: "spec.template.envName",
+		},
+		{
+			name: "duplicate field",
+			mutate: func(spec *batchopsv1alpha1.ListJobSpec) {
+				spec.Template.Fields = []batchopsv1alpha1.ItemField{{Name: "id"}, {Name: "id"}}
+			},
+			wantErr: "spec.template.fields[1].name",
+		},
+		{
+			name: "duplicate field env name",
+			mutate: func(spec *batchopsv1alpha1.ListJobSpec) {
+				spec.Template.Fields = []batchopsv1alpha1.ItemField{{Name: "user-id"}, {Name: "user_id"}}
+			},
+			wantErr: `spec.template.fields[1].envName: Duplicate value: "ITEM_USER_ID"`,
+		},
+		{
+			name: "field env name of the item",
+			mutate: func(spec *batchopsv1alpha1.ListJobSpec) {
+				spec.Template.Fields = []batchopsv1alpha1.ItemField{{Name: "id", EnvName: "ITEM"}}
+			},
+			wantErr: `spec.template.fields[0].envName: Duplicate value: "ITEM"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listJob := &batchopsv1alpha1.ListJob{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+				Spec: batchopsv1alpha1.ListJobSpec{
+					StaticList:  []string{"a", "b"},
+					Parallelism: 2,
+					Template:    batchopsv1alpha1.JobTemplateSpec{Image: "busybox", EnvName: "ITEM"},
+				},
+			}
+			old := listJob.DeepCopy()
+			old.Spec.Parallelism = 1
+			tt.mutate(&listJob.Spec)
+
+			validator := &ListJobCustomValidator{}
+			_, createErr := validator.ValidateCreate(context.Background(), listJob)
+			_, updateErr := validator.ValidateUpdate(context.Background(), old, listJob)
+			if tt.wantErr == "" {
+				assert.NoError(t, createErr)
+				assert.NoError(t, updateErr)
+				return
+			}
+			require.Error(t, createErr)
+			assert.True(t, apierrors.IsInvalid(createErr))
+			assert.Contains(t, createErr.Error(), tt.wantErr)
+			assert.Error(t, updateErr)
+		})
+	}
+}
+
+func TestListJobValidator_NameLength(t *testing.T) {
+	listJob := &batchopsv1alpha1.ListJob{
+		ObjectMeta: metav1.ObjectMeta{Name: strings.Repeat("a", 63), Namespace: "default"},
+		Spec: batchopsv1alpha1.ListJobSpec{
+			StaticList:  []string{"a"},
+			Parallelism: 1,
+			Template:    batchopsv1alpha1.JobTemplateSpec{Image: "busybox"},
+		},
+	}
+	_, err := (&ListJobCustomValidator{}).ValidateCreate(context.Background(), listJob)
+	assert.NoError(t, err)
+
+	listJob.Name += "a"
+	_, err = (&ListJobCustomValidator{}).ValidateCreate(context.Background(), listJob)
+	assert.ErrorContains(t, err, "metadata.name")
+}
+
+func TestListJobValidator_UpdateWithoutSpecChange(t *testing.T) {
+	// Stored before the webhook was enabled, and invalid under its rules
+	old := &batchopsv1alpha1.ListJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default", Finalizers: []string{"batchops.io/finalizer"}},
+		Spec: batchopsv1alpha1.ListJobSpec{
+			StaticList: []string{"a"},
+			Template:   batchopsv1alpha1.JobTemplateSpec{Image: "busybox", EnvName: "MY-ITEM"},
+		},
+	}
+	validator := &ListJobCustomValidator{}
+
+	updated := old.DeepCopy()
+	updated.Finalizers = nil
+	require.NoError(t, (&ListJobCustomDefaulter{}).Default(context.Background(), updated))
+	_, err := validator.ValidateUpdate(context.Background(), old, updated)
+	assert.NoError(t, err, "metadata updates are not validated")
+
+	updated.Spec.Parallelism = 3
+	_, err = validator.ValidateUpdate(context.Background(), old, updated)
+	assert.ErrorContains(t, err, "spec.template.envName")
+
+	updated.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	_, err = validator.ValidateUpdate(context.Background(), old, updated)
+	assert.NoError(t, err, "objects being deleted are not validated")
+}
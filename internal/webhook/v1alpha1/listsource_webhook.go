@@ -0,0 +1,572 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	pathpkg "path"
+	"regexp"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/util/jsonpath"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	batchopsv1alpha1 "github.com/matanryngler/parallax/api/v1alpha1"
+)
+
+// log is for logging in this package.
+var listsourcelog = logf.Log.WithName("listsource-resource")
+
+// SetupListSourceWebhookWithManager registers the webhook for ListSource in the manager.
+func SetupListSourceWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&batchopsv1alpha1.ListSource{}).
+		WithValidator(&ListSourceCustomValidator{}).
+		WithDefaulter(&ListSourceCustomDefaulter{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-batchops-io-v1alpha1-listsource,mutating=true,failurePolicy=fail,sideEffects=None,groups=batchops.io,resources=listsources,verbs=create;update,versions=v1alpha1,name=mlistsource-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// ListSourceCustomDefaulter sets default values on ListSources when they are
+// created or updated.
+type ListSourceCustomDefaulter struct{}
+
+var _ webhook.CustomDefaulter = &ListSourceCustomDefaulter{}
+
+// Default implements webhook.CustomDefaulter so a webhook will be registered for the Kind ListSource.
+func (d *ListSourceCustomDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	listsource, ok := obj.(*batchopsv1alpha1.ListSource)
+	if !ok {
+		return fmt.Errorf("expected a ListSource object but got %T", obj)
+	}
+	listsourcelog.V(1).Info("Defaulting for ListSource", "name", listsource.GetName())
+
+	if listsource.Spec.ItemFormat == "" {
+		listsource.Spec.ItemFormat = batchopsv1alpha1.TextItemFormat
+	}
+	if listsource.Spec.Compression == "" {
+		listsource.Spec.Compression = batchopsv1alpha1.NoCompression
+	}
+	return nil
+}
+
+// +kubebuilder:webhook:path=/validate-batchops-io-v1alpha1-listsource,mutating=false,failurePolicy=fail,sideEffects=None,groups=batchops.io,resources=listsources,verbs=create;update,versions=v1alpha1,name=vlistsource-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// ListSourceCustomValidator validates ListSources when they are created or
+// updated.
+type ListSourceCustomValidator struct{}
+
+var _ webhook.CustomValidator = &ListSourceCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type ListSource.
+func (v *ListSourceCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	listsource, ok := obj.(*batchopsv1alpha1.ListSource)
+	if !ok {
+		return nil, fmt.Errorf("expected a ListSource object but got %T", obj)
+	}
+	listsourcelog.V(1).Info("Validation for ListSource upon creation", "name", listsource.GetName())
+
+	return validateListSource(listsource)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type ListSource.
+func (v *ListSourceCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	listsource, ok := newObj.(*batchopsv1alpha1.ListSource)
+	if !ok {
+		return nil, fmt.Errorf("expected a ListSource object for the newObj but got %T", newObj)
+	}
+	old, ok := oldObj.(*batchopsv1alpha1.ListSource)
+	if !ok {
+		return nil, fmt.Errorf("expected a ListSource object for the oldObj but got %T", oldObj)
+	}
+	listsourcelog.V(1).Info("Validation for ListSource upon update", "name", listsource.GetName())
+
+	// The old object may predate the webhook, default it like the new one
+	old = old.DeepCopy()
+	if err := (&ListSourceCustomDefaulter{}).Default(ctx, old); err != nil {
+		return nil, err
+	}
+	if !specChanged(listsource.DeletionTimestamp, old.Spec, listsource.Spec) {
+		return nil, nil
+	}
+	return validateListSource(listsource)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type ListSource.
+func (v *ListSourceCustomValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateListSource checks that the configuration the type needs is present
+// and usable. Configuration of other types is ignored with a warning.
+func validateListSource(listsource *batchopsv1alpha1.ListSource) (admission.Warnings, error) {
+	spec := listsource.Spec
+	specPath := field.NewPath("spec")
+
+	var errs field.ErrorList
+	var warnings admission.Warnings
+	switch spec.Type {
+	case batchopsv1alpha1.StaticList:
+		if len(spec.StaticList) == 0 {
+			errs = append(errs, field.Required(specPath.Child("staticList"), "required for type static"))
+		}
+	case batchopsv1alpha1.APIList:
+		errs = append(errs, validateAPIConfig(spec.API, specPath.Child("api"))...)
+	case batchopsv1alpha1.PostgresList:
+		errs = append(errs, validatePostgresConfig(spec.Postgres, specPath.Child("postgres"))...)
+	case batchopsv1alpha1.MySQLList:
+		errs = append(errs, validateMySQLConfig(spec.MySQL, specPath.Child("mysql"))...)
+	case batchopsv1alpha1.SQLList:
+		errs = append(errs, validateSQLConfig(spec.SQL, specPath.Child("sql"))...)
+	case batchopsv1alpha1.KubernetesList:
+		errs = append(errs, validateKubernetesConfig(spec.Kubernetes, specPath.Child("kubernetes"))...)
+	case batchopsv1alpha1.S3List:
+		errs = append(errs, validateS3Config(spec.S3, specPath.Child("s3"))...)
+	case batchopsv1alpha1.GitList:
+		errs = append(errs, validateGitConfig(spec.Git, specPath.Child("git"))...)
+	}
+	if spec.FetchPolicy != nil {
+		errs = append(errs, validateFetchPolicy(spec.FetchPolicy, specPath.Child("fetchPolicy"))...)
+	}
+	if policy := spec.UpdatePolicy; policy != nil && policy.MaxStaleness != nil && policy.MaxStaleness.Duration <= 0 {
+		errs = append(errs, field.Invalid(specPath.Child("updatePolicy", "maxStaleness"), policy.MaxStaleness.Duration.String(), "must be positive"))
+	}
+
+	if spec.Type == batchopsv1alpha1.APIList && spec.API != nil && spec.API.TLS != nil && spec.API.TLS.InsecureSkipVerify {
+		warnings = append(warnings, "spec.api.tls.insecureSkipVerify disables the verification of the server certificate")
+	}
+	if spec.Type == batchopsv1alpha1.S3List && spec.S3 != nil && spec.S3.TLS != nil && spec.S3.TLS.InsecureSkipVerify {
+		warnings = append(warnings, "spec.s3.tls.insecureSkipVerify disables the verification of the server certificate")
+	}
+	if spec.Type == batchopsv1alpha1.GitList && spec.Git != nil && spec.Git.Auth != nil && spec.Git.Auth.InsecureIgnoreHostKey {
+		warnings = append(warnings, "spec.git.auth.insecureIgnoreHostKey disables the verification of the SSH host key")
+	}
+	if spec.Type != batchopsv1alpha1.StaticList && len(spec.StaticList) > 0 {
+		warnings = append(warnings, fmt.Sprintf("spec.staticList is ignored for type %s", spec.Type))
+	}
+	if spec.Type != batchopsv1alpha1.APIList && spec.API != nil {
+		warnings = append(warnings, fmt.Sprintf("spec.api is ignored for type %s", spec.Type))
+	}
+	if spec.Type != batchopsv1alpha1.PostgresList && spec.Postgres != nil {
+		warnings = append(warnings, fmt.Sprintf("spec.postgres is ignored for type %s", spec.Type))
+	}
+	if spec.Type != batchopsv1alpha1.MySQLList && spec.MySQL != nil {
+		warnings = append(warnings, fmt.Sprintf("spec.mysql is ignored for type %s", spec.Type))
+	}
+	if spec.Type != batchopsv1alpha1.SQLList && spec.SQL != nil {
+		warnings = append(warnings, fmt.Sprintf("spec.sql is ignored for type %s", spec.Type))
+	}
+	if spec.Type != batchopsv1alpha1.KubernetesList && spec.Kubernetes != nil {
+		warnings = append(warnings, fmt.Sprintf("spec.kubernetes is ignored for type %s", spec.Type))
+	}
+	if spec.Type != batchopsv1alpha1.S3List && spec.S3 != nil {
+		warnings = append(warnings, fmt.Sprintf("spec.s3 is ignored for type %s", spec.Type))
+	}
+	if spec.Type != batchopsv1alpha1.GitList && spec.Git != nil {
+		warnings = append(warnings, fmt.Sprintf("spec.git is ignored for type %s", spec.Type))
+	}
+
+	if len(errs) == 0 {
+		return warnings, nil
+	}
+	return warnings, apierrors.NewInvalid(batchopsv1alpha1.GroupVersion.WithKind("ListSource").GroupKind(), listsource.Name, errs)
+}
+
+func validateAPIConfig(config *batchopsv1alpha1.APIConfig, path *field.Path) field.ErrorList {
+	if config == nil {
+		return field.ErrorList{field.Required(path, "required for type api")}
+	}
+
+	var errs field.ErrorList
+	if parsed, err := url.Parse(config.URL); err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		errs = append(errs, field.Invalid(path.Child("url"), config.URL, "must be an absolute URL"))
+	}
+	if config.JSONPath == "" {
+		errs = append(errs, field.Required(path.Child("jsonPath"), "required for type api"))
+	} else if err := jsonpath.New("items").Parse(fmt.Sprintf("{%s}", config.JSONPath)); err != nil {
+		errs = append(errs, field.Invalid(path.Child("jsonPath"), config.JSONPath, err.Error()))
+	}
+	if config.Auth != nil && config.Auth.Type == batchopsv1alpha1.BasicAuth {
+		if config.Auth.UsernameKey == "" {
+			errs = append(errs, field.Required(path.Child("auth", "usernameKey"), "required for basic auth"))
+		}
+		if config.Auth.PasswordKey == "" {
+			errs = append(errs, field.Required(path.Child("auth", "passwordKey"), "required for basic auth"))
+		}
+	}
+	if config.Auth != nil && config.Auth.Type == batchopsv1alpha1.OAuth2Auth {
+		oauth2Path := path.Child("auth", "oauth2")
+		if config.Auth.OAuth2 == nil {
+			errs = append(errs, field.Required(oauth2Path, "required for oauth2 auth"))
+		} else if parsed, err := url.Parse(config.Auth.OAuth2.TokenURL); err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			errs = append(errs, field.Invalid(oauth2Path.Child("tokenURL"), config.Auth.OAuth2.TokenURL, "must be an absolute URL"))
+		}
+	}
+	errs = append(errs, validateAPIBody(config, path)...)
+	if config.TLS != nil && config.TLS.CA != nil {
+		errs = append(errs, validateCABundleRef(config.TLS.CA, path.Child("tls", "ca"))...)
+	}
+	if config.Pagination != nil {
+		errs = append(errs, validatePagination(config.Pagination, path.Child("pagination"))...)
+	}
+	return errs
+}
+
+// validateAPIBody checks that at most one request body is configured and
+// that it is not sent with GET.
+func validateAPIBody(config *batchopsv1alpha1.APIConfig, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	var bodies []string
+	if config.Body != "" {
+		bodies = append(bodies, "body")
+	}
+	if config.BodyFrom != nil {
+		bodies = append(bodies, "bodyFrom")
+	}
+	if config.GraphQL != nil {
+		bodies = append(bodies, "graphql")
+	}
+	if len(bodies) > 1 {
+		errs = append(errs, field.Forbidden(path.Child(bodies[1]), fmt.Sprintf("may not be set together with %s", bodies[0])))
+	}
+	if len(bodies) > 0 && config.Method == http.MethodGet {
+		errs = append(errs, field.Invalid(path.Child("method"), config.Method, fmt.Sprintf("%s cannot be sent with GET", bodies[0])))
+	}
+
+	if config.GraphQL != nil && config.GraphQL.Variables != nil && len(config.GraphQL.Variables.Raw) > 0 {
+		var variables map[string]interface{}
+		if err := json.Unmarshal(config.GraphQL.Variables.Raw, &variables); err != nil {
+			errs = append(errs, field.Invalid(path.Child("graphql", "variables"), string(config.GraphQL.Variables.Raw), "must be a JSON object"))
+		}
+	}
+	return errs
+}
+
+func validateCABundleRef(ca *batchopsv1alpha1.CABundleRef, path *field.Path) field.ErrorList {
+	if ca.SecretRef == nil && ca.ConfigMapRef == nil {
+		return field.ErrorList{field.Required(path, "one of secretRef or configMapRef must be set")}
+	}
+	if ca.SecretRef != nil && ca.ConfigMapRef != nil {
+		return field.ErrorList{field.Forbidden(path.Child("configMapRef"), "secretRef and configMapRef are mutually exclusive")}
+	}
+	return nil
+}
+
+func validatePagination(pagination *batchopsv1alpha1.APIPagination, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	switch pagination.Type {
+	case batchopsv1alpha1.CursorPagination:
+		if pagination.CursorPath == "" {
+			errs = append(errs, field.Required(path.Child("cursorPath"), "required for cursor pagination"))
+		} else if err := jsonpath.New("cursor").Parse(fmt.Sprintf("{%s}", pagination.CursorPath)); err != nil {
+			errs = append(errs, field.Invalid(path.Child("cursorPath"), pagination.CursorPath, err.Error()))
+		}
+	case batchopsv1alpha1.OffsetPagination:
+		if pagination.Limit < 1 {
+			errs = append(errs, field.Required(path.Child("limit"), "required for offset pagination"))
+		}
+	}
+	return errs
+}
+
+func validateFetchPolicy(policy *batchopsv1alpha1.FetchPolicy, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if policy.Timeout != nil && policy.Timeout.Duration <= 0 {
+		errs = append(errs, field.Invalid(path.Child("timeout"), policy.Timeout.Duration.String(), "must be positive"))
+	}
+	errs = append(errs, validateBackoff(policy.RetryBackoff, path.Child("retryBackoff"))...)
+	errs = append(errs, validateBackoff(policy.FailureBackoff, path.Child("failureBackoff"))...)
+	return errs
+}
+
+func validateBackoff(backoff *batchopsv1alpha1.Backoff, path *field.Path) field.ErrorList {
+	if backoff == nil {
+		return nil
+	}
+	var errs field.ErrorList
+	if backoff.Initial != nil && backoff.Initial.Duration <= 0 {
+		errs = append(errs, field.Invalid(path.Child("initial"), backoff.Initial.Duration.String(), "must be positive"))
+	}
+	if backoff.Max != nil && backoff.Max.Duration <= 0 {
+		errs = append(errs, field.Invalid(path.Child("max"), backoff.Max.Duration.String(), "must be positive"))
+	}
+	if backoff.Initial != nil && backoff.Max != nil && backoff.Initial.Duration > backoff.Max.Duration {
+		errs = append(errs, field.Invalid(path.Child("max"), backoff.Max.Duration.String(), "must not be less than initial"))
+	}
+	return errs
+}
+
+func validatePostgresConfig(config *batchopsv1alpha1.PostgresConfig, path *field.Path) field.ErrorList {
+	if config == nil {
+		return field.ErrorList{field.Required(path, "required for type postgresql")}
+	}
+
+	var errs field.ErrorList
+	if config.ConnectionString == "" && config.Host == "" {
+		errs = append(errs, field.Required(path.Child("host"), "one of connectionString or host is required for type postgresql"))
+	} else if config.ConnectionString != "" && config.Host != "" {
+		errs = append(errs, field.Forbidden(path.Child("host"), "may not be set together with connectionString"))
+	}
+	if config.TLS != nil && config.SSLMode == batchopsv1alpha1.SSLModeDisable {
+		errs = append(errs, field.Forbidden(path.Child("tls"), "may not be set with sslMode disable"))
+	}
+	if config.TLS != nil && config.TLS.CA != nil {
+		errs = append(errs, validateCABundleRef(config.TLS.CA, path.Child("tls", "ca"))...)
+	}
+	if config.Query == "" {
+		errs = append(errs, field.Required(path.Child("query"), "required for type postgresql"))
+	}
+	for i := range config.Parameters {
+		errs = append(errs, validateQueryParameter(&config.Parameters[i], path.Child("parameters").Index(i))...)
+	}
+	return errs
+}
+
+func validateMySQLConfig(config *batchopsv1alpha1.MySQLConfig, path *field.Path) field.ErrorList {
+	if config == nil {
+		return field.ErrorList{field.Required(path, "required for type mysql")}
+	}
+
+	var errs field.ErrorList
+	if config.DSN == "" && config.Host == "" {
+		errs = append(errs, field.Required(path.Child("host"), "one of dsn or host is required for type mysql"))
+	} else if config.DSN != "" && config.Host != "" {
+		errs = append(errs, field.Forbidden(path.Child("host"), "may not be set together with dsn"))
+	}
+	if config.TLS != nil && config.SSLMode == batchopsv1alpha1.MySQLSSLModeDisabled {
+		errs = append(errs, field.Forbidden(path.Child("tls"), "may not be set with sslMode disabled"))
+	}
+	if config.TLS != nil && config.TLS.CA != nil {
+		errs = append(errs, validateCABundleRef(config.TLS.CA, path.Child("tls", "ca"))...)
+	}
+	if config.ConnectTimeout != nil && config.ConnectTimeout.Duration <= 0 {
+		errs = append(errs, field.Invalid(path.Child("connectTimeout"), config.ConnectTimeout.Duration.String(), "must be positive"))
+	}
+	if config.Query == "" {
+		errs = append(errs, field.Required(path.Child("query"), "required for type mysql"))
+	}
+	for i := range config.Parameters {
+		errs = append(errs, validateQueryParameter(&config.Parameters[i], path.Child("parameters").Index(i))...)
+	}
+	return errs
+}
+
+func validateSQLConfig(config *batchopsv1alpha1.SQLConfig, path *field.Path) field.ErrorList {
+	if config == nil {
+		return field.ErrorList{field.Required(path, "required for type sql")}
+	}
+
+	var errs field.ErrorList
+	if config.Driver == "" {
+		errs = append(errs, field.Required(path.Child("driver"), "required for type sql"))
+	}
+	if config.DSN == "" && config.DSNFrom == nil {
+		errs = append(errs, field.Required(path.Child("dsn"), "one of dsn or dsnFrom is required for type sql"))
+	} else if config.DSN != "" && config.DSNFrom != nil {
+		errs = append(errs, field.Forbidden(path.Child("dsnFrom"), "may not be set together with dsn"))
+	}
+	if config.Auth != nil && config.Driver == batchopsv1alpha1.SQLiteDriver {
+		errs = append(errs, field.Forbidden(path.Child("auth"), "not supported by the sqlite driver"))
+	}
+	if config.Query == "" {
+		errs = append(errs, field.Required(path.Child("query"), "required for type sql"))
+	}
+	for i := range config.Parameters {
+		errs = append(errs, validateQueryParameter(&config.Parameters[i], path.Child("parameters").Index(i))...)
+	}
+	return errs
+}
+
+func validateQueryParameter(param *batchopsv1alpha1.QueryParameter, path *field.Path) field.ErrorList {
+	set := 0
+	for _, isSet := range []bool{param.Value != nil, param.SecretRef != nil, param.ConfigMapRef != nil, param.Runtime != ""} {
+		if isSet {
+			set++
+		}
+	}
+	if set != 1 {
+		return field.ErrorList{field.Invalid(path, param.Name, "exactly one of value, secretRef, configMapRef or runtime must be set")}
+	}
+	return nil
+}
+
+func validateKubernetesConfig(config *batchopsv1alpha1.KubernetesConfig, path *field.Path) field.ErrorList {
+	if config == nil {
+		return field.ErrorList{field.Required(path, "required for type kubernetes")}
+	}
+
+	var errs field.ErrorList
+	gv, err := schema.ParseGroupVersion(config.APIVersion)
+	if config.APIVersion == "" {
+		errs = append(errs, field.Required(path.Child("apiVersion"), "required for type kubernetes"))
+	} else if err != nil {
+		errs = append(errs, field.Invalid(path.Child("apiVersion"), config.APIVersion, err.Error()))
+	}
+	if config.Kind == "" {
+		errs = append(errs, field.Required(path.Child("kind"), "required for type kubernetes"))
+	} else if gv.Group == "" && config.Kind == "Secret" {
+		// The list would copy Secret data into a ConfigMap
+		errs = append(errs, field.Forbidden(path.Child("kind"), "Secrets may not be listed"))
+	}
+	if config.Namespace != "" && config.AllNamespaces {
+		errs = append(errs, field.Forbidden(path.Child("allNamespaces"), "may not be set together with namespace"))
+	}
+	if config.LabelSelector != nil {
+		errs = append(errs, metav1validation.ValidateLabelSelector(config.LabelSelector,
+			metav1validation.LabelSelectorValidationOptions{}, path.Child("labelSelector"))...)
+	}
+	if config.FieldSelector != "" {
+		if _, err := fields.ParseSelector(config.FieldSelector); err != nil {
+			errs = append(errs, field.Invalid(path.Child("fieldSelector"), config.FieldSelector, err.Error()))
+		}
+	}
+	if config.JSONPath != "" {
+		if err := jsonpath.New("items").Parse(fmt.Sprintf("{%s}", config.JSONPath)); err != nil {
+			errs = append(errs, field.Invalid(path.Child("jsonPath"), config.JSONPath, err.Error()))
+		}
+	}
+	return errs
+}
+
+func validateS3Config(config *batchopsv1alpha1.S3Config, path *field.Path) field.ErrorList {
+	if config == nil {
+		return field.ErrorList{field.Required(path, "required for type s3")}
+	}
+
+	var errs field.ErrorList
+	if config.Endpoint != "" {
+		parsed, err := url.Parse(config.Endpoint)
+		if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+			errs = append(errs, field.Invalid(path.Child("endpoint"), config.Endpoint, "must be an http or https URL"))
+		} else if strings.Trim(parsed.Path, "/") != "" {
+			errs = append(errs, field.Invalid(path.Child("endpoint"), config.Endpoint, "may not have a path, the bucket is set by bucket"))
+		}
+	}
+	if config.Bucket == "" {
+		errs = append(errs, field.Required(path.Child("bucket"), "required for type s3"))
+	}
+	errs = append(errs, validateGlobs(config.Include, path.Child("include"))...)
+	errs = append(errs, validateGlobs(config.Exclude, path.Child("exclude"))...)
+	if config.KeyRegex != "" {
+		if _, err := regexp.Compile(config.KeyRegex); err != nil {
+			errs = append(errs, field.Invalid(path.Child("keyRegex"), config.KeyRegex, err.Error()))
+		}
+	}
+	if config.ModifiedWithin != nil && config.ModifiedWithin.Duration <= 0 {
+		errs = append(errs, field.Invalid(path.Child("modifiedWithin"), config.ModifiedWithin.Duration.String(), "must be positive"))
+	}
+	if config.Auth != nil && config.Auth.AccessKeyIDKey == "" {
+		errs = append(errs, field.Required(path.Child("auth", "accessKeyIDKey"), "required for S3 auth"))
+	}
+	if config.TLS != nil && config.TLS.CA != nil {
+		errs = append(errs, validateCABundleRef(config.TLS.CA, path.Child("tls", "ca"))...)
+	}
+	return errs
+}
+
+// scpLikeGitURL matches the SSH URLs of the form user@host:path.
+var scpLikeGitURL = regexp.MustCompile(`^(?:[^@/]+@)?[^@/:]+:[^/]`)
+
+func validateGitConfig(config *batchopsv1alpha1.GitConfig, path *field.Path) field.ErrorList {
+	if config == nil {
+		return field.ErrorList{field.Required(path, "required for type git")}
+	}
+
+	var errs field.ErrorList
+	ssh := false
+	if parsed, err := url.Parse(config.URL); err == nil && parsed.Scheme != "" && parsed.Host != "" {
+		switch parsed.Scheme {
+		case "https", "http", "git":
+		case "ssh":
+			ssh = true
+		default:
+			errs = append(errs, field.Invalid(path.Child("url"), config.URL, "must be an https, http, ssh or git URL"))
+		}
+	} else if scpLikeGitURL.MatchString(config.URL) {
+		ssh = true
+	} else {
+		errs = append(errs, field.Invalid(path.Child("url"), config.URL, "must be an https, http, ssh or git URL, or user@host:path"))
+	}
+
+	switch {
+	case config.Paths == nil && config.File == nil:
+		errs = append(errs, field.Required(path, "one of paths or file must be set"))
+	case config.Paths != nil && config.File != nil:
+		errs = append(errs, field.Forbidden(path.Child("file"), "paths and file are mutually exclusive"))
+	}
+	if config.Paths != nil {
+		if len(config.Paths.Include) == 0 {
+			errs = append(errs, field.Required(path.Child("paths", "include"), "at least one glob is required"))
+		}
+		errs = append(errs, validateGlobs(config.Paths.Include, path.Child("paths", "include"))...)
+		errs = append(errs, validateGlobs(config.Paths.Exclude, path.Child("paths", "exclude"))...)
+	}
+	if file := config.File; file != nil {
+		if file.Path == "" {
+			errs = append(errs, field.Required(path.Child("file", "path"), "required for file"))
+		}
+		if file.JSONPath != "" && file.Format != batchopsv1alpha1.GitJSONFormat {
+			errs = append(errs, field.Forbidden(path.Child("file", "jsonPath"), "only applies to format json"))
+		} else if file.JSONPath != "" {
+			if err := jsonpath.New("items").Parse(fmt.Sprintf("{%s}", file.JSONPath)); err != nil {
+				errs = append(errs, field.Invalid(path.Child("file", "jsonPath"), file.JSONPath, err.Error()))
+			}
+		}
+	}
+	if config.Auth == nil && ssh {
+		errs = append(errs, field.Required(path.Child("auth"), "required for SSH URLs"))
+	}
+	if auth := config.Auth; auth != nil && ssh {
+		if auth.KnownHostsKey == "" && !auth.InsecureIgnoreHostKey {
+			errs = append(errs, field.Required(path.Child("auth", "knownHostsKey"), "required for SSH unless insecureIgnoreHostKey is set"))
+		}
+		if auth.UsernameKey != "" {
+			errs = append(errs, field.Forbidden(path.Child("auth", "usernameKey"), "the SSH user is set by the URL"))
+		}
+	}
+	if config.CA != nil {
+		errs = append(errs, validateCABundleRef(config.CA, path.Child("ca"))...)
+	}
+	return errs
+}
+
+// validateGlobs checks path globs, whose syntax is that of path.Match with
+// "**" added.
+func validateGlobs(globs []string, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for i, glob := range globs {
+		if glob == "" {
+			errs = append(errs, field.Invalid(path.Index(i), glob, "may not be empty"))
+		} else if _, err := pathpkg.Match(glob, ""); err != nil {
+			errs = append(errs, field.Invalid(path.Index(i), glob, err.Error()))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,508 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	batchopsv1alpha1 "github.com/matanryngler/parallax/api/v1alpha1"
+)
+
+func TestListSourceDefaulter(t *testing.T) {
+	listSource := &batchopsv1alpha1.ListSource{
+		ObjectMeta: metav1.ObjectMeta{Name: "customers", Namespace: "default"},
+		Spec: batchopsv1alpha1.ListSourceSpec{
+			Type:       batchopsv1alpha1.StaticList,
+			StaticList: []string{"a"},
+		},
+	}
+
+	require.NoError(t, (&ListSourceCustomDefaulter{}).Default(context.Background(), listSource))
+	assert.Equal(t, batchopsv1alpha1.TextItemFormat, listSource.Spec.ItemFormat)
+	assert.Equal(t, batchopsv1alpha1.NoCompression, listSource.Spec.Compression)
+}
+
+func TestListSourceValidator(t *testing.T) {
+	tests := []struct {
+		name         string
+		spec         batchopsv1alpha1.ListSourceSpec
+		wantErr      string
+		wantWarnings int
+	}{
+		{
+			name: "static",
+			spec: batchopsv1alpha1.ListSourceSpec{Type: batchopsv1alpha1.StaticList, StaticList: []string{"a"}},
+		},
+		{
+			name:    "static without list",
+			spec:    batchopsv1alpha1.ListSourceSpec{Type: batchopsv1alpha1.StaticList},
+			wantErr: "spec.staticList",
+		},
+		{
+			name: "api",
+			spec: batchopsv1alpha1.ListSourceSpec{
+				Type: batchopsv1alpha1.APIList,
+				API:  &batchopsv1alpha1.APIConfig{URL: "https://example.com/items", JSONPath: "$.items[*].id"},
+			},
+		},
+		{
+			name:    "api without config",
+			spec:    batchopsv1alpha1.ListSourceSpec{Type: batchopsv1alpha1.APIList},
+			wantErr: "spec.api",
+		},
+		{
+			name: "api with relative url",
+			spec: batchopsv1alpha1.ListSourceSpec{
+				Type: batchopsv1alpha1.APIList,
+				API:  &batchopsv1alpha1.APIConfig{URL: "/items", JSONPath: "$.items[*]"},
+			},
+			wantErr: "spec.api.url",
+		},
+		{
+			name: "api with invalid jsonpath",
+			spec: batchopsv1alpha1.ListSourceSpec{
+				Type: batchopsv1alpha1.APIList,
+				API:  &batchopsv1alpha1.APIConfig{URL: "https://example.com", JSONPath: "$.items[*"},
+			},
+			wantErr: "spec.api.jsonPath",
+		},
+		{
+			name: "offset pagination without limit",
+			spec: batchopsv1alpha1.ListSourceSpec{
+				Type: batchopsv1alpha1.APIList,
+				API: &batchopsv1alpha1.APIConfig{
+					URL:        "https://example.com/items",
+					JSONPath:   "$.items[*]",
+					Pagination: &batchopsv1alpha1.APIPagination{Type: batchopsv1alpha1.OffsetPagination},
+				},
+			},
+			wantErr: "spec.api.pagination.limit",
+		},
+		{
+			name: "body sent with GET",
+			spec: batchopsv1alpha1.ListSourceSpec{
+				Type: batchopsv1alpha1.APIList,
+				API: &batchopsv1alpha1.APIConfig{
+					URL:      "https://example.com/search",
+					JSONPath: "$.hits[*].id",
+					Method:   "GET",
+					Body:     `{"query":"active"}`,
+				},
+			},
+			wantErr: "spec.api.method",
+		},
+		{
+			name: "body and graphql",
+			spec: batchopsv1alpha1.ListSourceSpec{
+				Type: batchopsv1alpha1.APIList,
+				API: &batchopsv1alpha1.APIConfig{
+					URL:      "https://example.com/graphql",
+					JSONPath: "$.data.assets[*].id",
+					Body:     `{"query":"active"}`,
+					GraphQL:  &batchopsv1alpha1.GraphQLRequest{Query: "{ assets { id } }"},
+				},
+			},
+			wantErr: "spec.api.graphql",
+		},
+		{
+			name: "ca without reference",
+			spec: batchopsv1alpha1.ListSourceSpec{
+				Type: batchopsv1alpha1.APIList,
+				API: &batchopsv1alpha1.APIConfig{
+					URL:      "https://internal.example.com/items",
+					JSONPath: "$.items[*]",
+					TLS:      &batchopsv1alpha1.APITLSConfig{CA: &batchopsv1alpha1.CABundleRef{}},
+				},
+			},
+			wantErr: "spec.api.tls.ca",
+		},
+		{
+			name: "insecure skip verify",
+			spec: batchopsv1alpha1.ListSourceSpec{
+				Type: batchopsv1alpha1.APIList,
+				API: &batchopsv1alpha1.APIConfig{
+					URL:      "https://internal.example.com/items",
+					JSONPath: "$.items[*]",
+					TLS:      &batchopsv1alpha1.APITLSConfig{InsecureSkipVerify: true},
+				},
+			},
+			wantWarnings: 1,
+		},
+		{
+			name: "oauth2 without settings",
+			spec: batchopsv1alpha1.ListSourceSpec{
+				Type: batchopsv1alpha1.APIList,
+				API: &batchopsv1alpha1.APIConfig{
+					URL:      "https://internal.example.com/items",
+					JSONPath: "$.items[*]",
+					Auth: &batchopsv1alpha1.APIAuth{
+						Type:      batchopsv1alpha1.OAuth2Auth,
+						SecretRef: batchopsv1alpha1.SecretRef{Name: "oauth-client", Key: "client_secret"},
+					},
+				},
+			},
+			wantErr: "spec.api.auth.oauth2",
+		},
+		{
+			name: "postgres without query",
+			spec: batchopsv1alpha1.ListSourceSpec{
+				Type:     batchopsv1alpha1.PostgresList,
+				Postgres: &batchopsv1alpha1.PostgresConfig{ConnectionString: "postgres://db/app"},
+			},
+			wantErr: "spec.postgres.query",
+		},
+		{
+			name: "postgres with host and connection string",
+			spec: batchopsv1alpha1.ListSourceSpec{
+				Type: batchopsv1alpha1.PostgresList,
+				Postgres: &batchopsv1alpha1.PostgresConfig{
+					ConnectionString: "postgres://db/app",
+					Host:             "db",
+					Query:            "SELECT id FROM items",
+				},
+			},
+			wantErr: "spec.postgres.host",
+		},
+		{
+			name: "postgres TLS with sslmode disable",
+			spec: batchopsv1alpha1.ListSourceSpec{
+				Type: batchopsv1alpha1.PostgresList,
+				Postgres: &batchopsv1alpha1.PostgresConfig{
+					Host:    "db",
+					SSLMode: batchopsv1alpha1.SSLModeDisable,
+					TLS:     &batchopsv1alpha1.DatabaseTLSConfig{},
+					Query:   "SELECT id FROM items",
+				},
+			},
+			wantErr: "spec.postgres.tls",
+		},
+		{
+			name: "postgres parameter with two values",
+			spec: batchopsv1alpha1.ListSourceSpec{
+				Type: batchopsv1alpha1.PostgresList,
+				Postgres: &batchopsv1alpha1.PostgresConfig{
+					Host:  "db",
+					Query: "SELECT id FROM items WHERE updated_at > $1 AND tenant = $2",
+					Parameters: []batchopsv1alpha1.QueryParameter{
+						{Runtime: batchopsv1alpha1.LastSuccessfulFetchTimeValue},
+						{Name: "tenant", Value: ptr.To("acme"), ConfigMapRef: &batchopsv1alpha1.ConfigMapRef{Name: "tenant", Key: "id"}},
+					},
+				},
+			},
+			wantErr: "spec.postgres.parameters[1]",
+		},
+		{
+			name: "mysql",
+			spec: batchopsv1alpha1.ListSourceSpec{
+				Type:  batchopsv1alpha1.MySQLList,
+				MySQL: &batchopsv1alpha1.MySQLConfig{Host: "db", Query: "SELECT id FROM items"},
+			},
+		},
+		{
+			name: "mysql with host and dsn",
+			spec: batchopsv1alpha1.ListSourceSpec{
+				Type: batchopsv1alpha1.MySQLList,
+				MySQL: &batchopsv1alpha1.MySQLConfig{
+					DSN:   "svc@tcp(db)/app",
+					Host:  "db",
+					Query: "SELECT id FROM items",
+				},
+			},
+			wantErr: "spec.mysql.host",
+		},
+		{
+			name: "mysql TLS with sslmode disabled",
+			spec: batchopsv1alpha1.ListSourceSpec{
+				Type: batchopsv1alpha1.MySQLList,
+				MySQL: &batchopsv1alpha1.MySQLConfig{
+					Host:    "db",
+					SSLMode: batchopsv1alpha1.MySQLSSLModeDisabled,
+					TLS:     &batchopsv1alpha1.DatabaseTLSConfig{},
+					Query:   "SELECT id FROM items",
+				},
+			},
+			wantErr: "spec.mysql.tls",
+		},
+		{
+			name: "sql",
+			spec: batchopsv1alpha1.ListSourceSpec{
+				Type: batchopsv1alpha1.SQLList,
+				SQL: &batchopsv1alpha1.SQLConfig{
+					Driver:  batchopsv1alpha1.SQLServerDriver,
+					DSNFrom: &batchopsv1alpha1.SecretRef{Name: "mssql", Key: "dsn"},
+					Query:   "SELECT id FROM items WHERE tenant = @p1",
+				},
+			},
+		},
+		{
+			name: "sqlite with credentials",
+			spec: batchopsv1alpha1.ListSourceSpec{
+				Type: batchopsv1alpha1.SQLList,
+				SQL: &batchopsv1alpha1.SQLConfig{
+					Driver: batchopsv1alpha1.SQLiteDriver,
+					DSN:    "file:/data/items.db?mode=ro",
+					Auth:   &batchopsv1alpha1.DatabaseAuth{SecretRef: batchopsv1alpha1.SecretRef{Name: "db", Key: "password"}, PasswordKey: "password"},
+					Query:  "SELECT id FROM items",
+				},
+			},
+			wantErr: "spec.sql.auth",
+		},
+		{
+			name: "kubernetes",
+			spec: batchopsv1alpha1.ListSourceSpec{
+				Type: batchopsv1alpha1.KubernetesList,
+				Kubernetes: &batchopsv1alpha1.KubernetesConfig{
+					APIVersion:    "apps/v1",
+					Kind:          "Deployment",
+					LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "backend"}},
+					FieldSelector: "metadata.namespace!=kube-system",
+					Watch:         true,
+				},
+			},
+		},
+		{
+			name: "kubernetes secrets",
+			spec: batchopsv1alpha1.ListSourceSpec{
+				Type:       batchopsv1alpha1.KubernetesList,
+				Kubernetes: &batchopsv1alpha1.KubernetesConfig{APIVersion: "v1", Kind: "Secret"},
+			},
+			wantErr: "spec.kubernetes.kind",
+		},
+		{
+			name: "kubernetes with invalid label selector",
+			spec: batchopsv1alpha1.ListSourceSpec{
+				Type: batchopsv1alpha1.KubernetesList,
+				Kubernetes: &batchopsv1alpha1.KubernetesConfig{
+					APIVersion: "v1",
+					Kind:       "Namespace",
+					LabelSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+						{Key: "team", Operator: metav1.LabelSelectorOpIn},
+					}},
+				},
+			},
+			wantErr: "spec.kubernetes.labelSelector",
+		},
+		{
+			name: "kubernetes with namespace and all namespaces",
+			spec: batchopsv1alpha1.ListSourceSpec{
+				Type: batchopsv1alpha1.KubernetesList,
+				Kubernetes: &batchopsv1alpha1.KubernetesConfig{
+					APIVersion:    "v1",
+					Kind:          "Pod",
+					Namespace:     "jobs",
+					AllNamespaces: true,
+				},
+			},
+			wantErr: "spec.kubernetes.allNamespaces",
+		},
+		{
+			name: "s3",
+			spec: batchopsv1alpha1.ListSourceSpec{
+				Type: batchopsv1alpha1.S3List,
+				S3: &batchopsv1alpha1.S3Config{
+					Endpoint: "http://minio.storage:9000",
+					Bucket:   "data",
+					Include:  []string{"incoming/**/*.csv"},
+					KeyRegex: `\.csv$`,
+				},
+			},
+		},
+		{
+			name: "s3 endpoint with a path",
+			spec: batchopsv1alpha1.ListSourceSpec{
+				Type: batchopsv1alpha1.S3List,
+				S3:   &batchopsv1alpha1.S3Config{Endpoint: "https://minio.storage/data", Bucket: "data"},
+			},
+			wantErr: "spec.s3.endpoint",
+		},
+		{
+			name: "s3 with invalid glob",
+			spec: batchopsv1alpha1.ListSourceSpec{
+				Type: batchopsv1alpha1.S3List,
+				S3:   &batchopsv1alpha1.S3Config{Bucket: "data", Exclude: []string{"tmp/[a-"}},
+			},
+			wantErr: "spec.s3.exclude[0]",
+		},
+		{
+			name: "s3 auth without access key ID key",
+			spec: batchopsv1alpha1.ListSourceSpec{
+				Type: batchopsv1alpha1.S3List,
+				S3: &batchopsv1alpha1.S3Config{
+					Bucket: "data",
+					Auth:   &batchopsv1alpha1.S3Auth{SecretRef: batchopsv1alpha1.SecretRef{Name: "s3", Key: "secret_access_key"}},
+				},
+			},
+			wantErr: "spec.s3.auth.accessKeyIDKey",
+		},
+		{
+			name: "git paths",
+			spec: batchopsv1alpha1.ListSourceSpec{
+				Type: batchopsv1alpha1.GitList,
+				Git: &batchopsv1alpha1.GitConfig{
+					URL:   "https://github.com/example/tenants.git",
+					Ref:   "main",
+					Paths: &batchopsv1alpha1.GitPaths{Include: []string{"tenants/*"}, Type: batchopsv1alpha1.GitDirectoryPath},
+				},
+			},
+		},
+		{
+			name: "git file over SSH",
+			spec: batchopsv1alpha1.ListSourceSpec{
+				Type: batchopsv1alpha1.GitList,
+				Git: &batchopsv1alpha1.GitConfig{
+					URL:  "git@github.com:example/tenants.git",
+					File: &batchopsv1alpha1.GitFile{Path: "tenants.json", Format: batchopsv1alpha1.GitJSONFormat, JSONPath: "$.tenants[*].name"},
+					Auth: &batchopsv1alpha1.GitAuth{
+						SecretRef:     batchopsv1alpha1.SecretRef{Name: "deploy-key", Key: "identity"},
+						KnownHostsKey: "known_hosts",
+					},
+				},
+			},
+		},
+		{
+			name: "git over SSH ignoring the host key",
+			spec: batchopsv1alpha1.ListSourceSpec{
+				Type: batchopsv1alpha1.GitList,
+				Git: &batchopsv1alpha1.GitConfig{
+					URL:  "ssh://git@git.example.com/tenants.git",
+					File: &batchopsv1alpha1.GitFile{Path: "tenants.txt"},
+					Auth: &batchopsv1alpha1.GitAuth{
+						SecretRef:             batchopsv1alpha1.SecretRef{Name: "deploy-key", Key: "identity"},
+						InsecureIgnoreHostKey: true,
+					},
+				},
+			},
+			wantWarnings: 1,
+		},
+		{
+			name: "git over SSH without known hosts",
+			spec: batchopsv1alpha1.ListSourceSpec{
+				Type: batchopsv1alpha1.GitList,
+				Git: &batchopsv1alpha1.GitConfig{
+					URL:  "git@github.com:example/tenants.git",
+					File: &batchopsv1alpha1.GitFile{Path: "tenants.txt"},
+					Auth: &batchopsv1alpha1.GitAuth{SecretRef: batchopsv1alpha1.SecretRef{Name: "deploy-key", Key: "identity"}},
+				},
+			},
+			wantErr: "spec.git.auth.knownHostsKey",
+		},
+		{
+			name: "git file URL",
+			spec: batchopsv1alpha1.ListSourceSpec{
+				Type: batchopsv1alpha1.GitList,
+				Git:  &batchopsv1alpha1.GitConfig{URL: "file:///etc", File: &batchopsv1alpha1.GitFile{Path: "passwd"}},
+			},
+			wantErr: "spec.git.url",
+		},
+		{
+			name: "git with paths and file",
+			spec: batchopsv1alpha1.ListSourceSpec{
+				Type: batchopsv1alpha1.GitList,
+				Git: &batchopsv1alpha1.GitConfig{
+					URL:   "https://github.com/example/tenants.git",
+					Paths: &batchopsv1alpha1.GitPaths{Include: []string{"tenants/*"}},
+					File:  &batchopsv1alpha1.GitFile{Path: "tenants.txt"},
+				},
+			},
+			wantErr: "spec.git.file",
+		},
+		{
+			name: "git JSONPath of a lines file",
+			spec: batchopsv1alpha1.ListSourceSpec{
+				Type: batchopsv1alpha1.GitList,
+				Git: &batchopsv1alpha1.GitConfig{
+					URL:  "https://github.com/example/tenants.git",
+					File: &batchopsv1alpha1.GitFile{Path: "tenants.txt", JSONPath: "$[*]"},
+				},
+			},
+			wantErr: "spec.git.file.jsonPath",
+		},
+		{
+			name: "failure backoff initial above max",
+			spec: batchopsv1alpha1.ListSourceSpec{
+				Type:       batchopsv1alpha1.StaticList,
+				StaticList: []string{"a"},
+				FetchPolicy: &batchopsv1alpha1.FetchPolicy{
+					FailureBackoff: &batchopsv1alpha1.Backoff{
+						Initial: &metav1.Duration{Duration: 5 * time.Minute},
+						Max:     &metav1.Duration{Duration: time.Minute},
+					},
+				},
+			},
+			wantErr: "spec.fetchPolicy.failureBackoff.max",
+		},
+		{
+			name: "config of another type",
+			spec: batchopsv1alpha1.ListSourceSpec{
+				Type:       batchopsv1alpha1.StaticList,
+				StaticList: []string{"a"},
+				API:        &batchopsv1alpha1.APIConfig{URL: "https://example.com"},
+			},
+			wantWarnings: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listSource := &batchopsv1alpha1.ListSource{
+				ObjectMeta: metav1.ObjectMeta{Name: "customers", Namespace: "default"},
+				Spec:       tt.spec,
+			}
+
+			warnings, err := (&ListSourceCustomValidator{}).ValidateCreate(context.Background(), listSource)
+			assert.Len(t, warnings, tt.wantWarnings)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.True(t, apierrors.IsInvalid(err))
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestListSourceValidator_UpdateWithoutSpecChange(t *testing.T) {
+	// Stored before the webhook was enabled, and invalid under its rules
+	old := &batchopsv1alpha1.ListSource{
+		ObjectMeta: metav1.ObjectMeta{Name: "customers", Namespace: "default", Finalizers: []string{"batchops.io/finalizer"}},
+		Spec:       batchopsv1alpha1.ListSourceSpec{Type: batchopsv1alpha1.StaticList},
+	}
+	validator := &ListSourceCustomValidator{}
+
+	updated := old.DeepCopy()
+	updated.Finalizers = nil
+	require.NoError(t, (&ListSourceCustomDefaulter{}).Default(context.Background(), updated))
+	_, err := validator.ValidateUpdate(context.Background(), old, updated)
+	assert.NoError(t, err, "metadata updates are not validated")
+
+	updated.Spec.IntervalSeconds = 60
+	_, err = validator.ValidateUpdate(context.Background(), old, updated)
+	assert.ErrorContains(t, err, "spec.staticList")
+
+	updated.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	_, err = validator.ValidateUpdate(context.Background(), old, updated)
+	assert.NoError(t, err, "objects being deleted are not validated")
+}
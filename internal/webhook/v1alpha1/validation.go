@@ -0,0 +1,127 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	batchopsv1alpha1 "github.com/matanryngler/parallax/api/v1alpha1"
+)
+
+// defaultTemplate fills in the item variable name the controllers fall back to.
+func defaultTemplate(template *batchopsv1alpha1.JobTemplateSpec) {
+	if template.EnvName == "" {
+		template.EnvName = batchopsv1alpha1.DefaultEnvName
+	}
+}
+
+// defaultParallelism runs one pod at a time unless told otherwise.
+func defaultParallelism(parallelism *int32) {
+	if *parallelism == 0 {
+		*parallelism = 1
+	}
+}
+
+// specChanged reports whether an update is to be validated: the spec changes
+// and the object is not being deleted. Updates of metadata, such as those of
+// finalizers and annotations by the controllers, must go through for objects
+// stored under looser rules, or their deletion would hang.
+func specChanged(deletionTimestamp *metav1.Time, oldSpec, newSpec interface{}) bool {
+	return deletionTimestamp == nil && !equality.Semantic.DeepEqual(oldSpec, newSpec)
+}
+
+// validateList checks that a ListJob or ListCronJob takes its items from
+// exactly one of staticList and listSourceRef.
+func validateList(staticList []string, listSourceRef string, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	switch {
+	case len(staticList) == 0 && listSourceRef == "":
+		errs = append(errs, field.Required(path.Child("staticList"), "one of staticList or listSourceRef must be set"))
+	case len(staticList) > 0 && listSourceRef != "":
+		errs = append(errs, field.Forbidden(path.Child("listSourceRef"), "staticList and listSourceRef are mutually exclusive"))
+	}
+	return errs
+}
+
+// validateParallelism checks that at least one pod runs at a time.
+func validateParallelism(parallelism int32, path *field.Path) field.ErrorList {
+	if parallelism < 1 {
+		return field.ErrorList{field.Invalid(path, parallelism, "must be greater than 0")}
+	}
+	return nil
+}
+
+// validateTemplate checks the environment variable names of a job template.
+// Every field has to end up in a variable of its own, apart from the item's.
+func validateTemplate(template batchopsv1alpha1.JobTemplateSpec, path *field.Path) field.ErrorList {
+	errs := validateEnvName(template.EnvName, path.Child("envName"))
+
+	itemEnv := template.EnvName
+	if itemEnv == "" {
+		itemEnv = batchopsv1alpha1.DefaultEnvName
+	}
+	seen := map[string]bool{}
+	envNames := map[string]bool{itemEnv: true}
+	for i, itemField := range template.Fields {
+		fieldPath := path.Child("fields").Index(i)
+		duplicate := seen[itemField.Name]
+		if duplicate {
+			errs = append(errs, field.Duplicate(fieldPath.Child("name"), itemField.Name))
+		}
+		seen[itemField.Name] = true
+		if itemField.EnvName != "" {
+			errs = append(errs, validateEnvName(itemField.EnvName, fieldPath.Child("envName"))...)
+		}
+
+		envName := fieldEnvName(itemEnv, itemField)
+		if envNames[envName] && !duplicate {
+			errs = append(errs, field.Duplicate(fieldPath.Child("envName"), envName))
+		}
+		envNames[envName] = true
+	}
+	return errs
+}
+
+// fieldEnvName returns the variable the controllers expose a field in,
+// <itemEnv>_<NAME> unless the field names one.
+func fieldEnvName(itemEnv string, itemField batchopsv1alpha1.ItemField) string {
+	if itemField.EnvName != "" {
+		return itemField.EnvName
+	}
+	return itemEnv + "_" + strings.ToUpper(nonEnvChars.ReplaceAllString(itemField.Name, "_"))
+}
+
+var nonEnvChars = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// validateEnvName checks a variable name. The item scripts export variables
+// through the shell, so names must be C identifiers rather than anything the
+// API server accepts in a container env.
+func validateEnvName(name string, path *field.Path) field.ErrorList {
+	if name == "" {
+		return nil
+	}
+	if msgs := validation.IsCIdentifier(name); len(msgs) > 0 {
+		return field.ErrorList{field.Invalid(path, name, strings.Join(msgs, "; "))}
+	}
+	return nil
+}
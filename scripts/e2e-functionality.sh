@@ -272,7 +272,8 @@ metadata:
   name: test-scheduled
   namespace: $TEST_NAMESPACE
 spec:
-  schedule: "0 */1 * * *"  # Every hour
+  schedule: "* * * * *"  # Every minute
+  concurrencyPolicy: Forbid
   staticList:
     - scheduled-item1
     - scheduled-item2
@@ -289,29 +290,29 @@ spec:
   failedJobsHistoryLimit: 1
 EOF
 
-    # Wait for CronJob to be created
-    log "Waiting for Kubernetes CronJob to be created..."
-    sleep 15
+    # Wait for the first run to be scheduled
+    log "Waiting for a scheduled ListJob to be created..."
+    sleep 75
     
-    if kubectl get cronjob -l listcronjob=test-scheduled -n "$TEST_NAMESPACE" >/dev/null 2>&1; then
-        success "ListCronJob created Kubernetes CronJob successfully"
+    if [[ -n "$(kubectl get listjob -l listcronjob=test-scheduled -n "$TEST_NAMESPACE" -o name 2>/dev/null)" ]]; then
+        success "ListCronJob scheduled a ListJob successfully"
         
-        # Verify CronJob configuration
-        SCHEDULE=$(kubectl get cronjob -l listcronjob=test-scheduled -n "$TEST_NAMESPACE" -o jsonpath='{.items[0].spec.schedule}')
-        if [[ "$SCHEDULE" == "0 */1 * * *" ]]; then
-            success "CronJob schedule correctly set"
+        # Verify the run got the full list
+        TOTAL=$(kubectl get listjob -l listcronjob=test-scheduled -n "$TEST_NAMESPACE" -o jsonpath='{.items[0].status.total}')
+        if [[ "$TOTAL" == "2" ]]; then
+            success "Scheduled ListJob has the expected item count"
         else
-            warn "CronJob schedule is '$SCHEDULE', expected '0 */1 * * *'"
+            warn "Scheduled ListJob has $TOTAL items, expected 2"
         fi
         
-        SUCCESS_LIMIT=$(kubectl get cronjob -l listcronjob=test-scheduled -n "$TEST_NAMESPACE" -o jsonpath='{.items[0].spec.successfulJobsHistoryLimit}')
-        if [[ "$SUCCESS_LIMIT" == "2" ]]; then
-            success "CronJob success history limit correctly set"
+        LAST_SCHEDULE=$(kubectl get listcronjob test-scheduled -n "$TEST_NAMESPACE" -o jsonpath='{.status.lastScheduleTime}')
+        if [[ -n "$LAST_SCHEDULE" ]]; then
+            success "ListCronJob status records the last schedule time"
         else
-            warn "CronJob success history limit is $SUCCESS_LIMIT, expected 2"
+            warn "ListCronJob status has no last schedule time"
         fi
     else
-        error "ListCronJob did not create Kubernetes CronJob"
+        error "ListCronJob did not schedule a ListJob"
         kubectl get events -n "$TEST_NAMESPACE" --sort-by='.lastTimestamp' | tail -10
         return 1
     fi
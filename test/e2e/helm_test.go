@@ -503,7 +503,7 @@ func testBasicFunctionality(namespace string) {
 	applyTestManifest("listcronjob-static.yaml", namespace)
 
 	Eventually(func(g Gomega) {
-		cmd := exec.Command("kubectl", "get", "cronjob", "-l", "listcronjob=static-cronjob-test", "-n", namespace, "-o", "jsonpath={.items[0].spec.schedule}")
+		cmd := exec.Command("kubectl", "get", "listjob", "-l", "listcronjob=static-cronjob-test", "-n", namespace, "-o", "jsonpath={.items[0].metadata.name}")
 		output, err := utils.Run(cmd)
 		if err != nil {
 			// Debug on failure
@@ -511,11 +511,11 @@ func testBasicFunctionality(namespace string) {
 			utils.GetControllerLogs("parallax-test", namespace, 50)
 		}
 		g.Expect(err).NotTo(HaveOccurred())
-		g.Expect(output).To(Equal("0 */6 * * *"))
-	}, 120, 10).Should(Succeed())
+		g.Expect(output).To(HavePrefix("static-cronjob-test-"))
+	}, 150, 10).Should(Succeed())
 
 	Eventually(func(g Gomega) {
-		cmd := exec.Command("kubectl", "get", "cronjob", "-l", "listcronjob=static-cronjob-test", "-n", namespace, "-o", "jsonpath={.items[0].spec.jobTemplate.spec.completions}")
+		cmd := exec.Command("kubectl", "get", "listjob", "-l", "listcronjob=static-cronjob-test", "-n", namespace, "-o", "jsonpath={.items[0].status.total}")
 		output, err := utils.Run(cmd)
 		g.Expect(err).NotTo(HaveOccurred())
 		g.Expect(output).To(Equal("2"))
@@ -277,7 +277,7 @@ metadata:
 spec:
   type: postgresql
   postgres:
-    connectionString: "host=%s port=5432 dbname=testdb sslmode=disable"
+    connectionString: "host=%s port=5432 dbname=testdb user=testuser sslmode=disable"
     query: "SELECT email FROM users WHERE active = true LIMIT 3"
     auth:
       secretRef:
@@ -347,8 +347,9 @@ metadata:
   name: pg-scheduled-processor
   namespace: %s
 spec:
-  schedule: "0 */6 * * *"  # Every 6 hours
+  schedule: "* * * * *"  # Every minute
   listSourceRef: pg-simple
+  refreshListSource: true
   parallelism: 1
   template:
     image: busybox:latest
@@ -367,13 +368,13 @@ spec:
 			_, err := utils.Run(cmd)
 			Expect(err).NotTo(HaveOccurred())
 
-			By("verifying CronJob is created from PostgreSQL data")
+			By("verifying a ListJob is scheduled from PostgreSQL data")
 			Eventually(func(g Gomega) {
-				cmd := exec.Command("kubectl", "get", "cronjob", "-l", "listcronjob=pg-scheduled-processor", "-n", comprehensiveTestNamespace, "-o", "jsonpath={.items[0].spec.schedule}")
+				cmd := exec.Command("kubectl", "get", "listjob", "-l", "listcronjob=pg-scheduled-processor", "-n", comprehensiveTestNamespace, "-o", "jsonpath={.items[0].metadata.name}")
 				output, err := utils.Run(cmd)
 				g.Expect(err).NotTo(HaveOccurred())
-				g.Expect(output).To(Equal("0 */6 * * *"))
-			}).Should(Succeed())
+				g.Expect(output).To(HavePrefix("pg-scheduled-processor-"))
+			}, 150, 10).Should(Succeed())
 		})
 	})
 })